@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/generator"
+)
+
+const generateUsage = `Usage: generate module <name> --fields <name:type,...> [flags]
+
+Writes the model, DTOs, repository, service, controller, routes, wire provider
+set and tests of a new module, and registers it in internal/wires/modules.go.
+Field types are string, int, float, bool and time.
+
+Example: generate module product --fields name:string,price:float
+
+Flags:
+`
+
+// runGenerate runs the generate subcommand with its arguments.
+func runGenerate(args []string) error {
+	if len(args) < 2 || args[0] != "module" {
+		fmt.Fprint(os.Stderr, generateUsage)
+		return errors.New("expected: generate module <name>")
+	}
+	name := args[1]
+
+	fs := flag.NewFlagSet("generate module", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), generateUsage)
+		fs.PrintDefaults()
+	}
+	fields := fs.String("fields", "", "fields of the model, as name:type pairs separated by commas")
+	root := fs.String("root", ".", "root directory of the repository")
+	force := fs.Bool("force", false, "overwrite existing files")
+	skipWire := fs.Bool("skip-wire", false, "do not regenerate the wire injectors")
+	if err := fs.Parse(args[2:]); err != nil {
+		return err
+	}
+	if *fields == "" {
+		fs.Usage()
+		return errors.New("--fields is required")
+	}
+
+	goModule, err := generator.GoModule(*root)
+	if err != nil {
+		return err
+	}
+	spec, err := generator.NewModuleSpec(goModule, name, *fields)
+	if err != nil {
+		return err
+	}
+
+	written, err := generator.Generate(*root, spec, *force)
+	for _, path := range written {
+		fmt.Println("wrote", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *skipWire {
+		fmt.Println("run `make wire` to generate the injector of the module")
+		return nil
+	}
+	cmd := exec.Command("go", "generate", "./internal/wires")
+	cmd.Dir = *root
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("generate wire injectors, run `make wire` once fixed: %w", err)
+	}
+	fmt.Println("wrote internal/wires/wire_gen.go")
+	return nil
+}
@@ -1,9 +1,22 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/hainguyen27798/gin-boilerplate/internal/initialize"
 )
 
 func main() {
-	initialize.Run()
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		err = runGenerate(os.Args[2:])
+	} else {
+		err = initialize.Run()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
 }
@@ -4,16 +4,21 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/hainguyen27798/gin-boilerplate/internal/database"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/logger"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/ratelimit"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
 )
 
-// AppConfig holds the application's main configuration including server and logger settings.
+// AppConfig holds the application's main configuration including server and logger settings,
+// as loaded at startup. ConfigManager holds the live configuration, updated on file changes.
 // AppMode specifies whether the application is running in development or production mode.
 // Logger is a structured and leveled logger instance for application log management.
+// RateLimiter is the store shared by all rate limited route groups.
 var (
-	AppConfig setting.Config
-	AppMode   setting.AppMode
-	Logger    *logger.Zap
-	MongoDB   *database.MongoDBStrategy
-	Validator *validator.Validate
+	AppConfig     setting.Config
+	ConfigManager *setting.Manager
+	AppMode       setting.AppMode
+	Logger        *logger.Zap
+	MongoDB       *database.MongoDBStrategy
+	Validator     *validator.Validate
+	RateLimiter   ratelimit.Store
 )
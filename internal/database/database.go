@@ -1,22 +1,61 @@
 package database
 
-import "context"
+import (
+	"context"
+	"time"
 
+	"github.com/hainguyen27798/gin-boilerplate/pkg/redact"
+	"go.uber.org/zap"
+)
+
+// DBOptions holds the connection settings applied on top of the connection string.
+// Empty fields keep the value found in the connection string, if any.
+// A failed connection is retried ConnectRetries times, waiting RetryBackoff
+// between attempts, doubled after each one up to MaxRetryBackoff.
+// Logger receives the connection and topology events, and the commands when
+// EnableLog is set, with the values hidden by Redactor. Commands running longer
+// than SlowQueryThreshold are reported, and explained when ExplainSlowQueries is set.
 type DBOptions struct {
-	Username    string
-	Password    string
-	DBName      string
-	MaxPoolSize uint64
-	EnableLog   bool
+	Username           string
+	Password           string
+	AuthSource         string
+	DBName             string
+	ReplicaSet         string
+	ReadPreference     string
+	WriteConcern       string
+	TLS                TLSOptions
+	MaxPoolSize        uint64
+	EnableLog          bool
+	DirectConnection   bool
+	ConnectTimeout     time.Duration
+	ConnectRetries     int
+	RetryBackoff       time.Duration
+	MaxRetryBackoff    time.Duration
+	Logger             *zap.Logger
+	Redactor           *redact.Redactor
+	SlowQueryThreshold time.Duration
+	ExplainSlowQueries bool
+}
+
+// TLSOptions defines the TLS settings of a connection. CertFile and KeyFile hold
+// the client certificate, CAFile the authority verifying the server certificate.
+type TLSOptions struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
 }
 
 // DBConnection is a generic type to hold any kind of DB connection/client.
 type DBConnection interface{}
 
 // DBStrategy defines an interface for connecting to a database.
+// Ready reports whether the database can currently serve requests.
 type DBStrategy interface {
-	Connect(connString string, opts DBOptions) (DBConnection, error)
+	Connect(ctx context.Context, connString string, opts DBOptions) (DBConnection, error)
 	Disconnect(ctx context.Context) error
+	Ready() bool
 }
 
 // DBContext holds a DBStrategy. It serves as the "context" in the strategy pattern.
@@ -29,12 +68,22 @@ func NewDBContext(strategy DBStrategy) *DBContext {
 	return &DBContext{strategy: strategy}
 }
 
-// Connect uses the selected strategy to establish a connection.
-func (c *DBContext) Connect(connString string, opts DBOptions) (DBConnection, error) {
-	return c.strategy.Connect(connString, opts)
+// Connect uses the selected strategy to establish a connection, giving up once
+// ctx is done.
+func (c *DBContext) Connect(
+	ctx context.Context,
+	connString string,
+	opts DBOptions,
+) (DBConnection, error) {
+	return c.strategy.Connect(ctx, connString, opts)
 }
 
 // Disconnect uses the selected strategy to close the connection.
 func (c *DBContext) Disconnect(ctx context.Context) error {
 	return c.strategy.Disconnect(ctx)
 }
+
+// Ready uses the selected strategy to report whether the database is available.
+func (c *DBContext) Ready() bool {
+	return c.strategy.Ready()
+}
@@ -3,49 +3,79 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
+	"strconv"
+	"sync/atomic"
 	"time"
 
+	"github.com/hainguyen27798/gin-boilerplate/pkg/redact"
 	"go.mongodb.org/mongo-driver/v2/event"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+	"go.uber.org/zap"
 )
 
-type MongoDBOptions struct {
-	DirectConnection bool
-}
+// Defaults used when the options leave the connection timeout or backoff unset.
+const (
+	defaultConnectTimeout = 10 * time.Second
+	defaultRetryBackoff   = time.Second
+)
 
 // MongoDBStrategy implements DBStrategy for MongoDB.
 // It holds the connected client and a reference to a specific database.
 type MongoDBStrategy struct {
 	Client *mongo.Client
 	DB     *mongo.Database
+
+	// ready tells whether a writable server is known, as reported by the topology monitor.
+	ready atomic.Bool
 }
 
-func (m *MongoDBStrategy) Connect(connString string, opts DBOptions) (DBConnection, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (m *MongoDBStrategy) Connect(
+	ctx context.Context,
+	connString string,
+	opts DBOptions,
+) (DBConnection, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
 
-	clientOptions := options.Client().
-		ApplyURI(connString).
-		SetMaxPoolSize(opts.MaxPoolSize).
-		SetAuth(options.Credential{
-			Username: opts.Username,
-			Password: opts.Password,
-		})
+	clientOptions, err := mongoClientOptions(connString, opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MongoDB options for %s: %w", RedactURI(connString), err)
+	}
 
+	var monitors []*event.CommandMonitor
 	if opts.EnableLog {
-		clientOptions.SetMonitor(getLogMonitor())
+		monitors = append(monitors, getLogMonitor(logger, opts.Redactor))
+	}
+	var detector *SlowQueryDetector
+	if opts.SlowQueryThreshold > 0 {
+		detector = NewSlowQueryDetector(logger, opts.SlowQueryThreshold, opts.ExplainSlowQueries)
+		monitors = append(monitors, detector.Monitor())
+	}
+	if len(monitors) > 0 {
+		clientOptions.SetMonitor(mergeCommandMonitors(monitors...))
 	}
+	clientOptions.
+		SetServerMonitor(m.serverMonitor(logger)).
+		SetPoolMonitor(poolMonitor(logger))
 
 	client, err := mongo.Connect(clientOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to MongoDB: %v", err.Error())
 	}
 
-	if err = client.Ping(ctx, nil); err != nil {
+	if err = PingWithRetry(ctx, client.Ping, opts, logger); err != nil {
+		_ = client.Disconnect(context.Background())
 		return nil, fmt.Errorf("failed to ping MongoDB: %v", err.Error())
 	}
+	m.ready.Store(true)
+	if detector != nil {
+		detector.client.Store(client)
+	}
 
 	// Store the client and database reference.
 	m.Client = client
@@ -54,6 +84,127 @@ func (m *MongoDBStrategy) Connect(connString string, opts DBOptions) (DBConnecti
 	return m, nil
 }
 
+// Ready reports whether a writable MongoDB server is currently available.
+func (m *MongoDBStrategy) Ready() bool {
+	return m.ready.Load()
+}
+
+// PingWithRetry pings the server with ping until it answers, retrying with an
+// exponential backoff so that the database may start after the application. Each
+// attempt is given the connection timeout of the options. It gives up once ctx
+// is done, returning the error of the last attempt along with the cause.
+func PingWithRetry(
+	ctx context.Context,
+	ping func(ctx context.Context, rp *readpref.ReadPref) error,
+	opts DBOptions,
+	logger *zap.Logger,
+) error {
+	timeout := opts.ConnectTimeout
+	if timeout <= 0 {
+		timeout = defaultConnectTimeout
+	}
+	backoff := opts.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := ping(attemptCtx, nil)
+		cancel()
+		if err == nil || attempt >= opts.ConnectRetries {
+			return err
+		}
+
+		logger.Warn("MongoDB is not reachable, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Int("retries", opts.ConnectRetries),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %w", context.Cause(ctx), err)
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if opts.MaxRetryBackoff > 0 && backoff > opts.MaxRetryBackoff {
+			backoff = opts.MaxRetryBackoff
+		}
+	}
+}
+
+// mongoClientOptions applies the options on top of those of the connection string.
+func mongoClientOptions(connString string, opts DBOptions) (*options.ClientOptions, error) {
+	clientOptions := options.Client().
+		ApplyURI(connString).
+		SetMaxPoolSize(opts.MaxPoolSize)
+
+	// Keep the credentials of the connection string unless others are given.
+	credential := options.Credential{}
+	if clientOptions.Auth != nil {
+		credential = *clientOptions.Auth
+	}
+	if opts.Username != "" {
+		credential.Username = opts.Username
+		credential.Password = opts.Password
+	}
+	if opts.AuthSource != "" {
+		credential.AuthSource = opts.AuthSource
+	}
+	if clientOptions.Auth != nil || opts.Username != "" || opts.AuthSource != "" {
+		clientOptions.SetAuth(credential)
+	}
+
+	if opts.DirectConnection {
+		clientOptions.SetDirect(true)
+	}
+	if opts.ReplicaSet != "" {
+		clientOptions.SetReplicaSet(opts.ReplicaSet)
+	}
+
+	if opts.ReadPreference != "" {
+		mode, err := readpref.ModeFromString(opts.ReadPreference)
+		if err != nil {
+			return nil, err
+		}
+		rp, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetReadPreference(rp)
+	}
+
+	if opts.WriteConcern != "" {
+		clientOptions.SetWriteConcern(parseWriteConcern(opts.WriteConcern))
+	}
+
+	if opts.TLS.Enabled {
+		tlsConfig, err := opts.TLS.Config()
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
+
+	return clientOptions, clientOptions.Validate()
+}
+
+// parseWriteConcern reads a write concern given as "majority", a number of
+// acknowledging members, or a custom tag set name.
+func parseWriteConcern(w string) *writeconcern.WriteConcern {
+	if w == writeconcern.WCMajority {
+		return writeconcern.Majority()
+	}
+	if n, err := strconv.Atoi(w); err == nil {
+		return &writeconcern.WriteConcern{W: n}
+	}
+	return writeconcern.Custom(w)
+}
+
 // Disconnect closes the connection to the MongoDB client.
 // If the client is nil, it returns an error.
 func (m *MongoDBStrategy) Disconnect(ctx context.Context) error {
@@ -63,47 +214,56 @@ func (m *MongoDBStrategy) Disconnect(ctx context.Context) error {
 	return m.Client.Disconnect(ctx)
 }
 
-// ANSI color codes for terminal output.
-const (
-	colorReset  = "\033[0m"
-	colorBlue   = "\033[34m" // For started events.
-	colorGreen  = "\033[32m" // For succeeded events.
-	colorRed    = "\033[31m" // For failed events.
-	colorYellow = "\033[33m" // For highlighting text.
-)
+// mergeCommandMonitors returns a monitor notifying all the given ones.
+func mergeCommandMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	if len(monitors) == 1 {
+		return monitors[0]
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, monitor := range monitors {
+				monitor.Started(ctx, evt)
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, monitor := range monitors {
+				monitor.Succeeded(ctx, evt)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, monitor := range monitors {
+				monitor.Failed(ctx, evt)
+			}
+		},
+	}
+}
 
-func getLogMonitor() *event.CommandMonitor {
+// getLogMonitor logs the commands sent to MongoDB through the logger, with the
+// values of their sensitive fields hidden.
+func getLogMonitor(logger *zap.Logger, redactor *redact.Redactor) *event.CommandMonitor {
 	return &event.CommandMonitor{
 		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
-			// Format: [MONGO][STARTED] Command: <command_name> | DB: <database> |
-			// RequestID: <id> | Cmd: <command document>
-			log.Printf("%s[MONGO][STARTED]%s Command: %s%s%s | DB: %s%s%s | RequestID: %s%d%s | Cmd: %v",
-				colorBlue, colorReset,
-				colorYellow, evt.CommandName, colorReset,
-				colorYellow, evt.DatabaseName, colorReset,
-				colorYellow, evt.RequestID, colorReset,
-				evt.Command)
+			logger.Debug("mongo command started",
+				zap.String("command", evt.CommandName),
+				zap.String("database", evt.DatabaseName),
+				zap.Int64("request_id", evt.RequestID),
+				zap.String("cmd", redactor.Document(evt.Command)),
+			)
 		},
 		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
-			// Format:
-			// [MONGO][SUCCEEDED] Command: <command_name> | RequestID: <id> | Duration: <duration>
-			log.Printf("%s[MONGO][SUCCEEDED]%s Command: %s%s%s | RequestID: %s%d%s | Duration: %s%v%s",
-				colorGreen, colorReset,
-				colorYellow, evt.CommandName, colorReset,
-				colorYellow, evt.RequestID, colorReset,
-				colorYellow, evt.Duration, colorReset)
+			logger.Debug("mongo command succeeded",
+				zap.String("command", evt.CommandName),
+				zap.Int64("request_id", evt.RequestID),
+				zap.Duration("duration", evt.Duration),
+			)
 		},
 		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
-			// Format: [MONGO][FAILED] Command: <command_name> | RequestID: <id> | Duration:
-			// <duration> | Error: <error>
-			log.Printf(
-				"%s[MONGO][FAILED]%s Command: %s%s%s | "+
-					"RequestID: %s%d%s | Duration: %s%v%s | Error: %s%v%s",
-				colorRed, colorReset,
-				colorYellow, evt.CommandName, colorReset,
-				colorYellow, evt.RequestID, colorReset,
-				colorYellow, evt.Duration, colorReset,
-				colorYellow, evt.Failure, colorReset)
+			logger.Warn("mongo command failed",
+				zap.String("command", evt.CommandName),
+				zap.Int64("request_id", evt.RequestID),
+				zap.Duration("duration", evt.Duration),
+				zap.Error(evt.Failure),
+			)
 		},
 	}
 }
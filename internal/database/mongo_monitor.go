@@ -0,0 +1,76 @@
+package database
+
+import (
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.uber.org/zap"
+)
+
+// writableServerKinds lists the kinds of servers accepting writes.
+var writableServerKinds = map[string]bool{
+	"Standalone":   true,
+	"RSPrimary":    true,
+	"Mongos":       true,
+	"LoadBalancer": true,
+}
+
+// serverMonitor logs the topology changes and failed heartbeats, and keeps the
+// readiness of the strategy up to date.
+func (m *MongoDBStrategy) serverMonitor(logger *zap.Logger) *event.ServerMonitor {
+	return &event.ServerMonitor{
+		TopologyDescriptionChanged: func(evt *event.TopologyDescriptionChangedEvent) {
+			ready := hasWritableServer(evt.NewDescription)
+			if m.ready.Swap(ready) != ready {
+				logger.Info("MongoDB readiness changed", zap.Bool("ready", ready))
+			}
+		},
+		ServerDescriptionChanged: func(evt *event.ServerDescriptionChangedEvent) {
+			if evt.PreviousDescription.Kind == evt.NewDescription.Kind {
+				return
+			}
+			logger.Info("MongoDB server changed",
+				zap.String("address", evt.Address.String()),
+				zap.String("from", evt.PreviousDescription.Kind),
+				zap.String("to", evt.NewDescription.Kind),
+			)
+		},
+		ServerHeartbeatFailed: func(evt *event.ServerHeartbeatFailedEvent) {
+			logger.Warn("MongoDB heartbeat failed",
+				zap.String("connection", evt.ConnectionID),
+				zap.Duration("duration", evt.Duration),
+				zap.Error(evt.Failure),
+			)
+		},
+	}
+}
+
+// poolMonitor logs the connection pool events worth noticing.
+func poolMonitor(logger *zap.Logger) *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionPoolCleared:
+				logger.Warn("MongoDB connection pool cleared",
+					zap.String("address", evt.Address),
+					zap.Error(evt.Error),
+				)
+			case event.ConnectionPoolReady:
+				logger.Info("MongoDB connection pool ready", zap.String("address", evt.Address))
+			case event.ConnectionCheckOutFailed:
+				logger.Warn("MongoDB connection check out failed",
+					zap.String("address", evt.Address),
+					zap.String("reason", evt.Reason),
+				)
+			}
+		},
+	}
+}
+
+// hasWritableServer reports whether the topology contains a server accepting writes.
+func hasWritableServer(topology event.TopologyDescription) bool {
+	for _, server := range topology.Servers {
+		if writableServerKinds[server.Kind] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"expvar"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.uber.org/zap"
+)
+
+// slowQueries counts the slow MongoDB commands per collection.
+var slowQueries = expvar.NewMap("mongo_slow_queries")
+
+// explainTimeout bounds the time spent explaining a slow command.
+const explainTimeout = 5 * time.Second
+
+// filterPaths locates the filter of the commands that can be explained.
+var filterPaths = map[string][]string{
+	"find":          {"filter"},
+	"count":         {"query"},
+	"distinct":      {"query"},
+	"findAndModify": {"query"},
+	"update":        {"updates", "0", "q"},
+	"delete":        {"deletes", "0", "q"},
+	"aggregate":     {"pipeline", "0", "$match"},
+}
+
+// unexplainedFields are the fields of a command which explain doesn't accept.
+var unexplainedFields = map[string]bool{
+	"lsid":             true,
+	"txnNumber":        true,
+	"autocommit":       true,
+	"startTransaction": true,
+	"readConcern":      true,
+	"writeConcern":     true,
+}
+
+// startedCommand keeps what is needed to report a command once it finished.
+type startedCommand struct {
+	name       string
+	database   string
+	collection string
+	command    bson.Raw
+}
+
+// SlowQueryDetector reports the commands running longer than a threshold and,
+// when enabled, explains them to flag the queries scanning whole collections.
+type SlowQueryDetector struct {
+	logger    *zap.Logger
+	threshold time.Duration
+	explain   bool
+
+	// client runs the explain commands, it is set once connected.
+	client  atomic.Pointer[mongo.Client]
+	started sync.Map
+}
+
+// NewSlowQueryDetector creates a new instance of SlowQueryDetector reporting the
+// commands running for threshold or longer, explaining them when explain is set.
+func NewSlowQueryDetector(
+	logger *zap.Logger,
+	threshold time.Duration,
+	explain bool,
+) *SlowQueryDetector {
+	return &SlowQueryDetector{logger: logger, threshold: threshold, explain: explain}
+}
+
+// Monitor returns the command monitor feeding the detector.
+func (d *SlowQueryDetector) Monitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			if _, ok := filterPaths[evt.CommandName]; !ok {
+				return
+			}
+			collection, _ := evt.Command.Lookup(evt.CommandName).StringValueOK()
+			d.started.Store(evt.RequestID, &startedCommand{
+				name:       evt.CommandName,
+				database:   evt.DatabaseName,
+				collection: collection,
+				// The driver may reuse the buffer of the command.
+				command: bytes.Clone(evt.Command),
+			})
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			d.finished(evt.RequestID, evt.Duration)
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			d.finished(evt.RequestID, evt.Duration)
+		},
+	}
+}
+
+// finished reports the command if it was slow.
+func (d *SlowQueryDetector) finished(requestID int64, duration time.Duration) {
+	value, ok := d.started.LoadAndDelete(requestID)
+	if !ok || duration < d.threshold {
+		return
+	}
+	cmd := value.(*startedCommand)
+	shape := filterShape(cmd)
+
+	slowQueries.Add(cmd.collection, 1)
+	d.logger.Warn("mongo slow query",
+		zap.String("command", cmd.name),
+		zap.String("collection", cmd.collection),
+		zap.String("filter", shapeString(shape)),
+		zap.Duration("duration", duration),
+		zap.Duration("threshold", d.threshold),
+	)
+
+	if d.explain {
+		go d.explainCommand(cmd, shape)
+	}
+}
+
+// explainCommand explains the query plan of a slow command and warns about the
+// plans scanning the whole collection.
+func (d *SlowQueryDetector) explainCommand(cmd *startedCommand, shape bson.D) {
+	client := d.client.Load()
+	if client == nil {
+		return
+	}
+
+	var command bson.D
+	if err := bson.Unmarshal(cmd.command, &command); err != nil {
+		return
+	}
+	explained := make(bson.D, 0, len(command))
+	for _, e := range command {
+		if !strings.HasPrefix(e.Key, "$") && !unexplainedFields[e.Key] {
+			explained = append(explained, e)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+
+	var result bson.Raw
+	err := client.Database(cmd.database).RunCommand(ctx, bson.D{
+		{Key: "explain", Value: explained},
+		{Key: "verbosity", Value: "queryPlanner"},
+	}).Decode(&result)
+	if err != nil {
+		d.logger.Debug("explain slow query failed", zap.Error(err))
+		return
+	}
+
+	if !HasCollectionScan(result) {
+		return
+	}
+	d.logger.Warn("mongo slow query scans the whole collection, an index may be missing",
+		zap.String("command", cmd.name),
+		zap.String("collection", cmd.collection),
+		zap.String("filter", shapeString(shape)),
+		zap.Strings("index_candidates", indexCandidates(shape)),
+	)
+}
+
+// filterShape returns the filter of the command with its values replaced, keeping
+// the fields and operators only.
+func filterShape(cmd *startedCommand) bson.D {
+	filter, ok := cmd.command.Lookup(filterPaths[cmd.name]...).DocumentOK()
+	if !ok {
+		return bson.D{}
+	}
+	var doc bson.D
+	if err := bson.Unmarshal(filter, &doc); err != nil {
+		return bson.D{}
+	}
+	shape, _ := shapeOf(doc).(bson.D)
+	return shape
+}
+
+// shapeOf replaces the values of a filter with "?".
+func shapeOf(v any) any {
+	switch v := v.(type) {
+	case bson.D:
+		shape := make(bson.D, len(v))
+		for i, e := range v {
+			shape[i] = bson.E{Key: e.Key, Value: shapeOf(e.Value)}
+		}
+		return shape
+	case bson.A:
+		// Keep the conditions of $and and $or, collapse the lists of values.
+		shape := bson.A{}
+		for _, value := range v {
+			if doc, ok := value.(bson.D); ok {
+				shape = append(shape, shapeOf(doc))
+			}
+		}
+		if len(shape) == 0 {
+			return bson.A{"?"}
+		}
+		return shape
+	default:
+		return "?"
+	}
+}
+
+// shapeString formats a filter shape as extended JSON.
+func shapeString(shape bson.D) string {
+	out, err := bson.MarshalExtJSON(shape, false, false)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// indexCandidates returns the fields a filter matches on, which an index may cover.
+func indexCandidates(shape bson.D) []string {
+	var fields []string
+	for _, e := range shape {
+		if !strings.HasPrefix(e.Key, "$") {
+			fields = append(fields, e.Key)
+		}
+	}
+	return fields
+}
+
+// HasCollectionScan reports whether the winning plan of an explain result scans
+// the whole collection.
+func HasCollectionScan(doc bson.Raw) bool {
+	elements, err := doc.Elements()
+	if err != nil {
+		return false
+	}
+	for _, element := range elements {
+		key := element.Key()
+		value := element.Value()
+		switch {
+		case key == "rejectedPlans":
+			continue
+		case key == "stage":
+			if stage, ok := value.StringValueOK(); ok && stage == "COLLSCAN" {
+				return true
+			}
+		default:
+			if sub, ok := value.DocumentOK(); ok && HasCollectionScan(sub) {
+				return true
+			}
+			if arr, ok := value.ArrayOK(); ok && HasCollectionScan(bson.Raw(arr)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,19 @@
+package database
+
+import "net/url"
+
+// redactedPassword replaces the password of redacted connection strings.
+const redactedPassword = "xxxxx"
+
+// RedactURI returns the connection string with its password hidden, so it can be
+// logged. A connection string that can't be parsed is hidden entirely.
+func RedactURI(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "[invalid connection string]"
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redactedPassword)
+	}
+	return u.String()
+}
@@ -0,0 +1,164 @@
+// Package generator scaffolds new modules from templates following the layout
+// of the existing ones.
+package generator
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// modulesFile lists the modules registered by the application, the generated
+// module is added to it.
+const modulesFile = "internal/wires/modules.go"
+
+// importsEnd and modulesEnd close the imports of the modules file and the list
+// of modules returned by wires.Modules.
+const (
+	importsEnd = "\n)\n"
+	modulesEnd = "\n\t}\n}\n"
+)
+
+// File is a file to generate, its path being relative to the root of the repository.
+type File struct {
+	Path     string
+	Template string
+}
+
+// Files returns the files generated for a module: the model, DTOs, MongoDB and
+// in-memory repositories, service, controller, routes, wire provider set and
+// module, and their tests.
+func (s *ModuleSpec) Files() []File {
+	module := filepath.Join("internal", "module", s.Package)
+	tests := filepath.Join("test", "internal", "modules", s.Package)
+	return []File{
+		{filepath.Join(module, s.Name+"_model.go"), "model.go.tmpl"},
+		{filepath.Join(module, s.Name+"_dto.go"), "dto.go.tmpl"},
+		{filepath.Join(module, s.Name+"_errors.go"), "errors.go.tmpl"},
+		{filepath.Join(module, s.Name+"_repo.go"), "repo.go.tmpl"},
+		{filepath.Join(module, s.Name+"_repo_memory.go"), "repo_memory.go.tmpl"},
+		{filepath.Join(module, s.Name+"_service.go"), "service.go.tmpl"},
+		{filepath.Join(module, s.Name+"_controller.go"), "controller.go.tmpl"},
+		{filepath.Join("internal", "routes", s.Name+"_route.go"), "route.go.tmpl"},
+		{filepath.Join("internal", "wires", s.Name+"_wire.go"), "wire.go.tmpl"},
+		{filepath.Join("internal", "wires", s.Name+"_module.go"), "module.go.tmpl"},
+		{filepath.Join(tests, s.Name+"_model_test.go"), "model_test.go.tmpl"},
+		{filepath.Join(tests, s.Name+"_dto_test.go"), "dto_test.go.tmpl"},
+		{filepath.Join(tests, s.Name+"_controller_test.go"), "controller_test.go.tmpl"},
+	}
+}
+
+// Generate writes the files of a module under the root of the repository and
+// registers the module. Existing files are only overwritten when force is set.
+// It returns the paths of the written files.
+func Generate(root string, spec *ModuleSpec, force bool) ([]string, error) {
+	files := spec.Files()
+	if !force {
+		for _, f := range files {
+			if _, err := os.Stat(filepath.Join(root, f.Path)); err == nil {
+				return nil, fmt.Errorf("%s already exists, use --force to overwrite it", f.Path)
+			}
+		}
+	}
+
+	// Render every file before writing any, a failing template leaves the tree as is.
+	contents := make([][]byte, len(files))
+	for i, f := range files {
+		content, err := render(f.Template, spec)
+		if err != nil {
+			return nil, fmt.Errorf("render %s: %w", f.Path, err)
+		}
+		contents[i] = content
+	}
+
+	written := make([]string, 0, len(files)+1)
+	for i, f := range files {
+		path := filepath.Join(root, f.Path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(path, contents[i], 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, f.Path)
+	}
+
+	registered, err := registerModule(root, spec)
+	if err != nil {
+		return written, err
+	}
+	if registered {
+		written = append(written, modulesFile)
+	}
+	return written, nil
+}
+
+// render executes a template with the spec and formats the resulting source.
+func render(name string, spec *ModuleSpec) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, spec); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// registerModule adds the module, backed by its MongoDB repository, to the list
+// returned by wires.Modules unless it is already there. It reports whether the
+// list was changed.
+func registerModule(root string, spec *ModuleSpec) (bool, error) {
+	path := filepath.Join(root, modulesFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	constructor := "New" + spec.Type + "Module("
+	if bytes.Contains(content, []byte(constructor)) {
+		return false, nil
+	}
+
+	src := string(content)
+	end := strings.LastIndex(src, modulesEnd)
+	imports := strings.Index(src, importsEnd)
+	if end < 0 || imports < 0 {
+		return false, errors.New("cannot find the list of modules in " + modulesFile)
+	}
+	src = src[:end] + fmt.Sprintf(
+		"\n\t\t%s%s.New%sRepository(db)),", constructor, spec.Package, spec.Type,
+	) + src[end:]
+	// format.Source sorts the imports.
+	src = src[:imports] + fmt.Sprintf(
+		"\n\t\"%s/internal/module/%s\"", spec.GoModule, spec.Package,
+	) + src[imports:]
+
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(path, formatted, 0o644)
+}
+
+// GoModule returns the module path declared in the go.mod file of the root.
+func GoModule(root string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(path), `"`), nil
+		}
+	}
+	return "", errors.New("no module declared in go.mod")
+}
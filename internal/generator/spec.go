@@ -0,0 +1,210 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// identifier matches the snake case names accepted for modules and fields.
+var identifier = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// fieldTypes maps the field types accepted on the command line to their Go type
+// and to two distinct example values used by the generated tests.
+var fieldTypes = map[string]struct {
+	goType  string
+	example string
+	changed string
+}{
+	"string": {"string", `"example"`, `"changed"`},
+	"int":    {"int", "42", "7"},
+	"float":  {"float64", "9.99", "1.5"},
+	"bool":   {"bool", "true", "false"},
+	"time": {
+		"time.Time",
+		"time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)",
+		"time.Date(2025, time.March, 4, 5, 6, 7, 0, time.UTC)",
+	},
+}
+
+// reservedKeys are the keys of the fields of common.BaseModel.
+var reservedKeys = map[string]bool{"id": true, "created_at": true, "updated_at": true}
+
+// ModuleSpec describes the module to generate, with its name declined in every
+// form the templates need.
+type ModuleSpec struct {
+	// GoModule is the import path of the Go module the files are generated in.
+	GoModule string
+	// Name is the snake case name of the module, such as order_item.
+	Name string
+	// Package is the package of the module, such as orderitems.
+	Package string
+	// Type prefixes the exported identifiers, such as OrderItem.
+	Type string
+	// Var prefixes the unexported identifiers, such as orderItem.
+	Var string
+	// Label names the entity in comments and messages, such as order item,
+	// ALabel adds its article and PluralLabel is its plural.
+	Label       string
+	ALabel      string
+	PluralLabel string
+	// Collection is the MongoDB collection, such as order_items.
+	Collection string
+	// Route is the path segment of the routes, such as order-items.
+	Route  string
+	Fields []Field
+}
+
+// Field is a field of the generated model and DTOs.
+type Field struct {
+	// Name is the Go name of the field, such as UnitPrice.
+	Name string
+	// Key is the BSON and JSON key of the field, such as unit_price.
+	Key     string
+	GoType  string
+	Example string
+	Changed string
+}
+
+// Required reports whether the field must be set on creation. Numbers and
+// booleans may legitimately be zero.
+func (f Field) Required() bool {
+	return f.GoType == "string" || f.GoType == "time.Time"
+}
+
+// NewModuleSpec parses the name of a module and its fields, given as a comma
+// separated list of name:type pairs.
+func NewModuleSpec(goModule, name, fields string) (*ModuleSpec, error) {
+	if !identifier.MatchString(name) {
+		return nil, fmt.Errorf("invalid module name %q, use snake case such as order_item", name)
+	}
+
+	words := strings.Split(name, "_")
+	// Only the last word takes the plural, order_item giving order_items.
+	plurals := append(words[:len(words)-1:len(words)-1], plural(words[len(words)-1]))
+	spec := &ModuleSpec{
+		GoModule:    goModule,
+		Name:        name,
+		Package:     strings.Join(plurals, ""),
+		Type:        camelCase(words, true),
+		Var:         camelCase(words, false),
+		Label:       strings.Join(words, " "),
+		ALabel:      article(words[0]) + " " + strings.Join(words, " "),
+		PluralLabel: strings.Join(plurals, " "),
+		Collection:  strings.Join(plurals, "_"),
+		Route:       strings.Join(plurals, "-"),
+	}
+	if token.IsKeyword(spec.Var) || token.IsKeyword(spec.Package) {
+		return nil, fmt.Errorf("invalid module name %q, it is a Go keyword", name)
+	}
+
+	seen := map[string]bool{}
+	for _, raw := range strings.Split(fields, ",") {
+		key, typ, ok := strings.Cut(strings.TrimSpace(raw), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid field %q, use name:type", raw)
+		}
+		if !identifier.MatchString(key) {
+			return nil, fmt.Errorf("invalid field name %q, use snake case such as unit_price", key)
+		}
+		if reservedKeys[key] {
+			return nil, fmt.Errorf("invalid field name %q, it is set by common.BaseModel", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate field %q", key)
+		}
+		seen[key] = true
+
+		fieldType, ok := fieldTypes[typ]
+		if !ok {
+			return nil, fmt.Errorf(
+				"invalid type %q of field %q, use string, int, float, bool or time", typ, key,
+			)
+		}
+		spec.Fields = append(spec.Fields, Field{
+			Name:    camelCase(strings.Split(key, "_"), true),
+			Key:     key,
+			GoType:  fieldType.goType,
+			Example: fieldType.example,
+			Changed: fieldType.changed,
+		})
+	}
+
+	return spec, nil
+}
+
+// HasTime reports whether a field is a time, the time package being imported.
+func (s *ModuleSpec) HasTime() bool {
+	for _, f := range s.Fields {
+		if f.GoType == "time.Time" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasRequired reports whether a field must be set on creation.
+func (s *ModuleSpec) HasRequired() bool {
+	for _, f := range s.Fields {
+		if f.Required() {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrCode returns the error code of a missing entity, such as ORDER_ITEM_NOT_FOUND.
+func (s *ModuleSpec) ErrCode() string {
+	return strings.ToUpper(s.Name) + "_NOT_FOUND"
+}
+
+// initialisms are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"id": true, "url": true, "uri": true, "api": true, "ip": true, "json": true, "html": true,
+}
+
+// camelCase joins words into a Go name, the first word being capitalized when
+// exported is set.
+func camelCase(words []string, exported bool) string {
+	var b strings.Builder
+	for i, word := range words {
+		switch {
+		case i == 0 && !exported:
+			b.WriteString(word)
+		case initialisms[word]:
+			b.WriteString(strings.ToUpper(word))
+		default:
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
+
+// plural returns the plural of an English noun, following the regular rules.
+func plural(word string) string {
+	for _, suffix := range []string{"s", "x", "z", "ch", "sh"} {
+		if strings.HasSuffix(word, suffix) {
+			return word + "es"
+		}
+	}
+	switch {
+	case len(word) > 1 && word[len(word)-1] == 'y' && !isVowel(word[len(word)-2]):
+		return word[:len(word)-1] + "ies"
+	default:
+		return word + "s"
+	}
+}
+
+// article returns the indefinite article preceding a word.
+func article(word string) string {
+	if isVowel(word[0]) {
+		return "an"
+	}
+	return "a"
+}
+
+// isVowel reports whether a letter is a vowel.
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
@@ -1,40 +1,82 @@
 package initialize
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/url"
 
 	"github.com/hainguyen27798/gin-boilerplate/global"
 	"github.com/hainguyen27798/gin-boilerplate/internal/database"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
 	"go.uber.org/zap"
 )
 
-func InitDatabase() {
+// InitDatabase connects to MongoDB, retrying while it is unreachable until ctx is
+// done, and returns the error of the last attempt when all of them failed.
+func InitDatabase(ctx context.Context) error {
 	mongoConfig := global.AppConfig.MongoDB
-	connStr := fmt.Sprintf(
-		"mongodb://%s:%s/?directConnection=%t",
-		mongoConfig.Host,
-		mongoConfig.Port,
-		mongoConfig.DirectConnection,
-	)
+	connStr := mongoConnString(mongoConfig)
+	global.Logger.Info("connecting to database", zap.String("uri", database.RedactURI(connStr)))
 
 	strategy := &database.MongoDBStrategy{}
 
 	// Create a DBContext with the selected strategy.
 	dbContext := database.NewDBContext(strategy)
-	conn, err := dbContext.Connect(connStr, database.DBOptions{
-		Username:    mongoConfig.Username,
-		Password:    mongoConfig.Password,
-		DBName:      mongoConfig.Database,
-		MaxPoolSize: mongoConfig.MaxPoolSize,
-		EnableLog:   mongoConfig.EnableLog,
+	conn, err := dbContext.Connect(ctx, connStr, database.DBOptions{
+		Username:       mongoConfig.Username,
+		Password:       mongoConfig.Password,
+		AuthSource:     mongoConfig.AuthSource,
+		DBName:         mongoConfig.Database,
+		ReplicaSet:     mongoConfig.ReplicaSet,
+		ReadPreference: mongoConfig.ReadPreference,
+		WriteConcern:   mongoConfig.WriteConcern,
+		TLS: database.TLSOptions{
+			Enabled:            mongoConfig.TLS.Enabled,
+			CAFile:             mongoConfig.TLS.CAFile,
+			CertFile:           mongoConfig.TLS.CertFile,
+			KeyFile:            mongoConfig.TLS.KeyFile,
+			InsecureSkipVerify: mongoConfig.TLS.InsecureSkipVerify,
+		},
+		MaxPoolSize:        mongoConfig.MaxPoolSize,
+		EnableLog:          mongoConfig.EnableLog,
+		DirectConnection:   mongoConfig.DirectConnection,
+		ConnectTimeout:     mongoConfig.ConnectTimeout,
+		ConnectRetries:     mongoConfig.ConnectRetries,
+		RetryBackoff:       mongoConfig.RetryBackoff,
+		MaxRetryBackoff:    mongoConfig.MaxRetryBackoff,
+		Logger:             global.Logger.Module("mongodb"),
+		Redactor:           global.Logger.Redactor(),
+		SlowQueryThreshold: mongoConfig.SlowQuery.Threshold,
+		// Explaining costs a round trip per slow query, keep it out of production.
+		ExplainSlowQueries: mongoConfig.SlowQuery.Explain && global.AppMode != setting.ProdMode,
 	})
 	if err != nil {
 		global.Logger.Error("init database fail", zap.Error(err))
-		panic(err)
+		return fmt.Errorf("init database: %w", err)
 	}
 	global.Logger.Info("init and connect database success")
 
 	if mongoStrategy, ok := conn.(*database.MongoDBStrategy); ok {
 		global.MongoDB = mongoStrategy
 	}
+	return nil
+}
+
+// mongoConnString returns the configured URI, or builds one from the host and
+// port. Credentials are passed separately and never end up in the built URI.
+func mongoConnString(config setting.MongoDBSettings) string {
+	if config.URI != "" {
+		return config.URI
+	}
+
+	u := url.URL{Scheme: "mongodb", Host: config.Host, Path: "/"}
+	switch {
+	case config.SRV:
+		// SRV records carry the ports of the members.
+		u.Scheme = "mongodb+srv"
+	case config.Port != "":
+		u.Host = net.JoinHostPort(config.Host, config.Port)
+	}
+	return u.String()
 }
@@ -0,0 +1,32 @@
+package initialize
+
+import (
+	"github.com/hainguyen27798/gin-boilerplate/global"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module"
+	"github.com/hainguyen27798/gin-boilerplate/internal/wires"
+	"go.uber.org/zap"
+)
+
+// InitModules registers the feature modules and initializes them in dependency
+// order with the shared dependencies.
+func InitModules() (*module.Registry, error) {
+	registry := module.NewRegistry()
+	if err := registry.Register(wires.Modules(global.MongoDB.DB)...); err != nil {
+		return nil, err
+	}
+
+	err := registry.Init(module.Deps{
+		DB:        global.MongoDB.DB,
+		Logger:    global.Logger,
+		Validator: global.Validator,
+		Config:    global.ConfigManager.Current,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range registry.Modules() {
+		global.Logger.Info("init module success", zap.String("module", m.Name()))
+	}
+	return registry, nil
+}
@@ -0,0 +1,18 @@
+package initialize
+
+import (
+	"github.com/hainguyen27798/gin-boilerplate/global"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/helpers"
+	"go.uber.org/zap"
+)
+
+// InitPasswordHasher initializes the password hasher with the configured algorithm,
+// parameters and pepper.
+func InitPasswordHasher() {
+	hasher, err := helpers.NewPasswordHasher(global.AppConfig.Hash)
+	if err != nil {
+		global.Logger.Error("init password hasher fail", zap.Error(err))
+		panic(err)
+	}
+	helpers.SetPasswordHasher(hasher)
+}
@@ -0,0 +1,30 @@
+package initialize
+
+import (
+	"github.com/hainguyen27798/gin-boilerplate/global"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/ratelimit"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// InitRateLimiter initializes the store used by the rate limit middleware
+// according to the configured backend.
+func InitRateLimiter() {
+	config := global.AppConfig.RateLimit
+	if !config.Enabled {
+		return
+	}
+
+	switch config.Store {
+	case "redis":
+		redisConfig := global.AppConfig.Redis
+		global.RateLimiter = ratelimit.NewRedisStore(redis.NewClient(&redis.Options{
+			Addr:     redisConfig.Addr,
+			Password: redisConfig.Password,
+			DB:       redisConfig.DB,
+		}))
+	default:
+		global.RateLimiter = ratelimit.NewMemoryStore()
+	}
+	global.Logger.Info("init rate limiter success", zap.String("store", config.Store))
+}
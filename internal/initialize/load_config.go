@@ -1,6 +1,7 @@
 package initialize
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/hainguyen27798/gin-boilerplate/global"
@@ -25,7 +26,10 @@ func LoadConfig(path string) {
 	viper.SetConfigName(string(global.AppMode))
 	viper.SetConfigType("yaml")
 
-	viper.AutomaticEnv()
+	setting.SetDefaults(viper.GetViper())
+	if err := setting.BindEnv(viper.GetViper()); err != nil {
+		panic(err)
+	}
 
 	// reading config
 	if err := viper.ReadInConfig(); err != nil {
@@ -36,4 +40,10 @@ func LoadConfig(path string) {
 	if err := viper.Unmarshal(&global.AppConfig); err != nil {
 		panic(err)
 	}
+
+	// Report every invalid setting at once rather than failing deep inside startup.
+	if err := global.AppConfig.Validate(); err != nil {
+		panic(fmt.Errorf("invalid configuration:\n%w", err))
+	}
+	global.ConfigManager = setting.NewManager(global.AppConfig)
 }
@@ -9,5 +9,10 @@ import (
 // InitLogger initializes the global logger instance using application configuration settings
 // for structured logging.
 func InitLogger() {
-	global.Logger = logger.NewLogger(global.AppConfig.Logger, global.AppMode, metadata.Version)
+	global.Logger = logger.NewLogger(
+		global.AppConfig.Logger,
+		global.AppMode,
+		metadata.Version,
+		metadata.Commit,
+	)
 }
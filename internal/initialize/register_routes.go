@@ -1,13 +1,41 @@
 package initialize
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/hainguyen27798/gin-boilerplate/global"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/health"
 	"github.com/hainguyen27798/gin-boilerplate/internal/routes"
-	"github.com/hainguyen27798/gin-boilerplate/internal/wires"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"go.uber.org/zap"
 )
 
-func RegisterRoutes(r *gin.Engine) {
-	userController := wires.InitializeUserModule(global.MongoDB.DB)
-	routes.RegisterUserRoutes(r, userController)
+// RegisterRoutes sets up the health probes, checking the database and every
+// module, the routes of the modules and their OpenAPI document. The Swagger UI
+// is only served outside production.
+func RegisterRoutes(r *gin.Engine, registry *module.Registry) {
+	checks := map[string]health.Check{
+		"mongodb": func(context.Context) error {
+			if !global.MongoDB.Ready() {
+				return response.ErrUnavailable
+			}
+			return nil
+		},
+	}
+	for name, check := range registry.HealthChecks() {
+		checks[name] = check
+	}
+	routes.RegisterHealthRoutes(r, health.NewHealthController(checks))
+
+	registry.RegisterRoutes(r)
+
+	err := routes.RegisterOpenAPIRoutes(
+		r, registry.OpenAPIRoutes(), global.AppMode != setting.ProdMode,
+	)
+	if err != nil {
+		global.Logger.Warn("openapi document does not match the routes", zap.Error(err))
+	}
 }
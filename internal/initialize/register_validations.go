@@ -1,19 +1,31 @@
 package initialize
 
 import (
-	"github.com/go-playground/validator/v10"
 	"github.com/hainguyen27798/gin-boilerplate/global"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/validations"
 )
 
 // RegisterValidations Register custom validation
 func RegisterValidations() {
-	v := validator.New()
+	// Apply the configured password policy, its settings defaulting to the default policy
+	policy, err := validations.NewPasswordPolicy(global.AppConfig.Password)
+	if err != nil {
+		panic(err)
+	}
+	validations.SetPasswordPolicy(policy)
+
+	v, err := validations.NewValidator()
+	if err != nil {
+		panic(err)
+	}
 
-	// Register custom strong password validation
-	if err := v.RegisterValidation("strongPassword", validations.StrongPassword); err != nil {
+	// Register validation messages of every supported locale
+	translator, err := validations.NewTranslator(v)
+	if err != nil {
 		panic(err)
 	}
+	response.SetTranslator(translator)
 
 	global.Validator = v
 }
@@ -2,41 +2,113 @@ package initialize
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/hainguyen27798/gin-boilerplate/global"
 )
 
-func Run() {
+// shutdownTimeout is the time the server is given to complete its requests on
+// shutdown, and the dependencies to be released.
+const shutdownTimeout = 5 * time.Second
+
+// Run starts the application and blocks until it shuts down. It returns the
+// error preventing the application from starting, if any.
+func Run() error {
 	LoadConfig("./configs/")
 	InitLogger()
-	InitDatabase()
+	WatchConfig()
+	InitPasswordHasher()
+	// Stop retrying to connect when the application is stopped meanwhile
+	connectCtx, stopConnecting := signal.NotifyContext(
+		context.Background(), syscall.SIGINT, syscall.SIGTERM,
+	)
+	err := InitDatabase(connectCtx)
+	stopConnecting()
+	if err != nil {
+		return err
+	}
+	InitRateLimiter()
 
 	RegisterValidations()
 
+	registry, err := InitModules()
+	if err != nil {
+		return err
+	}
+	if err := registry.Start(context.Background()); err != nil {
+		return err
+	}
+
 	s := InitServer()
 
 	// Register routes
-	RegisterRoutes(s.r)
+	RegisterRoutes(s.r, registry)
 
-	defer func() {
-		// Create a context with timeout for graceful shutdown
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+	quit := QuitSignals()
+	s.Run(global.AppConfig.Server.Port)
 
-		// shutdown server
-		s.Stop(ctx)
+	// Stop claiming jobs as soon as the signal arrives: the running jobs are
+	// given their drain timeout while the server completes its requests.
+	AwaitShutdown(quit,
+		ShutdownStep{Timeout: shutdownTimeout, Stop: s.Shutdown},
+		ShutdownStep{Timeout: global.AppConfig.Jobs.DrainTimeout, Stop: func(ctx context.Context) {
+			if err := registry.Stop(ctx); err != nil {
+				global.Logger.Error("Modules stop failed: " + err.Error())
+			}
+		}},
+	)
 
-		// disconnect mongoDB
-		err := global.MongoDB.Disconnect(ctx)
-		if err != nil {
-			panic(err.Error())
-		}
+	// release the dependencies the server and the modules share
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// disconnect mongoDB
+	if err := global.MongoDB.Disconnect(ctx); err != nil {
+		global.Logger.Error("MongoDB disconnect failed: " + err.Error())
+	} else {
 		global.Logger.Info("MongoDB disconnect success")
+	}
 
-		// after server shutdown
-		<-ctx.Done()
-		global.Logger.Info("Server shutdown")
-	}()
-	s.Run(global.AppConfig.Server.Port)
+	// close rate limiter store
+	if global.RateLimiter != nil {
+		if err := global.RateLimiter.Close(); err != nil {
+			global.Logger.Error("Rate limiter close failed: " + err.Error())
+		}
+	}
+
+	global.Logger.Info("Server shutdown")
+	return nil
+}
+
+// ShutdownStep is a part of the application to stop on shutdown, given its own
+// timeout.
+type ShutdownStep struct {
+	// Timeout is the time Stop is given, counted from the signal.
+	Timeout time.Duration
+	// Stop stops the part, until ctx is done.
+	Stop func(ctx context.Context)
+}
+
+// AwaitShutdown blocks until quit receives a signal, then runs the steps
+// concurrently and waits for them to return. Each step is given a context
+// expiring after its own timeout, which runs from the signal, not from the start
+// of the application.
+func AwaitShutdown(quit <-chan os.Signal, steps ...ShutdownStep) {
+	<-quit
+
+	var wg sync.WaitGroup
+	for _, step := range steps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), step.Timeout)
+			defer cancel()
+			step.Stop(ctx)
+		}()
+	}
+	wg.Wait()
 }
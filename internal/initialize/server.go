@@ -11,6 +11,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/hainguyen27798/gin-boilerplate/global"
+	"github.com/hainguyen27798/gin-boilerplate/internal/middlewares"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
 )
 
@@ -21,18 +23,21 @@ type Server struct {
 
 // InitServer init gin server
 func InitServer() *Server {
-	var r *gin.Engine
-
 	if global.AppMode == setting.ProdMode {
 		gin.SetMode(gin.ReleaseMode)
-		r = gin.New()
-		r.Use(gin.Logger(), gin.Recovery())
 	} else {
 		gin.SetMode(gin.DebugMode)
 		gin.ForceConsoleColor()
-		r = gin.Default()
 	}
 
+	r := gin.New()
+	r.Use(
+		middlewares.AccessLog(global.Logger.Module("http"), global.Logger.Redactor()),
+		gin.Recovery(),
+	)
+
+	response.SetErrorFormat(response.ErrorFormat(global.AppConfig.Server.ErrorFormat))
+
 	return &Server{r, nil}
 }
 
@@ -56,21 +61,25 @@ func (s *Server) Run(port string) {
 	}()
 }
 
-func (s *Server) Stop(ctx context.Context) {
-	// Wait for interrupt signal to gracefully shut down the server with
-	// a timeout of 5 seconds.
+// QuitSignals returns the channel receiving the signals asking the application
+// to shut down.
+func QuitSignals() <-chan os.Signal {
 	quit := make(chan os.Signal, 1)
 
 	// kill (no param) default send syscall.SIGTERM
 	// kill -2 is syscall.SIGINT
 	// kill -9 is syscall. SIGKILL but can't be caught, so don't need to add it
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	return quit
+}
+
+// Shutdown stops the server, waiting for the running requests until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) {
 	global.Logger.Info("Shutting down server...")
 
-	// Attempt to shut down the server gracefully
+	// Attempt to shut down the server gracefully, the requests still running when
+	// ctx is done are left to the process exit
 	if err := s.s.Shutdown(ctx); err != nil {
-		global.Logger.Fatal("Server forced to shut down: \n" + err.Error())
-		panic(err)
+		global.Logger.Error("Server forced to shut down: " + err.Error())
 	}
 }
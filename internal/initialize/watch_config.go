@@ -0,0 +1,63 @@
+package initialize
+
+import (
+	"reflect"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hainguyen27798/gin-boilerplate/global"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/validations"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// WatchConfig reloads the configuration whenever its file changes. A valid
+// configuration replaces the live one and is published to the subscribers,
+// an invalid one is ignored. The log levels, the error format and the password
+// policy are applied here, the settings read on every use, such as the admin
+// token or the idempotency TTL, apply on their own.
+func WatchConfig() {
+	global.ConfigManager.Subscribe(func(old, current *setting.Config) {
+		if old.Logger.Level != current.Logger.Level {
+			global.Logger.SetLevel(current.Logger.Level)
+			global.Logger.Info("log level changed", zap.String("level", current.Logger.Level))
+		}
+		if !reflect.DeepEqual(old.Logger.Modules, current.Logger.Modules) {
+			global.Logger.SetModuleLevels(current.Logger.Modules)
+			global.Logger.Info("module log levels changed", zap.Any("modules", current.Logger.Modules))
+		}
+		if old.Server.ErrorFormat != current.Server.ErrorFormat {
+			response.SetErrorFormat(response.ErrorFormat(current.Server.ErrorFormat))
+			global.Logger.Info("error format changed", zap.String("format", current.Server.ErrorFormat))
+		}
+		if !reflect.DeepEqual(old.Password, current.Password) {
+			policy, err := validations.NewPasswordPolicy(current.Password)
+			if err != nil {
+				global.Logger.Error("apply password policy fail", zap.Error(err))
+			} else {
+				validations.SetPasswordPolicy(policy)
+				global.Logger.Info("password policy changed")
+			}
+		}
+	})
+
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		var next setting.Config
+		if err := viper.Unmarshal(&next); err != nil {
+			global.Logger.Error("reload config fail", zap.String("file", e.Name), zap.Error(err))
+			return
+		}
+
+		warnings, err := global.ConfigManager.Update(next)
+		if err != nil {
+			global.Logger.Error("reload config fail", zap.String("file", e.Name), zap.Error(err))
+			return
+		}
+		for _, warning := range warnings {
+			global.Logger.Warn(warning)
+		}
+		global.Logger.Info("reload config success", zap.String("file", e.Name))
+	})
+	viper.WatchConfig()
+}
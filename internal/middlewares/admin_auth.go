@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+)
+
+// AdminAuth returns a middleware only letting through requests carrying the admin
+// token returned by token as a bearer token. The token is looked up on every
+// request so a rotated token applies immediately.
+func AdminAuth(token func() string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		checkAdminToken(c, token())
+	}
+}
+
+// NewAdminAuth returns a middleware only letting through requests carrying the
+// given token as a bearer token.
+func NewAdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		checkAdminToken(c, token)
+	}
+}
+
+// checkAdminToken aborts the request unless it carries the token. Every request
+// is rejected when no token is configured.
+func checkAdminToken(c *gin.Context, token string) {
+	given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" || !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		response.ErrorResponse(c, response.NewError(response.ErrUnauthorized, nil))
+		c.Abort()
+		return
+	}
+	c.Next()
+}
@@ -0,0 +1,190 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/global"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/idempotency"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"go.uber.org/zap"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client's idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// defaultIdempotencyTTL is used when no TTL is configured.
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// MaxIdempotentBodySize is the maximum size in bytes of the bodies of the requests
+// made with an Idempotency-Key, read in full to fingerprint them.
+const MaxIdempotentBodySize = 1 << 20
+
+// Idempotency returns a middleware honouring the Idempotency-Key header. The first
+// request with a key is processed and its response saved; retries with the same
+// key and payload replay that response. A retry arriving while the first request
+// is still processing gets 409, reusing a key for another payload gets 422, and
+// bodies larger than MaxIdempotentBodySize get 413. The replays restore the status,
+// the headers set after this middleware and the body of the saved response, kept
+// for the TTL returned by ttl, looked up on every request.
+func Idempotency(repo idempotency.IdempotencyRepository, ttl func() time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxIdempotentBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				response.ErrorResponse(c, response.NewError(response.ErrPayloadTooLarge, fmt.Errorf(
+					"idempotent requests are limited to %d bytes", maxBytesErr.Limit,
+				)))
+			} else {
+				response.ErrorResponse(c, response.NewError(response.ErrBadRequest, err))
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are scoped to the authenticated user, if any.
+		if userID := c.GetString(ContextUserIDKey); userID != "" {
+			key = userID + ":" + key
+		}
+
+		keepFor := ttl()
+		if keepFor <= 0 {
+			keepFor = defaultIdempotencyTTL
+		}
+
+		now := time.Now()
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+		record, acquired, appErr := repo.Acquire(c, &idempotency.IdempotencyModel{
+			Key:         key,
+			Fingerprint: fingerprint,
+			Status:      idempotency.StatusProcessing,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(keepFor),
+		})
+		if appErr != nil {
+			response.ErrorResponse(c, appErr)
+			c.Abort()
+			return
+		}
+
+		if !acquired {
+			replayIdempotentResponse(c, record, fingerprint)
+			c.Abort()
+			return
+		}
+
+		// Only the headers set by the handlers are saved, the previous middlewares
+		// setting theirs again on the replays.
+		before := c.Writer.Header().Clone()
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		// The request may be canceled once the response is written,
+		// the record must still be updated.
+		ctx := context.WithoutCancel(c.Request.Context())
+		defer func() {
+			if r := recover(); r != nil {
+				// Let the client retry requests whose handler panicked.
+				if appErr := repo.Release(ctx, key); appErr != nil && global.Logger != nil {
+					global.Logger.Error("release idempotency record failed", zap.Error(appErr))
+				}
+				panic(r)
+			}
+		}()
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			// Let the client retry requests failing on our side.
+			appErr = repo.Release(ctx, key)
+		} else {
+			appErr = repo.Complete(
+				ctx, key, recorder.Status(), handlerHeader(before, recorder.Header()), recorder.body.Bytes(),
+			)
+		}
+		if appErr != nil && global.Logger != nil {
+			global.Logger.Error("save idempotency record failed", zap.Error(appErr))
+		}
+	}
+}
+
+// replayIdempotentResponse answers a request whose key is already in use.
+func replayIdempotentResponse(
+	c *gin.Context,
+	record *idempotency.IdempotencyModel,
+	fingerprint string,
+) {
+	switch {
+	case record.Fingerprint != fingerprint:
+		response.ErrorResponse(c, response.NewError(
+			response.ErrUnprocessable,
+			errors.New("idempotency key was used with a different request"),
+		))
+	case record.Status != idempotency.StatusCompleted:
+		response.ErrorResponse(c, response.NewError(
+			response.ErrConflict,
+			errors.New("a request with this idempotency key is being processed"),
+		))
+	default:
+		for name, values := range record.Header {
+			c.Writer.Header()[name] = values
+		}
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(record.StatusCode, record.Header.Get("Content-Type"), record.Body)
+	}
+}
+
+// handlerHeader returns the headers of the response set or changed since before.
+func handlerHeader(before, after http.Header) http.Header {
+	header := http.Header{}
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			header[name] = values
+		}
+	}
+	return header
+}
+
+// requestFingerprint hashes the parts of a request that identify its payload.
+func requestFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body written by the handlers.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write implements io.Writer.
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// WriteString implements io.StringWriter.
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
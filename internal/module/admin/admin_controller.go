@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+)
+
+// AdminController handles HTTP requests related to administration operations.
+type AdminController struct {
+	logLevelService LogLevelService
+	validate        *validator.Validate
+}
+
+// NewAdminController creates a new instance of AdminController.
+func NewAdminController(
+	logLevelService LogLevelService,
+	validate *validator.Validate,
+) *AdminController {
+	return &AdminController{
+		logLevelService: logLevelService,
+		validate:        validate,
+	}
+}
+
+// GetLogLevel handles the retrieval of the level of a logger, given by the
+// optional logger query parameter.
+func (c *AdminController) GetLogLevel() gin.HandlerFunc {
+	return response.Handle(
+		func(_ context.Context, query LogLevelQuery) (*LogLevelDto, *response.Error) {
+			return c.logLevelService.GetLogLevel(query.Logger), nil
+		},
+		response.WithMessage("Found the log level"),
+	)
+}
+
+// UpdateLogLevel handles the change of the level of a logger.
+func (c *AdminController) UpdateLogLevel() gin.HandlerFunc {
+	return response.Handle(
+		func(_ context.Context, dto UpdateLogLevelDto) (*LogLevelDto, *response.Error) {
+			return c.logLevelService.UpdateLogLevel(&dto), nil
+		},
+		response.WithMessage("Updated the log level successfully"),
+		response.WithValidator(c.validate),
+	)
+}
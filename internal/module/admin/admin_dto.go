@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// UpdateLogLevelDto is used for changing the level of a logger. Logger names a
+// module logger, the application logger being used when empty. RevertAfter, in
+// seconds, restores the configured level once elapsed.
+type UpdateLogLevelDto struct {
+	Logger      string `json:"logger"`
+	Level       string `json:"level" validate:"required,oneof=debug info warn error"`
+	RevertAfter int    `json:"revert_after" validate:"gte=0,lte=86400"`
+}
+
+// Validate validates the UpdateLogLevelDto.
+func (dto *UpdateLogLevelDto) Validate(v *validator.Validate) error {
+	return v.Struct(dto)
+}
+
+// LogLevelDto is used for retrieving the level of a logger.
+type LogLevelDto struct {
+	Logger          string     `json:"logger,omitempty"`
+	Level           string     `json:"level"`
+	ConfiguredLevel string     `json:"configured_level"`
+	RevertAt        *time.Time `json:"revert_at,omitempty"`
+}
+
+// LogLevelQuery selects a logger by the query. Logger names a module logger, the
+// application logger being used when empty.
+type LogLevelQuery struct {
+	Logger string `form:"logger"`
+}
@@ -0,0 +1,168 @@
+package admin
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/logger"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"go.uber.org/zap"
+)
+
+// LogLevelService defines the operations on the levels of the loggers.
+type LogLevelService interface {
+	GetLogLevel(name string) *LogLevelDto
+	UpdateLogLevel(dto *UpdateLogLevelDto) *LogLevelDto
+}
+
+// pendingRevert is a scheduled return of a logger to its configured level.
+type pendingRevert struct {
+	stop func() bool
+	at   time.Time
+}
+
+// LogLevelOption customizes the LogLevelService.
+type LogLevelOption func(s *logLevelService)
+
+// WithAfterFunc replaces time.AfterFunc scheduling the reverts, such as by a fake
+// timer in the tests. after calls f once d elapsed, unless the returned stop
+// function is called first.
+func WithAfterFunc(after func(d time.Duration, f func()) (stop func() bool)) LogLevelOption {
+	return func(s *logLevelService) {
+		s.after = after
+	}
+}
+
+// logLevelService changes the levels of the loggers at runtime, optionally
+// reverting them to the configured levels after a while.
+type logLevelService struct {
+	logger     *logger.Zap
+	configured func() setting.LoggerSettings
+	after      func(d time.Duration, f func()) (stop func() bool)
+
+	mu      sync.Mutex
+	reverts map[string]*pendingRevert
+}
+
+// NewLogLevelService creates a new instance of LogLevelService. The configured
+// function returns the logger settings levels are reverted to.
+func NewLogLevelService(
+	log *logger.Zap,
+	configured func() setting.LoggerSettings,
+	opts ...LogLevelOption,
+) LogLevelService {
+	s := &logLevelService{
+		logger:     log,
+		configured: configured,
+		after: func(d time.Duration, f func()) func() bool {
+			return time.AfterFunc(d, f).Stop
+		},
+		reverts: map[string]*pendingRevert{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// GetLogLevel returns the current and configured levels of a logger.
+func (s *logLevelService) GetLogLevel(name string) *LogLevelDto {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logLevel(name)
+}
+
+// UpdateLogLevel changes the level of a logger, replacing any pending revert.
+func (s *logLevelService) UpdateLogLevel(dto *UpdateLogLevelDto) *LogLevelDto {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pending, ok := s.reverts[dto.Logger]; ok {
+		pending.stop()
+		delete(s.reverts, dto.Logger)
+	}
+
+	s.setLevel(dto.Logger, dto.Level)
+	s.logger.Info("log level changed",
+		zap.String("target", loggerName(dto.Logger)),
+		zap.String("level", dto.Level),
+		zap.Int("revert_after", dto.RevertAfter),
+	)
+
+	if dto.RevertAfter > 0 {
+		name := dto.Logger
+		delay := time.Duration(dto.RevertAfter) * time.Second
+		pending := &pendingRevert{at: time.Now().Add(delay)}
+		pending.stop = s.after(delay, func() {
+			s.revert(name, pending)
+		})
+		s.reverts[name] = pending
+	}
+
+	return s.logLevel(dto.Logger)
+}
+
+// revert restores the configured level of a logger, unless the revert was
+// replaced in the meantime.
+func (s *logLevelService) revert(name string, pending *pendingRevert) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reverts[name] != pending {
+		return
+	}
+	delete(s.reverts, name)
+	s.setLevel(name, s.configuredLevel(name))
+	s.logger.Info("log level reverted",
+		zap.String("target", loggerName(name)),
+		zap.String("level", s.logLevel(name).Level),
+	)
+}
+
+// setLevel changes the level of the application logger, or of a module logger.
+func (s *logLevelService) setLevel(name, level string) {
+	if name == "" {
+		s.logger.SetLevel(level)
+		return
+	}
+	s.logger.SetModuleLevel(name, level)
+}
+
+// configuredLevel returns the level of a logger found in the configuration. It is
+// empty for module loggers without their own level, which follow the application one.
+func (s *logLevelService) configuredLevel(name string) string {
+	config := s.configured()
+	if name == "" {
+		return config.Level
+	}
+	return config.Modules[name]
+}
+
+// loggerName names a logger in the log lines.
+func loggerName(name string) string {
+	if name == "" {
+		return "application"
+	}
+	return name
+}
+
+// logLevel describes the levels of a logger, s.mu must be held.
+func (s *logLevelService) logLevel(name string) *LogLevelDto {
+	dto := &LogLevelDto{Logger: name}
+	if name == "" {
+		dto.Level = s.logger.Level().String()
+	} else {
+		dto.Level = s.logger.ModuleLevel(name).String()
+	}
+
+	dto.ConfiguredLevel = s.configuredLevel(name)
+	if dto.ConfiguredLevel == "" {
+		dto.ConfiguredLevel = s.configured().Level
+	}
+
+	if pending, ok := s.reverts[name]; ok {
+		at := pending.at
+		dto.RevertAt = &at
+	}
+	return dto
+}
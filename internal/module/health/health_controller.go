@@ -0,0 +1,56 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+)
+
+// Check reports why a dependency of the application is unavailable, if it is.
+type Check func(ctx context.Context) error
+
+// HealthController answers the liveness and readiness probes.
+type HealthController struct {
+	checks map[string]Check
+}
+
+// NewHealthController creates a new instance of HealthController running the
+// given readiness checks, keyed by the name of the dependency.
+func NewHealthController(checks map[string]Check) *HealthController {
+	return &HealthController{
+		checks: checks,
+	}
+}
+
+// Live reports that the application is running.
+func (c *HealthController) Live(ctx *gin.Context) {
+	response.OkResponse(ctx, "Alive", nil)
+}
+
+// Ready reports whether the application can serve requests, answering 503 with
+// the unavailable dependencies otherwise.
+func (c *HealthController) Ready(ctx *gin.Context) {
+	status := make(map[string]bool, len(c.checks))
+	var unavailable []string
+	for name, check := range c.checks {
+		status[name] = check(ctx) == nil
+		if !status[name] {
+			unavailable = append(unavailable, name)
+		}
+	}
+
+	if len(unavailable) > 0 {
+		sort.Strings(unavailable)
+		response.ErrorResponse(ctx, response.NewError(
+			response.ErrUnavailable,
+			errors.New("unavailable: "+strings.Join(unavailable, ", ")),
+		))
+		return
+	}
+
+	response.OkResponse(ctx, "Ready", status)
+}
@@ -0,0 +1,30 @@
+package idempotency
+
+import (
+	"net/http"
+	"time"
+)
+
+// Status values of an idempotency record.
+const (
+	StatusProcessing = "processing"
+	StatusCompleted  = "completed"
+)
+
+// IdempotencyModel stores the outcome of a request made with an Idempotency-Key,
+// so that retries of the same request can be answered with the saved response.
+type IdempotencyModel struct {
+	Key         string      `bson:"_id"`
+	Fingerprint string      `bson:"fingerprint"`
+	Status      string      `bson:"status"`
+	StatusCode  int         `bson:"status_code,omitempty"`
+	Header      http.Header `bson:"header,omitempty"`
+	Body        []byte      `bson:"body,omitempty"`
+	CreatedAt   time.Time   `bson:"created_at"`
+	ExpiresAt   time.Time   `bson:"expires_at"`
+}
+
+// CollectionName returns the name of the MongoDB collection for this model.
+func (IdempotencyModel) CollectionName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,128 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// IdempotencyRepository defines the interface for idempotency record operations.
+type IdempotencyRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Acquire(
+		ctx context.Context,
+		record *IdempotencyModel,
+	) (*IdempotencyModel, bool, *response.Error)
+	Complete(
+		ctx context.Context,
+		key string, statusCode int, header http.Header, body []byte,
+	) *response.Error
+	Release(ctx context.Context, key string) *response.Error
+}
+
+// idempotencyRepositoryImpl is a concrete implementation of IdempotencyRepository
+type idempotencyRepositoryImpl struct {
+	model *mongo.Collection
+}
+
+// NewIdempotencyRepository creates a new instance of IdempotencyRepository
+func NewIdempotencyRepository(db *mongo.Database) IdempotencyRepository {
+	return &idempotencyRepositoryImpl{
+		model: db.Collection(IdempotencyModel{}.CollectionName()),
+	}
+}
+
+// EnsureIndexes creates the TTL index removing records once they expire.
+func (r *idempotencyRepositoryImpl) EnsureIndexes(ctx context.Context) error {
+	_, err := r.model.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// Acquire inserts the record if no live record exists for its key. It reports
+// whether the record was inserted; otherwise the existing record is returned.
+func (r *idempotencyRepositoryImpl) Acquire(
+	ctx context.Context,
+	record *IdempotencyModel,
+) (*IdempotencyModel, bool, *response.Error) {
+	_, err := r.model.InsertOne(ctx, record)
+	if err == nil {
+		return record, true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, false, response.NewError(response.ErrInternalError, err)
+	}
+
+	var existing IdempotencyModel
+	err = r.model.FindOne(ctx, bson.M{"_id": record.Key}).Decode(&existing)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// The record expired in the meantime, try once more.
+			return r.acquireExpired(ctx, record)
+		}
+		return nil, false, response.NewError(response.ErrInternalError, err)
+	}
+
+	// The TTL monitor only runs periodically, expired records may still be around.
+	if existing.ExpiresAt.Before(time.Now()) {
+		_, err = r.model.DeleteOne(ctx, bson.M{
+			"_id":        record.Key,
+			"expires_at": bson.M{"$lt": time.Now()},
+		})
+		if err != nil {
+			return nil, false, response.NewError(response.ErrInternalError, err)
+		}
+		return r.acquireExpired(ctx, record)
+	}
+
+	return &existing, false, nil
+}
+
+// acquireExpired retries inserting a record whose previous holder expired.
+func (r *idempotencyRepositoryImpl) acquireExpired(
+	ctx context.Context,
+	record *IdempotencyModel,
+) (*IdempotencyModel, bool, *response.Error) {
+	if _, err := r.model.InsertOne(ctx, record); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, false, response.NewError(response.ErrConflict, err)
+		}
+		return nil, false, response.NewError(response.ErrInternalError, err)
+	}
+	return record, true, nil
+}
+
+// Complete saves the response of the request holding the key.
+func (r *idempotencyRepositoryImpl) Complete(
+	ctx context.Context,
+	key string, statusCode int, header http.Header, body []byte,
+) *response.Error {
+	_, err := r.model.UpdateOne(ctx, bson.M{"_id": key}, bson.M{
+		"$set": bson.M{
+			"status":      StatusCompleted,
+			"status_code": statusCode,
+			"header":      header,
+			"body":        body,
+		},
+	})
+	if err != nil {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
+
+// Release removes the record so that the request can be retried with the same key.
+func (r *idempotencyRepositoryImpl) Release(ctx context.Context, key string) *response.Error {
+	if _, err := r.model.DeleteOne(ctx, bson.M{"_id": key}); err != nil {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
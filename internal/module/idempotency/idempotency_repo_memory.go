@@ -0,0 +1,89 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/memstore"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// idempotencyRepositoryMemory is an in-memory implementation of
+// IdempotencyRepository with the semantics of the MongoDB one, to run tests
+// without a database.
+type idempotencyRepositoryMemory struct {
+	records *memstore.Collection[IdempotencyModel]
+}
+
+// NewMemoryIdempotencyRepository creates a new instance of IdempotencyRepository
+// keeping the records in memory.
+func NewMemoryIdempotencyRepository() IdempotencyRepository {
+	return &idempotencyRepositoryMemory{
+		records: memstore.NewCollection[IdempotencyModel](),
+	}
+}
+
+// EnsureIndexes implements IdempotencyRepository, expired records are replaced
+// when acquired.
+func (r *idempotencyRepositoryMemory) EnsureIndexes(context.Context) error {
+	return nil
+}
+
+// Acquire implements IdempotencyRepository.
+func (r *idempotencyRepositoryMemory) Acquire(
+	_ context.Context,
+	record *IdempotencyModel,
+) (*IdempotencyModel, bool, *response.Error) {
+	err := r.records.Insert(record)
+	if err == nil {
+		return record, true, nil
+	}
+	if !errors.Is(err, memstore.ErrDuplicateKey) {
+		return nil, false, response.NewError(response.ErrInternalError, err)
+	}
+
+	existing, err := r.records.FindOne(bson.M{"_id": record.Key})
+	if err != nil {
+		return nil, false, response.NewError(response.ErrInternalError, err)
+	}
+	if !existing.ExpiresAt.Before(time.Now()) {
+		return existing, false, nil
+	}
+
+	if _, err := r.records.DeleteOne(bson.M{"_id": record.Key}); err != nil {
+		return nil, false, response.NewError(response.ErrInternalError, err)
+	}
+	if err := r.records.Insert(record); err != nil {
+		return nil, false, response.NewError(response.ErrConflict, err)
+	}
+	return record, true, nil
+}
+
+// Complete implements IdempotencyRepository.
+func (r *idempotencyRepositoryMemory) Complete(
+	_ context.Context,
+	key string, statusCode int, header http.Header, body []byte,
+) *response.Error {
+	_, err := r.records.FindOneAndUpdate(bson.M{"_id": key}, bson.D{{Key: "$set", Value: bson.M{
+		"status":      StatusCompleted,
+		"status_code": statusCode,
+		"header":      header,
+		"body":        body,
+	}}})
+	// Like UpdateOne, completing a missing record is not an error.
+	if err != nil && !errors.Is(err, memstore.ErrNotFound) {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
+
+// Release implements IdempotencyRepository.
+func (r *idempotencyRepositoryMemory) Release(_ context.Context, key string) *response.Error {
+	if _, err := r.records.DeleteOne(bson.M{"_id": key}); err != nil {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
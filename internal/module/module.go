@@ -0,0 +1,77 @@
+// Package module defines the feature modules of the application and the
+// registry managing their lifecycle.
+package module
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/logger"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/openapi"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Module is a feature of the application. Modules are initialized and started
+// after the modules they depend on, and stopped before them.
+type Module interface {
+	// Name identifies the module, other modules depend on it by this name.
+	Name() string
+	// Dependencies returns the names of the modules this module uses.
+	Dependencies() []string
+	// Init builds the components of the module.
+	Init(deps Deps) error
+	// RegisterRoutes sets up the HTTP routes of the module.
+	RegisterRoutes(router *gin.Engine)
+	// OpenAPIRoutes documents the HTTP routes of the module.
+	OpenAPIRoutes() []openapi.Route
+	// Start runs the background work of the module, once every module is initialized.
+	Start(ctx context.Context) error
+	// Stop ends the background work of the module.
+	Stop(ctx context.Context) error
+	// HealthCheck reports whether the module can serve requests.
+	HealthCheck(ctx context.Context) error
+}
+
+// Deps holds the shared dependencies handed to the modules. Config returns the
+// live configuration and Lookup the initialized modules, by name.
+type Deps struct {
+	DB        *mongo.Database
+	Logger    *logger.Zap
+	Validator *validator.Validate
+	Config    func() *setting.Config
+	Lookup    func(name string) Module
+}
+
+// Base provides the optional parts of Module, to be embedded by modules without
+// dependencies, routes, background work or health checks.
+type Base struct{}
+
+// Dependencies implements Module.
+func (Base) Dependencies() []string {
+	return nil
+}
+
+// RegisterRoutes implements Module.
+func (Base) RegisterRoutes(*gin.Engine) {}
+
+// OpenAPIRoutes implements Module.
+func (Base) OpenAPIRoutes() []openapi.Route {
+	return nil
+}
+
+// Start implements Module.
+func (Base) Start(context.Context) error {
+	return nil
+}
+
+// Stop implements Module.
+func (Base) Stop(context.Context) error {
+	return nil
+}
+
+// HealthCheck implements Module.
+func (Base) HealthCheck(context.Context) error {
+	return nil
+}
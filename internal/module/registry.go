@@ -0,0 +1,158 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/openapi"
+)
+
+// Registry holds the modules of the application and manages their lifecycle in
+// dependency order.
+type Registry struct {
+	modules map[string]Module
+	// registered keeps the registration order, ordered lists the modules after
+	// their dependencies once initialized.
+	registered []Module
+	ordered    []Module
+	started    []Module
+}
+
+// NewRegistry creates a new instance of Registry.
+func NewRegistry() *Registry {
+	return &Registry{modules: map[string]Module{}}
+}
+
+// Register adds modules to the registry. Names must be unique.
+func (r *Registry) Register(modules ...Module) error {
+	for _, m := range modules {
+		if _, ok := r.modules[m.Name()]; ok {
+			return fmt.Errorf("module %q is already registered", m.Name())
+		}
+		r.modules[m.Name()] = m
+		r.registered = append(r.registered, m)
+	}
+	return nil
+}
+
+// Get returns the registered module with the given name, or nil.
+func (r *Registry) Get(name string) Module {
+	return r.modules[name]
+}
+
+// Modules returns the registered modules, in dependency order once initialized.
+func (r *Registry) Modules() []Module {
+	return r.ordered
+}
+
+// Init initializes the modules after their dependencies. It fails on unknown
+// or circular dependencies, and on the first module failing to initialize.
+func (r *Registry) Init(deps Deps) error {
+	ordered, err := r.sort()
+	if err != nil {
+		return err
+	}
+
+	deps.Lookup = r.Get
+	for _, m := range ordered {
+		if err := m.Init(deps); err != nil {
+			return fmt.Errorf("init module %q: %w", m.Name(), err)
+		}
+	}
+	r.ordered = ordered
+	return nil
+}
+
+// RegisterRoutes sets up the routes of every module.
+func (r *Registry) RegisterRoutes(router *gin.Engine) {
+	for _, m := range r.ordered {
+		m.RegisterRoutes(router)
+	}
+}
+
+// OpenAPIRoutes returns the documentation of the routes of every module.
+func (r *Registry) OpenAPIRoutes() []openapi.Route {
+	var docs []openapi.Route
+	for _, m := range r.ordered {
+		docs = append(docs, m.OpenAPIRoutes()...)
+	}
+	return docs
+}
+
+// Start starts the modules after their dependencies. When a module fails to
+// start, the modules already started are stopped.
+func (r *Registry) Start(ctx context.Context) error {
+	for _, m := range r.ordered {
+		if err := m.Start(ctx); err != nil {
+			return errors.Join(fmt.Errorf("start module %q: %w", m.Name(), err), r.Stop(ctx))
+		}
+		r.started = append(r.started, m)
+	}
+	return nil
+}
+
+// Stop stops the started modules before their dependencies, returning all the
+// errors met.
+func (r *Registry) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(r.started) - 1; i >= 0; i-- {
+		m := r.started[i]
+		if err := m.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop module %q: %w", m.Name(), err))
+		}
+	}
+	r.started = nil
+	return errors.Join(errs...)
+}
+
+// HealthChecks returns the health check of every module, keyed by its name.
+func (r *Registry) HealthChecks() map[string]func(ctx context.Context) error {
+	checks := make(map[string]func(ctx context.Context) error, len(r.ordered))
+	for _, m := range r.ordered {
+		checks[m.Name()] = m.HealthCheck
+	}
+	return checks
+}
+
+// sort orders the modules after their dependencies, keeping the registration
+// order otherwise.
+func (r *Registry) sort() ([]Module, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[string]int{}
+	ordered := make([]Module, 0, len(r.modules))
+
+	var visit func(m Module, path []string) error
+	visit = func(m Module, path []string) error {
+		switch state[m.Name()] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("circular module dependency: %v", append(path, m.Name()))
+		}
+		state[m.Name()] = visiting
+		for _, name := range m.Dependencies() {
+			dep, ok := r.modules[name]
+			if !ok {
+				return fmt.Errorf("module %q depends on unknown module %q", m.Name(), name)
+			}
+			if err := visit(dep, append(path, m.Name())); err != nil {
+				return err
+			}
+		}
+		state[m.Name()] = visited
+		ordered = append(ordered, m)
+		return nil
+	}
+
+	for _, m := range r.registered {
+		if err := visit(m, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
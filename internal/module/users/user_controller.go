@@ -1,121 +1,177 @@
 package users
 
 import (
+	"context"
+	"log"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
-	"github.com/hainguyen27798/gin-boilerplate/pkg/helpers"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
 )
 
 // UserController handles HTTP requests related to user operations.
 type UserController struct {
-	userService UserService
+	userService   UserService
+	importService UserImportService
+	validate      *validator.Validate
 }
 
 // NewUserController creates a new instance of UserController.
-func NewUserController(userService UserService) *UserController {
+func NewUserController(
+	userService UserService,
+	importService UserImportService,
+	validate *validator.Validate,
+) *UserController {
 	return &UserController{
-		userService: userService,
+		userService:   userService,
+		importService: importService,
+		validate:      validate,
 	}
 }
 
 // CreateUser handles the creation of a new user.
-func (c *UserController) CreateUser(ctx *gin.Context) {
-	var dto CreateUserDto
-	if err := ctx.ShouldBindJSON(&dto); err != nil {
-		response.ValidateErrorResponse(ctx, err)
-		return
-	}
-
-	if err := dto.Validate(); err != nil {
-		response.ValidateErrorResponse(ctx, err)
-		return
-	}
-
-	newUser, err := c.userService.CreateUser(ctx, &dto)
-	if err != nil {
-		response.ErrorResponse(ctx, err)
-		return
-	}
-
-	response.CreatedResponse(ctx, "Created user successfully", newUser)
+func (c *UserController) CreateUser() gin.HandlerFunc {
+	return response.Handle(
+		func(ctx context.Context, dto CreateUserDto) (*UserDto, *response.Error) {
+			return c.userService.CreateUser(ctx, &dto)
+		},
+		response.WithStatus(http.StatusCreated),
+		response.WithMessage("Created user successfully"),
+		response.WithValidator(c.validate),
+	)
 }
 
 // GetUserByID handles the retrieval of a user by ID.
-func (c *UserController) GetUserByID(ctx *gin.Context) {
-	id := ctx.Param("id")
-	if ok := common.IsValidObjectID(id); !ok {
-		response.ErrorResponse(ctx, response.NewError(response.ErrInvalidObjectID, nil))
-		return
-	}
-
-	user, err := c.userService.GetUserByID(ctx, id)
-	if err != nil {
-		response.ErrorResponse(ctx, err)
-		return
-	}
-
-	response.OkResponse(ctx, "Found a user", user)
+func (c *UserController) GetUserByID() gin.HandlerFunc {
+	return response.Handle(
+		func(ctx context.Context, param UserIDParam) (*UserDto, *response.Error) {
+			if ok := common.IsValidObjectID(param.ID); !ok {
+				return nil, response.NewError(response.ErrInvalidObjectID, nil)
+			}
+			return c.userService.GetUserByID(ctx, param.ID)
+		},
+		response.WithMessage("Found a user"),
+		response.WithFields(),
+	)
 }
 
 // UpdateUser handles the update of a user.
-func (c *UserController) UpdateUser(ctx *gin.Context) {
-	id := ctx.Param("id")
-	if ok := common.IsValidObjectID(id); !ok {
-		response.ErrorResponse(ctx, response.NewError(response.ErrInvalidObjectID, nil))
-		return
-	}
-
-	var dto UpdateUserDto
-	if err := ctx.ShouldBindJSON(&dto); err != nil {
-		response.ValidateErrorResponse(ctx, err)
-		return
-	}
+func (c *UserController) UpdateUser() gin.HandlerFunc {
+	return response.Handle(
+		func(ctx context.Context, req UpdateUserRequest) (*UserDto, *response.Error) {
+			if ok := common.IsValidObjectID(req.ID); !ok {
+				return nil, response.NewError(response.ErrInvalidObjectID, nil)
+			}
+			return c.userService.UpdateUser(ctx, req.ID, &req.UpdateUserDto)
+		},
+		response.WithMessage("Updated user successfully"),
+		response.WithValidator(c.validate),
+	)
+}
 
-	if err := dto.Validate(); err != nil {
-		response.ValidateErrorResponse(ctx, err)
-		return
-	}
+// DeleteUser handles the deletion of a user.
+func (c *UserController) DeleteUser() gin.HandlerFunc {
+	return response.Handle(
+		func(ctx context.Context, param UserIDParam) (any, *response.Error) {
+			if param.ID == "" {
+				return nil, response.NewError(response.ErrInvalidObjectID, nil)
+			}
+			return nil, c.userService.DeleteUser(ctx, param.ID)
+		},
+		response.WithMessage("Deleted user successfully"),
+	)
+}
 
-	userDto, err := c.userService.UpdateUser(ctx, id, &dto)
-	if err != nil {
-		response.ErrorResponse(ctx, err)
-		return
-	}
+// Login handles the authentication of a user with their credentials.
+func (c *UserController) Login() gin.HandlerFunc {
+	return response.Handle(
+		func(ctx context.Context, dto LoginDto) (*UserDto, *response.Error) {
+			return c.userService.Authenticate(ctx, &dto)
+		},
+		response.WithMessage("Logged in successfully"),
+		response.WithValidator(c.validate),
+	)
+}
 
-	response.OkResponse(ctx, "Updated user successfully", userDto)
+// GetUserByEmail handles the retrieval of a user by email.
+func (c *UserController) GetUserByEmail() gin.HandlerFunc {
+	return response.Handle(
+		func(ctx context.Context, query UserEmailQuery) (*UserDto, *response.Error) {
+			if query.Email == "" {
+				return nil, response.NewError(response.ErrInvalidObjectID, nil)
+			}
+			return c.userService.GetUserByEmail(ctx, query.Email)
+		},
+		response.WithMessage("Found a user"),
+		response.WithFields(),
+	)
 }
 
-// DeleteUser handles the deletion of a user.
-func (c *UserController) DeleteUser(ctx *gin.Context) {
-	id := ctx.Param("id")
-	if id == "" {
-		response.ErrorResponse(ctx, response.NewError(response.ErrInvalidObjectID, nil))
-		return
-	}
+// SearchUsers handles the search of the users by name or email.
+func (c *UserController) SearchUsers() gin.HandlerFunc {
+	return response.Handle(
+		func(
+			ctx context.Context,
+			query SearchUsersQuery,
+		) (*common.Page[UserSearchHit], *response.Error) {
+			return c.userService.SearchUsers(ctx, &query)
+		},
+		response.WithMessage("Found the matching users"),
+		response.WithValidator(c.validate),
+	)
+}
 
-	if err := c.userService.DeleteUser(ctx, id); err != nil {
-		helpers.Must(err)
-		response.ErrorResponse(ctx, err)
-		return
+// ImportUsers handles the upload of users to import, in CSV or NDJSON. The import
+// runs in the background, its job is returned to poll its progress.
+func (c *UserController) ImportUsers() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, MaxImportSize)
+		job, err := c.importService.Import(ctx, ctx.GetHeader("Content-Type"), body)
+		if err != nil {
+			response.ErrorResponse(ctx, err)
+			return
+		}
+		response.DataResponse(ctx, http.StatusAccepted, "Started the import", job)
 	}
-
-	response.OkResponse(ctx, "Deleted user successfully", nil)
 }
 
-// GetUserByEmail handles the retrieval of a user by email.
-func (c *UserController) GetUserByEmail(ctx *gin.Context) {
-	email := ctx.Query("email")
-	if email == "" {
-		response.ErrorResponse(ctx, response.NewError(response.ErrInvalidObjectID, nil))
-		return
-	}
+// GetImport handles the retrieval of the progress of an import.
+func (c *UserController) GetImport() gin.HandlerFunc {
+	return response.Handle(
+		func(ctx context.Context, param ImportIDParam) (*ImportJobDto, *response.Error) {
+			return c.importService.GetImport(ctx, param.ID)
+		},
+		response.WithMessage("Found the import"),
+	)
+}
 
-	user, err := c.userService.GetUserByEmail(ctx, email)
-	if err != nil {
-		response.ErrorResponse(ctx, err)
-		return
+// ExportUsers handles the export of the users in CSV or NDJSON, streamed as they
+// are read. Once the export started, errors can only end it early.
+func (c *UserController) ExportUsers() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var query ExportUsersQuery
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			response.ValidateErrorResponse(ctx, err)
+			return
+		}
+		if err := query.Validate(c.validate); err != nil {
+			response.ValidateErrorResponse(ctx, err)
+			return
+		}
+
+		export := newUserExport(ctx.Writer, query.Format)
+		if err := c.userService.ExportUsers(ctx, query.Filter(), export.write); err != nil {
+			if !export.started {
+				response.ErrorResponse(ctx, err)
+				return
+			}
+			log.Println(err.Error())
+			return
+		}
+		if err := export.close(); err != nil {
+			log.Println(err.Error())
+		}
 	}
-
-	response.OkResponse(ctx, "Found a user", user)
 }
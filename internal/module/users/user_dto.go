@@ -1,6 +1,9 @@
 package users
 
 import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
 )
 
@@ -14,8 +17,8 @@ type CreateUserDto struct {
 }
 
 // Validate validates the CreateUserDto.
-func (dto *CreateUserDto) Validate() error {
-	return common.ValidateStruct(dto)
+func (dto *CreateUserDto) Validate(v *validator.Validate) error {
+	return v.Struct(dto)
 }
 
 // UpdateUserDto is used for updating an existing user.
@@ -26,8 +29,19 @@ type UpdateUserDto struct {
 }
 
 // Validate validates the UpdateUserDto.
-func (dto *UpdateUserDto) Validate() error {
-	return common.ValidateStruct(dto)
+func (dto *UpdateUserDto) Validate(v *validator.Validate) error {
+	return v.Struct(dto)
+}
+
+// LoginDto is used for authenticating a user with their credentials.
+type LoginDto struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Validate validates the LoginDto.
+func (dto *LoginDto) Validate(v *validator.Validate) error {
+	return v.Struct(dto)
 }
 
 // UserDto is used for retrieving user information, excluding the password.
@@ -39,3 +53,97 @@ type UserDto struct {
 	Image          string `json:"image"`
 	Verified       bool   `json:"verified"`
 }
+
+// UserIDParam identifies a user by the ID in the path.
+type UserIDParam struct {
+	ID string `uri:"id"`
+}
+
+// UpdateUserRequest is the update of the user identified by the path.
+type UpdateUserRequest struct {
+	UserIDParam
+	UpdateUserDto
+}
+
+// UserEmailQuery identifies a user by the email in the query.
+type UserEmailQuery struct {
+	Email string `form:"email"`
+}
+
+// SearchUsersQuery is a search of the users by name or email, along with the
+// requested page of results.
+type SearchUsersQuery struct {
+	Q string `form:"q" json:"q" validate:"required,max=200"`
+	common.PageQuery
+}
+
+// Validate validates the SearchUsersQuery.
+func (query *SearchUsersQuery) Validate(v *validator.Validate) error {
+	return v.Struct(query)
+}
+
+// UserSearchHit is a user found by a search, with its relevance and the fields
+// matching the search.
+type UserSearchHit struct {
+	User       *UserDto    `json:"user"`
+	Score      float64     `json:"score"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+// Highlight is a field of a user matching a search, along with the ranges of
+// its value matching the terms of the search.
+type Highlight struct {
+	Field  string      `json:"field"`
+	Ranges []TextRange `json:"ranges"`
+}
+
+// TextRange is the range [Start, End) of a text, in characters.
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// ImportJobDto is the progress of an import of users.
+type ImportJobDto struct {
+	common.BaseDto `json:",inline"`
+	Format         string           `json:"format"`
+	Status         string           `json:"status"`
+	Total          int              `json:"total"`
+	Processed      int              `json:"processed"`
+	Inserted       int              `json:"inserted"`
+	Failed         int              `json:"failed"`
+	Progress       float64          `json:"progress"`
+	Errors         []ImportRowError `json:"errors"`
+	Error          string           `json:"error,omitempty"`
+	FinishedAt     *time.Time       `json:"finished_at,omitempty"`
+}
+
+// ImportIDParam identifies an import job by the ID in the path.
+type ImportIDParam struct {
+	ID string `uri:"id"`
+}
+
+// ExportUsersQuery selects the users to export and the format of the export, CSV
+// by default. The creation dates are in RFC 3339.
+type ExportUsersQuery struct {
+	Format      string    `form:"format" json:"format" validate:"omitempty,oneof=csv ndjson"`
+	Verified    *bool     `form:"verified" json:"verified"`
+	EmailDomain string    `form:"email_domain" json:"email_domain" validate:"omitempty,fqdn"`
+	CreatedFrom time.Time `form:"created_from" json:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`                                      //nolint:lll
+	CreatedTo   time.Time `form:"created_to" json:"created_to" time_format:"2006-01-02T15:04:05Z07:00" validate:"omitempty,gtfield=CreatedFrom"` //nolint:lll
+}
+
+// Validate validates the ExportUsersQuery.
+func (query *ExportUsersQuery) Validate(v *validator.Validate) error {
+	return v.Struct(query)
+}
+
+// Filter returns the filter of the exported users.
+func (query *ExportUsersQuery) Filter() UserFilter {
+	return UserFilter{
+		Verified:    query.Verified,
+		EmailDomain: query.EmailDomain,
+		CreatedFrom: query.CreatedFrom,
+		CreatedTo:   query.CreatedTo,
+	}
+}
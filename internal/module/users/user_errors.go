@@ -0,0 +1,46 @@
+package users
+
+import (
+	"errors"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+)
+
+// Machine-readable error codes returned by the user module.
+const (
+	ErrCodeUserNotFound      = "USER_NOT_FOUND"
+	ErrCodePasswordViolation = "PASSWORD_POLICY_VIOLATION"
+	ErrCodeInvalidCredential = "INVALID_CREDENTIALS"
+	ErrCodeEmailTaken        = "EMAIL_ALREADY_EXISTS"
+	ErrCodeImportNotFound    = "IMPORT_NOT_FOUND"
+	ErrCodeInvalidImport     = "INVALID_IMPORT"
+	ErrCodeImportTooLarge    = "IMPORT_TOO_LARGE"
+)
+
+// errUserNotFound is returned by the repositories when no user matches.
+func errUserNotFound() *response.Error {
+	return response.NewError(response.ErrNotFound, errors.New("user not found")).
+		WithCode(ErrCodeUserNotFound)
+}
+
+// errEmailTaken is returned by the repositories when another user has the email.
+func errEmailTaken() *response.Error {
+	return response.NewError(response.ErrConflict, errors.New("email is already in use")).
+		WithCode(ErrCodeEmailTaken)
+}
+
+// errImportNotFound is returned by the import repositories when no job matches.
+func errImportNotFound() *response.Error {
+	return response.NewError(response.ErrNotFound, errors.New("import not found")).
+		WithCode(ErrCodeImportNotFound)
+}
+
+// errInvalidImport is returned when an upload cannot be read.
+func errInvalidImport(err error) *response.Error {
+	return response.NewError(response.ErrBadRequest, err).WithCode(ErrCodeInvalidImport)
+}
+
+// errImportTooLarge is returned when an upload exceeds the limits of the imports.
+func errImportTooLarge(err error) *response.Error {
+	return response.NewError(response.ErrPayloadTooLarge, err).WithCode(ErrCodeImportTooLarge)
+}
@@ -0,0 +1,178 @@
+package users
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Formats of the imports and exports of users.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Media types of the formats of the imports and exports of users.
+const (
+	MIMECSV    = "text/csv"
+	MIMENDJSON = "application/x-ndjson"
+)
+
+// exportFlushRows is the number of users written between two flushes of an export.
+const exportFlushRows = 500
+
+// exportColumns are the columns of the CSV exports.
+var exportColumns = []string{
+	"id", "email", "first_name", "last_name", "image", "verified", "created_at", "updated_at",
+}
+
+// UserFilter selects users by their verification, the domain of their email and
+// their creation date, from CreatedFrom included to CreatedTo excluded. Zero
+// values select every user.
+type UserFilter struct {
+	Verified    *bool
+	EmailDomain string
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+}
+
+// bson returns the MongoDB filter of the users.
+func (f UserFilter) bson() bson.M {
+	filter := bson.M{}
+	if f.Verified != nil {
+		filter["verified"] = *f.Verified
+	}
+	if f.EmailDomain != "" {
+		filter["email"] = bson.M{"$regex": "@" + regexp.QuoteMeta(normalizeEmail(f.EmailDomain)) + "$"}
+	}
+	created := bson.M{}
+	if !f.CreatedFrom.IsZero() {
+		created["$gte"] = f.CreatedFrom
+	}
+	if !f.CreatedTo.IsZero() {
+		created["$lt"] = f.CreatedTo
+	}
+	if len(created) > 0 {
+		filter["created_at"] = created
+	}
+	return filter
+}
+
+// matches reports whether the user is selected by the filter.
+func (f UserFilter) matches(user *UserModel) bool {
+	return (f.Verified == nil || user.Verified == *f.Verified) &&
+		(f.EmailDomain == "" ||
+			strings.HasSuffix(user.Email, "@"+normalizeEmail(f.EmailDomain))) &&
+		(f.CreatedFrom.IsZero() || !user.CreatedAt.Before(f.CreatedFrom)) &&
+		(f.CreatedTo.IsZero() || user.CreatedAt.Before(f.CreatedTo))
+}
+
+// userExport streams users to a response in CSV or NDJSON. The response starts
+// with the first user, errors met before it can still be answered in full.
+type userExport struct {
+	w       http.ResponseWriter
+	format  string
+	csv     *csv.Writer
+	json    *json.Encoder
+	rows    int
+	started bool
+}
+
+// newUserExport returns an export of users in the format, CSV by default.
+func newUserExport(w http.ResponseWriter, format string) *userExport {
+	if format == "" {
+		format = FormatCSV
+	}
+	return &userExport{w: w, format: format}
+}
+
+// start writes the headers of the response and the header row of CSV exports.
+func (e *userExport) start() error {
+	e.started = true
+	contentType := MIMENDJSON
+	if e.format == FormatCSV {
+		contentType = MIMECSV + "; charset=utf-8"
+	}
+	e.w.Header().Set("Content-Type", contentType)
+	e.w.Header().Set("Content-Disposition", `attachment; filename="users.`+e.format+`"`)
+	e.w.WriteHeader(http.StatusOK)
+
+	if e.format == FormatCSV {
+		e.csv = csv.NewWriter(e.w)
+		return e.csv.Write(exportColumns)
+	}
+	e.json = json.NewEncoder(e.w)
+	return nil
+}
+
+// write adds a user to the export, flushing it periodically.
+func (e *userExport) write(user *UserDto) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if e.csv != nil {
+		err = e.csv.Write([]string{
+			user.ID,
+			csvSafe(user.Email),
+			csvSafe(user.FirstName),
+			csvSafe(user.LastName),
+			csvSafe(user.Image),
+			strconv.FormatBool(user.Verified),
+			user.CreatedAt.Format(time.RFC3339),
+			user.UpdatedAt.Format(time.RFC3339),
+		})
+	} else {
+		err = e.json.Encode(user)
+	}
+	if err != nil {
+		return err
+	}
+
+	if e.rows++; e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// close ends the export, which is started even when there is no user.
+func (e *userExport) close() error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+	return e.flush()
+}
+
+// flush sends the buffered users to the client.
+func (e *userExport) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// csvSafe prevents spreadsheets from evaluating a value as a formula, quoting the
+// values starting like one.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
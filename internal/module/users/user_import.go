@@ -0,0 +1,188 @@
+package users
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Limits of the uploads of users.
+const (
+	MaxImportSize   = 10 << 20
+	MaxImportRows   = 10000
+	maxImportLine   = 1 << 20
+	importBatchSize = 100
+)
+
+// importColumns sets the fields of a CreateUserDto from the columns of a CSV
+// upload, named as in the JSON of the DTO.
+var importColumns = map[string]func(dto *CreateUserDto, value string){
+	"email":      func(dto *CreateUserDto, value string) { dto.Email = value },
+	"first_name": func(dto *CreateUserDto, value string) { dto.FirstName = value },
+	"last_name":  func(dto *CreateUserDto, value string) { dto.LastName = value },
+	"password":   func(dto *CreateUserDto, value string) { dto.Password = value },
+	"image":      func(dto *CreateUserDto, value string) { dto.Image = value },
+}
+
+// requiredImportColumns are the columns without which no row could be imported.
+var requiredImportColumns = []string{"email", "first_name", "last_name", "password"}
+
+// errTooManyRows is returned when an upload exceeds MaxImportRows.
+var errTooManyRows = fmt.Errorf("the upload exceeds %d rows", MaxImportRows)
+
+// importRow is a user read from an upload, along with its line. Holding the
+// password in plaintext, rows are only staged in an ImportBatchModel.
+type importRow struct {
+	Line int           `bson:"line"`
+	DTO  CreateUserDto `bson:"dto"`
+	// Violations are the reasons the row could not be read, if any.
+	Violations []response.Violation `bson:"violations,omitempty"`
+	// UserID identifies the user of the row, for the retries of its batch to
+	// recognize the user as already inserted.
+	UserID bson.ObjectID `bson:"user_id"`
+}
+
+// importFormat returns the format of the uploads of the media type.
+func importFormat(contentType string) (string, *response.Error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case MIMECSV:
+		return FormatCSV, nil
+	case MIMENDJSON, "application/ndjson":
+		return FormatNDJSON, nil
+	default:
+		return "", response.NewError(
+			response.ErrUnsupportedMedia,
+			fmt.Errorf("unsupported media type %q, use %s or %s", mediaType, MIMECSV, MIMENDJSON),
+		)
+	}
+}
+
+// readImport reads the rows of an upload in the format.
+func readImport(format string, body io.Reader) ([]importRow, *response.Error) {
+	var rows []importRow
+	var err error
+	if format == FormatCSV {
+		rows, err = readCSV(body)
+	} else {
+		rows, err = readNDJSON(body)
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return nil, errImportTooLarge(fmt.Errorf("the upload exceeds %d bytes", maxBytesErr.Limit))
+	case errors.Is(err, errTooManyRows):
+		return nil, errImportTooLarge(err)
+	case err != nil:
+		return nil, errInvalidImport(err)
+	case len(rows) == 0:
+		return nil, errInvalidImport(errors.New("the upload has no rows"))
+	}
+	return rows, nil
+}
+
+// readCSV reads the rows of a CSV upload, whose header names the columns.
+func readCSV(body io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	setters := make([]func(*CreateUserDto, string), len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		header[i] = name
+		if setters[i] = importColumns[name]; setters[i] == nil {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		if slices.Contains(header[:i], name) {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+	}
+	for _, name := range requiredImportColumns {
+		if !slices.Contains(header, name) {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if len(rows) == MaxImportRows {
+			return nil, errTooManyRows
+		}
+
+		var row importRow
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount):
+			row.Line = parseErr.StartLine
+			row.Violations = []response.Violation{{
+				Rule:    "format",
+				Params:  []string{FormatCSV},
+				Message: fmt.Sprintf("row has %d fields, the header %d", len(record), len(header)),
+			}}
+		case err != nil:
+			return nil, err
+		default:
+			row.Line, _ = reader.FieldPos(0)
+			for i, value := range record {
+				setters[i](&row.DTO, value)
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// readNDJSON reads the rows of an NDJSON upload, one CreateUserDto by line. Blank
+// lines are skipped.
+func readNDJSON(body io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxImportLine)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if len(rows) == MaxImportRows {
+			return nil, errTooManyRows
+		}
+
+		row := importRow{Line: line}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.DTO); err != nil {
+			row.Violations = []response.Violation{{
+				Rule:    "format",
+				Params:  []string{FormatNDJSON},
+				Message: err.Error(),
+			}}
+		}
+		rows = append(rows, row)
+	}
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return nil, fmt.Errorf("a line exceeds %d bytes", maxImportLine)
+	}
+	return rows, scanner.Err()
+}
@@ -0,0 +1,156 @@
+package users
+
+import (
+	"slices"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Status values of an import job.
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// maxImportErrors is the number of row errors kept by an import job, the rows
+// failing after them are only counted.
+const maxImportErrors = 1000
+
+// importBatchTTL is how long the rows of a batch are staged at most, for the
+// plaintext passwords of the batches never imported not to stay around.
+const importBatchTTL = 24 * time.Hour
+
+// ImportJobModel tracks the import of the users of an upload, row by row.
+type ImportJobModel struct {
+	common.BaseModel `bson:",inline"`
+	Format           string           `bson:"format"`
+	Status           string           `bson:"status"`
+	Total            int              `bson:"total"`
+	Processed        int              `bson:"processed"`
+	Inserted         int              `bson:"inserted"`
+	Failed           int              `bson:"failed"`
+	Errors           []ImportRowError `bson:"errors"`
+	Error            string           `bson:"error,omitempty"`
+	FinishedAt       *time.Time       `bson:"finished_at,omitempty"`
+	// Batches are the indexes of the batches of rows recorded so far.
+	Batches []int `bson:"batches"`
+}
+
+// ImportRowError reports why the row at a line of an upload was not imported.
+type ImportRowError struct {
+	Line       int                  `bson:"line" json:"line"`
+	Violations []response.Violation `bson:"violations" json:"violations"`
+}
+
+// CollectionName returns the name of the MongoDB collection for this model.
+func (ImportJobModel) CollectionName() string {
+	return "user_import_jobs"
+}
+
+// ImportBatchModel stages the rows of a batch of an import until the job importing
+// them runs, then is deleted. Holding plaintext passwords, the rows are kept out
+// of the payloads of the jobs, which outlive them in the dead letters, and
+// expire at ExpiresAt when never imported.
+type ImportBatchModel struct {
+	ID        bson.ObjectID `bson:"_id"`
+	ImportID  bson.ObjectID `bson:"import_id"`
+	Index     int           `bson:"index"`
+	Rows      []importRow   `bson:"rows"`
+	ExpiresAt time.Time     `bson:"expires_at"`
+}
+
+// CollectionName returns the name of the MongoDB collection for this model.
+func (ImportBatchModel) CollectionName() string {
+	return "user_import_batches"
+}
+
+// ImportBatchResult is the outcome of the import of a batch of rows of an upload.
+// Error is the reason the batch failed as a whole, if any.
+type ImportBatchResult struct {
+	Batch     int
+	Processed int
+	Inserted  int
+	Failed    int
+	Errors    []ImportRowError
+	Error     string
+}
+
+// fail records that the row at the line was not imported.
+func (result *ImportBatchResult) fail(line int, violations ...response.Violation) {
+	result.Failed++
+	if len(result.Errors) < maxImportErrors {
+		result.Errors = append(result.Errors, ImportRowError{Line: line, Violations: violations})
+	}
+}
+
+// record adds the outcome of a batch to the progress of the job, once. The job
+// finishes with its last batch, or when one is recorded again while it runs.
+func (job *ImportJobModel) record(result *ImportBatchResult) {
+	if slices.Contains(job.Batches, result.Batch) {
+		if job.Status == ImportRunning && job.Processed >= job.Total {
+			job.finish("")
+		}
+		return
+	}
+	job.Batches = append(job.Batches, result.Batch)
+	job.Status = ImportRunning
+	job.Processed += result.Processed
+	job.Inserted += result.Inserted
+	job.Failed += result.Failed
+	room := max(maxImportErrors-len(job.Errors), 0)
+	job.Errors = append(job.Errors, result.Errors[:min(room, len(result.Errors))]...)
+	if result.Error != "" {
+		job.Error = result.Error
+	}
+	if job.Processed >= job.Total {
+		job.finish("")
+	}
+}
+
+// finish ends the job, as failed when there is a reason or a batch failed.
+func (job *ImportJobModel) finish(reason string) {
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+	if reason != "" {
+		job.Error = reason
+	}
+	job.Status = ImportCompleted
+	if job.Error != "" {
+		job.Status = ImportFailed
+	}
+}
+
+// ToDto returns the progress of the import job.
+func (job ImportJobModel) ToDto() *ImportJobDto {
+	progress := 100.0
+	if job.Total > 0 {
+		progress = float64(job.Processed) * 100 / float64(job.Total)
+	}
+	errs := job.Errors
+	if errs == nil {
+		errs = []ImportRowError{}
+	}
+
+	return &ImportJobDto{
+		BaseDto: common.BaseDto{
+			ID:        job.ID.Hex(),
+			CreatedAt: job.CreatedAt,
+			UpdatedAt: job.UpdatedAt,
+		},
+		Format:     job.Format,
+		Status:     job.Status,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Inserted:   job.Inserted,
+		Failed:     job.Failed,
+		Progress:   progress,
+		Errors:     errs,
+		Error:      job.Error,
+		FinishedAt: job.FinishedAt,
+	}
+}
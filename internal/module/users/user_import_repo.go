@@ -0,0 +1,219 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ImportJobRepository defines the interface for import job operations.
+type ImportJobRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, job *ImportJobModel) *response.Error
+	FindByID(ctx context.Context, id string) (*ImportJobModel, *response.Error)
+	Save(ctx context.Context, job *ImportJobModel) *response.Error
+	Record(ctx context.Context, id string, result *ImportBatchResult) *response.Error
+	StageBatches(ctx context.Context, batches []*ImportBatchModel) *response.Error
+	FindBatch(ctx context.Context, id string) (*ImportBatchModel, *response.Error)
+	DeleteBatch(ctx context.Context, id string) *response.Error
+}
+
+// importJobRepositoryImpl is a concrete implementation of ImportJobRepository
+type importJobRepositoryImpl struct {
+	model   *mongo.Collection
+	batches *mongo.Collection
+}
+
+// NewImportJobRepository creates a new instance of ImportJobRepository
+func NewImportJobRepository(db *mongo.Database) ImportJobRepository {
+	return &importJobRepositoryImpl{
+		model:   db.Collection(ImportJobModel{}.CollectionName()),
+		batches: db.Collection(ImportBatchModel{}.CollectionName()),
+	}
+}
+
+// EnsureIndexes creates the TTL index removing the staged batches once expired.
+func (r *importJobRepositoryImpl) EnsureIndexes(ctx context.Context) error {
+	_, err := r.batches.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// Create inserts a new import job.
+func (r *importJobRepositoryImpl) Create(ctx context.Context, job *ImportJobModel) *response.Error {
+	job.BeforeCreate()
+	if _, err := r.model.InsertOne(ctx, job); err != nil {
+		log.Println(err.Error())
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
+
+// FindByID retrieves an import job by its ID
+func (r *importJobRepositoryImpl) FindByID(
+	ctx context.Context,
+	id string,
+) (*ImportJobModel, *response.Error) {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, response.NewError(response.ErrInvalidObjectID, nil)
+	}
+
+	var job ImportJobModel
+	err = r.model.FindOne(ctx, bson.M{"_id": _id}).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errImportNotFound()
+	}
+	if err != nil {
+		return nil, response.NewError(response.ErrInternalError, err)
+	}
+	return &job, nil
+}
+
+// Save replaces the stored import job with its current progress.
+func (r *importJobRepositoryImpl) Save(ctx context.Context, job *ImportJobModel) *response.Error {
+	job.BeforeUpdate()
+	res, err := r.model.ReplaceOne(ctx, bson.M{"_id": job.ID}, job)
+	if err != nil {
+		log.Println(err.Error())
+		return response.NewError(response.ErrInternalError, err)
+	}
+	if res.MatchedCount == 0 {
+		return errImportNotFound()
+	}
+	return nil
+}
+
+// Record adds the outcome of a batch to the progress of an import job in a single
+// update, skipped when the batch was already recorded, so that the batches run
+// concurrently and retried are counted once. The job finishes with its last batch,
+// or with its retry when the job could not be finished after being recorded.
+func (r *importJobRepositoryImpl) Record(
+	ctx context.Context,
+	id string,
+	result *ImportBatchResult,
+) *response.Error {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return response.NewError(response.ErrInvalidObjectID, nil)
+	}
+
+	set := bson.M{"status": ImportRunning, "updated_at": time.Now()}
+	if result.Error != "" {
+		set["error"] = result.Error
+	}
+	var job ImportJobModel
+	err = r.model.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": _id, "batches": bson.M{"$ne": result.Batch}},
+		bson.D{
+			{Key: "$set", Value: set},
+			{Key: "$inc", Value: bson.M{
+				"processed": result.Processed,
+				"inserted":  result.Inserted,
+				"failed":    result.Failed,
+			}},
+			{Key: "$push", Value: bson.M{
+				"batches": result.Batch,
+				"errors":  bson.M{"$each": result.Errors, "$slice": maxImportErrors},
+			}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&job)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Println(err.Error())
+		return response.NewError(response.ErrInternalError, err)
+	}
+	// A batch already recorded by a previous attempt may have failed to finish the job.
+	if err == nil && job.Processed < job.Total {
+		return nil
+	}
+	return r.finish(ctx, _id)
+}
+
+// finish ends a running import job whose rows were all processed, as failed when
+// a batch failed. It does nothing to the other jobs, so that it can be retried.
+func (r *importJobRepositoryImpl) finish(ctx context.Context, id bson.ObjectID) *response.Error {
+	now := time.Now().UTC()
+	_, err := r.model.UpdateOne(ctx,
+		bson.M{
+			"_id":    id,
+			"status": ImportRunning,
+			"$expr":  bson.M{"$gte": bson.A{"$processed", "$total"}},
+		},
+		bson.A{bson.M{"$set": bson.M{
+			"status": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$error", ""}}, ""}},
+				ImportFailed,
+				ImportCompleted,
+			}},
+			"finished_at": now,
+			"updated_at":  now,
+		}}},
+	)
+	if err != nil {
+		log.Println(err.Error())
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
+
+// StageBatches inserts the batches of rows of an import.
+func (r *importJobRepositoryImpl) StageBatches(
+	ctx context.Context,
+	batches []*ImportBatchModel,
+) *response.Error {
+	if len(batches) == 0 {
+		return nil
+	}
+	docs := make([]any, len(batches))
+	for i, batch := range batches {
+		docs[i] = batch
+	}
+	if _, err := r.batches.InsertMany(ctx, docs); err != nil {
+		log.Println(err.Error())
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
+
+// FindBatch retrieves a staged batch by its ID, missing once imported or expired.
+func (r *importJobRepositoryImpl) FindBatch(
+	ctx context.Context,
+	id string,
+) (*ImportBatchModel, *response.Error) {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, response.NewError(response.ErrInvalidObjectID, nil)
+	}
+
+	var batch ImportBatchModel
+	err = r.batches.FindOne(ctx, bson.M{"_id": _id}).Decode(&batch)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errImportNotFound()
+	}
+	if err != nil {
+		return nil, response.NewError(response.ErrInternalError, err)
+	}
+	return &batch, nil
+}
+
+// DeleteBatch removes a staged batch once imported.
+func (r *importJobRepositoryImpl) DeleteBatch(ctx context.Context, id string) *response.Error {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return response.NewError(response.ErrInvalidObjectID, nil)
+	}
+	if _, err := r.batches.DeleteOne(ctx, bson.M{"_id": _id}); err != nil {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/memstore"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// importJobRepositoryMemory is an in-memory implementation of ImportJobRepository
+// with the semantics of the MongoDB one, to run tests without a database.
+type importJobRepositoryMemory struct {
+	// mu makes the recording of the batches atomic.
+	mu      sync.Mutex
+	jobs    *memstore.Collection[ImportJobModel]
+	batches *memstore.Collection[ImportBatchModel]
+}
+
+// NewMemoryImportJobRepository creates a new instance of ImportJobRepository
+// keeping the jobs in memory.
+func NewMemoryImportJobRepository() ImportJobRepository {
+	return &importJobRepositoryMemory{
+		jobs:    memstore.NewCollection[ImportJobModel](),
+		batches: memstore.NewCollection[ImportBatchModel](),
+	}
+}
+
+// EnsureIndexes implements ImportJobRepository, the batches never expire.
+func (r *importJobRepositoryMemory) EnsureIndexes(context.Context) error {
+	return nil
+}
+
+// Create implements ImportJobRepository.
+func (r *importJobRepositoryMemory) Create(_ context.Context, job *ImportJobModel) *response.Error {
+	job.BeforeCreate()
+	if err := r.jobs.Insert(job); err != nil {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
+
+// FindByID implements ImportJobRepository.
+func (r *importJobRepositoryMemory) FindByID(
+	_ context.Context,
+	id string,
+) (*ImportJobModel, *response.Error) {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, response.NewError(response.ErrInvalidObjectID, nil)
+	}
+
+	job, err := r.jobs.FindOne(bson.M{"_id": _id})
+	if err != nil {
+		return nil, importMemoryError(err)
+	}
+	return job, nil
+}
+
+// Save implements ImportJobRepository.
+func (r *importJobRepositoryMemory) Save(_ context.Context, job *ImportJobModel) *response.Error {
+	job.BeforeUpdate()
+	if err := r.jobs.ReplaceOne(bson.M{"_id": job.ID}, job); err != nil {
+		return importMemoryError(err)
+	}
+	return nil
+}
+
+// Record implements ImportJobRepository.
+func (r *importJobRepositoryMemory) Record(
+	_ context.Context,
+	id string,
+	result *ImportBatchResult,
+) *response.Error {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return response.NewError(response.ErrInvalidObjectID, nil)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, err := r.jobs.FindOne(bson.M{"_id": _id})
+	if err != nil {
+		return importMemoryError(err)
+	}
+	job.record(result)
+	job.BeforeUpdate()
+	if err := r.jobs.ReplaceOne(bson.M{"_id": _id}, job); err != nil {
+		return importMemoryError(err)
+	}
+	return nil
+}
+
+// StageBatches implements ImportJobRepository.
+func (r *importJobRepositoryMemory) StageBatches(
+	_ context.Context,
+	batches []*ImportBatchModel,
+) *response.Error {
+	for _, batch := range batches {
+		if err := r.batches.Insert(batch); err != nil {
+			return response.NewError(response.ErrInternalError, err)
+		}
+	}
+	return nil
+}
+
+// FindBatch implements ImportJobRepository.
+func (r *importJobRepositoryMemory) FindBatch(
+	_ context.Context,
+	id string,
+) (*ImportBatchModel, *response.Error) {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, response.NewError(response.ErrInvalidObjectID, nil)
+	}
+
+	batch, err := r.batches.FindOne(bson.M{"_id": _id})
+	if err != nil {
+		return nil, importMemoryError(err)
+	}
+	return batch, nil
+}
+
+// DeleteBatch implements ImportJobRepository.
+func (r *importJobRepositoryMemory) DeleteBatch(_ context.Context, id string) *response.Error {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return response.NewError(response.ErrInvalidObjectID, nil)
+	}
+	if _, err := r.batches.DeleteOne(bson.M{"_id": _id}); err != nil {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
+
+// importMemoryError converts the errors of the in-memory collection into the
+// errors returned by the MongoDB repository.
+func importMemoryError(err error) *response.Error {
+	if errors.Is(err, memstore.ErrNotFound) {
+		return errImportNotFound()
+	}
+	return response.NewError(response.ErrInternalError, err)
+}
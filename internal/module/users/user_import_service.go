@@ -0,0 +1,274 @@
+package users
+
+import (
+	"context"
+	"io"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/jobs"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// UserImportService defines the interface for the imports of users.
+type UserImportService interface {
+	Import(ctx context.Context, contentType string, body io.Reader) (*ImportJobDto, *response.Error)
+	GetImport(ctx context.Context, id string) (*ImportJobDto, *response.Error)
+}
+
+// importBatchJob is the type of the background jobs importing a batch of rows.
+var importBatchJob = jobs.NewType[importBatch]("users.import_batch")
+
+// importBatch is the payload of the jobs importing the batch at Index of the rows
+// of an import. The rows are staged as the ImportBatchModel of BatchID, for the
+// payloads to never hold a plaintext password.
+type importBatch struct {
+	ImportID string `bson:"import_id"`
+	Index    int    `bson:"index"`
+	BatchID  string `bson:"batch_id"`
+	// Rows is the number of rows of the batch.
+	Rows int `bson:"rows"`
+}
+
+// errBatchExpired is the error of the batches whose rows expired before being
+// imported.
+const errBatchExpired = "the rows of the batch expired before being imported"
+
+// userImportServiceImpl is the concrete implementation of UserImportService. The
+// rows of the imports are imported by batches, each by a job of the queue.
+type userImportServiceImpl struct {
+	repo       UserRepository
+	importJobs ImportJobRepository
+	queue      *jobs.Queue
+	validate   *validator.Validate
+}
+
+// NewUserImportService creates a new instance of UserImportService, registering
+// the handler of its jobs on the queue.
+func NewUserImportService(
+	repo UserRepository,
+	importJobs ImportJobRepository,
+	queue *jobs.Queue,
+	validate *validator.Validate,
+) UserImportService {
+	s := &userImportServiceImpl{
+		repo:       repo,
+		importJobs: importJobs,
+		queue:      queue,
+		validate:   validate,
+	}
+	jobs.Handle(queue, importBatchJob, s.importBatch)
+	return s
+}
+
+// Import reads the users of an upload in the format of its media type, then
+// imports them in the background. The returned job tracks the progress of the
+// import, and reports the rows failing to be imported.
+func (s *userImportServiceImpl) Import(
+	ctx context.Context,
+	contentType string,
+	body io.Reader,
+) (*ImportJobDto, *response.Error) {
+	format, err := importFormat(contentType)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readImport(format, body)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &ImportJobModel{
+		Format:  format,
+		Status:  ImportPending,
+		Total:   len(rows),
+		Errors:  []ImportRowError{},
+		Batches: []int{},
+	}
+	if err := s.importJobs.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(importBatchTTL)
+	var batches []*ImportBatchModel
+	for start := 0; start < len(rows); start += importBatchSize {
+		batch := &ImportBatchModel{
+			ID:        bson.NewObjectID(),
+			ImportID:  job.ID,
+			Index:     len(batches),
+			Rows:      rows[start:min(start+importBatchSize, len(rows))],
+			ExpiresAt: expiresAt,
+		}
+		for i := range batch.Rows {
+			batch.Rows[i].UserID = bson.NewObjectID()
+		}
+		batches = append(batches, batch)
+	}
+	if err := s.importJobs.StageBatches(ctx, batches); err != nil {
+		s.abort(ctx, job)
+		return nil, err
+	}
+
+	for _, batch := range batches {
+		payload := importBatch{
+			ImportID: job.ID.Hex(),
+			Index:    batch.Index,
+			BatchID:  batch.ID.Hex(),
+			Rows:     len(batch.Rows),
+		}
+		if _, err := jobs.Enqueue(ctx, s.queue, importBatchJob, payload); err != nil {
+			log.Println(err.Error())
+			// The batches already queued still run, the job reports the others.
+			s.abort(ctx, job)
+			return nil, response.NewError(response.ErrInternalError, err)
+		}
+	}
+	return job.ToDto(), nil
+}
+
+// abort ends the import job failing to be queued.
+func (s *userImportServiceImpl) abort(ctx context.Context, job *ImportJobModel) {
+	job.finish("the import could not be queued")
+	if err := s.importJobs.Save(context.WithoutCancel(ctx), job); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// GetImport retrieves the progress of an import by its ID
+func (s *userImportServiceImpl) GetImport(
+	ctx context.Context,
+	id string,
+) (*ImportJobDto, *response.Error) {
+	job, err := s.importJobs.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return job.ToDto(), nil
+}
+
+// importBatch validates the staged rows of a batch, then inserts the valid ones at
+// once and records the outcome on the import job. A batch failing to be inserted
+// is retried, unless on its last attempt, its rows then failing as a whole. The
+// users inserted by a previous attempt count as inserted.
+func (s *userImportServiceImpl) importBatch(ctx context.Context, batch importBatch) error {
+	result := &ImportBatchResult{
+		Batch:     batch.Index,
+		Processed: batch.Rows,
+		Errors:    []ImportRowError{},
+	}
+	staged, err := s.importJobs.FindBatch(ctx, batch.BatchID)
+	if err != nil {
+		if err.ErrorCode() != ErrCodeImportNotFound {
+			return err
+		}
+		// Unless imported by a previous attempt, which recorded the batch.
+		result.Failed, result.Error = batch.Rows, errBatchExpired
+		if err := s.importJobs.Record(ctx, batch.ImportID, result); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	users := make([]*UserModel, 0, len(staged.Rows))
+	lines := make([]int, 0, len(staged.Rows))
+	for _, row := range staged.Rows {
+		if row.Violations != nil {
+			result.fail(row.Line, row.Violations...)
+			continue
+		}
+		user, violations := s.newUser(&row.DTO)
+		if violations != nil {
+			result.fail(row.Line, violations...)
+			continue
+		}
+		user.ID = row.UserID
+		users = append(users, user)
+		lines = append(lines, row.Line)
+	}
+
+	failed, err := s.repo.CreateMany(ctx, users)
+	if err != nil {
+		if job := jobs.FromContext(ctx); job == nil || !job.LastAttempt() {
+			return err
+		}
+		result.Error = err.Error()
+	}
+	for i, line := range lines {
+		if err != nil {
+			result.fail(line, rowViolations(err)...)
+			continue
+		}
+		if err, ok := failed[i]; ok {
+			if err.ErrorCode() == ErrCodeEmailTaken {
+				err = s.insertedBefore(ctx, users[i])
+			}
+			if err != nil {
+				result.fail(line, rowViolations(err)...)
+				continue
+			}
+		}
+		result.Inserted++
+	}
+
+	if err := s.importJobs.Record(ctx, batch.ImportID, result); err != nil {
+		return err
+	}
+	// The rows expire otherwise.
+	if err := s.importJobs.DeleteBatch(ctx, batch.BatchID); err != nil {
+		log.Println(err.Error())
+	}
+	return nil
+}
+
+// insertedBefore returns nil when the user taking the email of an imported user
+// is that user, inserted by a previous attempt of its batch, and the error of the
+// user otherwise.
+func (s *userImportServiceImpl) insertedBefore(
+	ctx context.Context,
+	user *UserModel,
+) *response.Error {
+	found, err := s.repo.FindByEmail(ctx, user.Email)
+	if err != nil {
+		if err.ErrorCode() == ErrCodeUserNotFound {
+			return errEmailTaken()
+		}
+		return err
+	}
+	if found.ID != user.ID {
+		return errEmailTaken()
+	}
+	return nil
+}
+
+// newUser returns the user created by a row, or the violations of the rules of
+// CreateUserDto by the row.
+func (s *userImportServiceImpl) newUser(dto *CreateUserDto) (*UserModel, []response.Violation) {
+	if err := dto.Validate(s.validate); err != nil {
+		if violations := response.ValidationViolations(err); len(violations) > 0 {
+			return nil, violations
+		}
+		return nil, []response.Violation{{Message: err.Error()}}
+	}
+
+	user, err := newUserModel(dto)
+	if err != nil {
+		return nil, rowViolations(err)
+	}
+	return user, nil
+}
+
+// rowViolations returns the violations reporting an error of a row, the email
+// of the users already having it being its only violation.
+func rowViolations(err *response.Error) []response.Violation {
+	if violations := err.Violations(); len(violations) > 0 {
+		return violations
+	}
+
+	violation := response.Violation{Rule: err.ErrorCode(), Message: err.ServiceErr()}
+	if err.ErrorCode() == ErrCodeEmailTaken {
+		violation.Field = "email"
+	}
+	return []response.Violation{violation}
+}
@@ -4,12 +4,15 @@ import (
 	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
 )
 
-// UserModel represents the data structure for a user in the system.
+// UserModel represents the data structure for a user in the system. The names
+// are also stored lower-cased, for the prefix searches to use their indexes.
 type UserModel struct {
 	common.BaseModel `bson:",inline"`
 	Email            string `bson:"email,omitempty" json:"email,omitempty"`
 	FirstName        string `bson:"first_name,omitempty" json:"first_name,omitempty"`
 	LastName         string `bson:"last_name,omitempty" json:"last_name,omitempty"`
+	FirstNameLower   string `bson:"first_name_lower,omitempty" json:"-"`
+	LastNameLower    string `bson:"last_name_lower,omitempty" json:"-"`
 	Password         string `bson:"password,omitempty" json:"-"`
 	Image            string `bson:"image" json:"image"`
 	Verified         bool   `bson:"verified" json:"verified"`
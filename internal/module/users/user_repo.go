@@ -5,11 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/fieldset"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
-
-	"github.com/hainguyen27798/gin-boilerplate/pkg/helpers"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -17,11 +20,22 @@ import (
 
 // UserRepository defines the interface for user repository operations.
 type UserRepository interface {
+	EnsureIndexes(ctx context.Context) error
 	Create(ctx context.Context, user *UserModel) (*UserModel, *response.Error)
+	CreateMany(ctx context.Context, users []*UserModel) (map[int]*response.Error, *response.Error)
 	FindByEmail(ctx context.Context, email string) (*UserModel, *response.Error)
 	FindByID(ctx context.Context, id string) (*UserModel, *response.Error)
 	Update(ctx context.Context, id string, payload bson.D) (*UserModel, *response.Error)
 	Delete(ctx context.Context, id string) *response.Error
+	Search(ctx context.Context, query string, skip, limit int64) ([]UserMatch, int64, *response.Error)
+	Iterate(ctx context.Context, filter UserFilter, fn func(user *UserModel) error) *response.Error
+}
+
+// UserMatch is a user found by a search, along with the relevance of the match.
+// The matches of a prefix have no relevance.
+type UserMatch struct {
+	UserModel `bson:",inline"`
+	Score     float64 `bson:"score,omitempty"`
 }
 
 // userRepositoryImpl is a concrete implementation of UserRepository
@@ -36,6 +50,66 @@ func NewUserRepository(db *mongo.Database) UserRepository {
 	}
 }
 
+// emailCollation compares the emails regardless of their case, for the emails
+// stored before they were lower-cased to still be unique and found.
+var emailCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// EnsureIndexes creates the indexes of the users: the unique indexes on their
+// email, the case-insensitive one refusing the emails only differing by their
+// case, the indexes of the prefixes of their email and lower-cased names, and the
+// text index searching them. The lower-cased names of the users stored before
+// they were are stored first. Each index is created on its own, for the others to be created
+// when one cannot, such as the case-insensitive index while emails only differing
+// by their case are stored, which must then be merged.
+func (r *userRepositoryImpl) EnsureIndexes(ctx context.Context) error {
+	keys, weights := bson.D{}, bson.D{}
+	for _, field := range searchFields {
+		keys = append(keys, bson.E{Key: field.name, Value: "text"})
+		weights = append(weights, bson.E{Key: field.name, Value: field.weight})
+	}
+
+	// Store the lower-cased names of the users stored before they were.
+	var errs []error
+	if _, err := r.model.UpdateMany(ctx,
+		bson.M{"first_name_lower": bson.M{"$exists": false}},
+		bson.A{bson.M{"$set": bson.M{
+			"first_name_lower": bson.M{"$toLower": "$first_name"},
+			"last_name_lower":  bson.M{"$toLower": "$last_name"},
+		}}},
+	); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, index := range []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "email", Value: 1}},
+			Options: options.Index().
+				SetName("email_ci").
+				SetUnique(true).
+				SetCollation(emailCollation),
+		},
+		{Keys: bson.D{{Key: "first_name_lower", Value: 1}}},
+		{Keys: bson.D{{Key: "last_name_lower", Value: 1}}},
+		{
+			Keys:    keys,
+			Options: options.Index().SetName("search").SetWeights(weights),
+		},
+	} {
+		_, err := r.model.Indexes().CreateOne(ctx, index)
+		if mongo.IsDuplicateKeyError(err) {
+			err = fmt.Errorf("duplicate emails must be merged first: %w", err)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Create inserts a new user into the database. It first calls the BeforeCreate method on the user
 // model, then inserts the user into the database. If the insert operation is successful, it returns
 // the created user model. If there is an error, it returns the error.
@@ -44,7 +118,11 @@ func (r *userRepositoryImpl) Create(
 	user *UserModel,
 ) (*UserModel, *response.Error) {
 	user.BeforeCreate()
+	user.lowerNames()
 	if _, err := r.model.InsertOne(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errEmailTaken()
+		}
 		log.Println(err.Error())
 		return nil, response.NewError(response.ErrInternalError, err)
 	}
@@ -52,15 +130,60 @@ func (r *userRepositoryImpl) Create(
 	return user, nil
 }
 
-// FindByEmail retrieves a user by their email address
+// CreateMany inserts the users in a single unordered batch, so that the users
+// failing to be inserted do not prevent the others. The errors of the failed
+// users are returned by their index, an error stopping the whole batch apart.
+func (r *userRepositoryImpl) CreateMany(
+	ctx context.Context,
+	users []*UserModel,
+) (map[int]*response.Error, *response.Error) {
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	docs := make([]any, len(users))
+	for i, user := range users {
+		user.BeforeCreate()
+		user.lowerNames()
+		docs[i] = user
+	}
+
+	_, err := r.model.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		failed := make(map[int]*response.Error, len(bulkErr.WriteErrors))
+		for _, writeErr := range bulkErr.WriteErrors {
+			if mongo.IsDuplicateKeyError(writeErr.WriteError) {
+				failed[writeErr.Index] = errEmailTaken()
+				continue
+			}
+			failed[writeErr.Index] = response.NewError(response.ErrInternalError, writeErr)
+		}
+		return failed, nil
+	}
+	if err != nil {
+		log.Println(err.Error())
+		return nil, response.NewError(response.ErrInternalError, err)
+	}
+	return nil, nil
+}
+
+// FindByEmail retrieves a user by their email address, regardless of its case.
 func (r *userRepositoryImpl) FindByEmail(
 	ctx context.Context,
 	email string,
 ) (*UserModel, *response.Error) {
 	var user UserModel
-	err := r.model.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	err := r.model.FindOne(
+		ctx,
+		bson.M{"email": email},
+		findOneOptions(ctx).SetCollation(emailCollation),
+	).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errUserNotFound()
+	}
 	if err != nil {
-		return nil, response.NewError(response.ErrNotFound, errors.New("user not found"))
+		return nil, response.NewError(response.ErrInternalError, err)
 	}
 	return &user, nil
 }
@@ -70,37 +193,59 @@ func (r *userRepositoryImpl) FindByID(
 	ctx context.Context,
 	id string,
 ) (*UserModel, *response.Error) {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, response.NewError(response.ErrInvalidObjectID, nil)
+	}
+
 	var user UserModel
-	_id := helpers.MustValue(bson.ObjectIDFromHex(id))
-	err := r.model.FindOne(ctx, bson.M{"_id": _id}).Decode(&user)
+	err = r.model.FindOne(ctx, bson.M{"_id": _id}, findOneOptions(ctx)).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errUserNotFound()
+	}
 	if err != nil {
-		return nil, response.NewError(response.ErrNotFound, nil)
+		return nil, response.NewError(response.ErrInternalError, err)
 	}
 	return &user, nil
 }
 
+// findOneOptions returns the options of the reads of a user, which only read the
+// fields of the UserDto selected by the client, if any.
+func findOneOptions(ctx context.Context) *options.FindOneOptionsBuilder {
+	opts := options.FindOne()
+	projection := fieldset.FromContext(ctx).Projection(reflect.TypeFor[UserDto]())
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	return opts
+}
+
 // Update updates an existing user in the database
 func (r *userRepositoryImpl) Update(
 	ctx context.Context,
 	id string, payload bson.D,
 ) (*UserModel, *response.Error) {
-	_id := helpers.MustValue(bson.ObjectIDFromHex(id))
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, response.NewError(response.ErrInvalidObjectID, nil)
+	}
 
-	// Append "updated_at" field to the payload.
-	payload = append(payload, bson.E{
-		Key: "$set",
-		Value: bson.D{
-			{Key: "updated_at", Value: time.Now()},
-		},
-	})
+	// Add "updated_at" field to the payload.
+	payload = common.WithUpdatedAt(withLowerNames(payload), time.Now())
 
 	var userUpdated UserModel
-	err := r.model.FindOneAndUpdate(
+	err = r.model.FindOneAndUpdate(
 		ctx,
 		bson.M{"_id": _id},
 		payload,
 		options.FindOneAndUpdate().SetReturnDocument(options.After),
 	).Decode(&userUpdated)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errUserNotFound()
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errEmailTaken()
+	}
 	if err != nil {
 		return nil, response.NewError(response.ErrInternalError, err)
 	}
@@ -108,6 +253,109 @@ func (r *userRepositoryImpl) Update(
 	return &userUpdated, nil
 }
 
+// searchProjection leaves the secrets of the users out of the search results.
+var searchProjection = bson.D{{Key: "password", Value: 0}, {Key: "verification_code", Value: 0}}
+
+// Search finds the users whose name or email match the text index, ranked by their
+// text score. When none does, the users whose email, first or last name starts
+// with the query, regardless of its case, are found instead in order of email:
+// the lower-cased query is matched case-sensitively against the emails and the
+// lower-cased names, for the matches to use their indexes.
+func (r *userRepositoryImpl) Search(
+	ctx context.Context,
+	query string, skip, limit int64,
+) ([]UserMatch, int64, *response.Error) {
+	score := bson.D{{Key: "$meta", Value: "textScore"}}
+	matches, total, err := r.find(
+		ctx,
+		bson.M{"$text": bson.M{"$search": query}},
+		options.Find().
+			SetProjection(append(bson.D{{Key: "score", Value: score}}, searchProjection...)).
+			SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+			SetSkip(skip).
+			SetLimit(limit),
+	)
+	if err != nil || total > 0 {
+		return matches, total, err
+	}
+
+	prefix := bson.M{"$regex": "^" + regexp.QuoteMeta(strings.ToLower(strings.TrimSpace(query)))}
+	return r.find(
+		ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"email": prefix},
+			bson.M{"first_name_lower": prefix},
+			bson.M{"last_name_lower": prefix},
+		}},
+		options.Find().
+			SetProjection(searchProjection).
+			SetSort(bson.D{{Key: "email", Value: 1}, {Key: "_id", Value: 1}}).
+			SetSkip(skip).
+			SetLimit(limit),
+	)
+}
+
+// find returns the users matching the filter, along with the number of matches.
+func (r *userRepositoryImpl) find(
+	ctx context.Context,
+	filter bson.M,
+	opts *options.FindOptionsBuilder,
+) ([]UserMatch, int64, *response.Error) {
+	total, err := r.model.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, response.NewError(response.ErrInternalError, err)
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	cursor, err := r.model.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, response.NewError(response.ErrInternalError, err)
+	}
+	var matches []UserMatch
+	if err := cursor.All(ctx, &matches); err != nil {
+		return nil, 0, response.NewError(response.ErrInternalError, err)
+	}
+	return matches, total, nil
+}
+
+// Iterate calls fn with the users matching the filter, in order of creation,
+// without their secrets. The users are read in batches as fn goes, and an error
+// of fn stops the iteration.
+func (r *userRepositoryImpl) Iterate(
+	ctx context.Context,
+	filter UserFilter,
+	fn func(user *UserModel) error,
+) *response.Error {
+	cursor, err := r.model.Find(
+		ctx,
+		filter.bson(),
+		options.Find().
+			SetProjection(searchProjection).
+			SetSort(bson.D{{Key: "_id", Value: 1}}).
+			SetBatchSize(exportFlushRows),
+	)
+	if err != nil {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	defer func() { _ = cursor.Close(context.WithoutCancel(ctx)) }()
+
+	for cursor.Next(ctx) {
+		var user UserModel
+		if err := cursor.Decode(&user); err != nil {
+			return response.NewError(response.ErrInternalError, err)
+		}
+		if err := fn(&user); err != nil {
+			return response.NewError(response.ErrInternalError, err)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	return nil
+}
+
 // Delete removes a user from the database by their ID
 func (r *userRepositoryImpl) Delete(ctx context.Context, id string) *response.Error {
 	_id, err := bson.ObjectIDFromHex(id)
@@ -121,7 +369,7 @@ func (r *userRepositoryImpl) Delete(ctx context.Context, id string) *response.Er
 	}
 
 	if res.DeletedCount == 0 {
-		return response.NewError(response.ErrNotFound, fmt.Errorf("user not found"))
+		return errUserNotFound()
 	}
 
 	return nil
@@ -0,0 +1,245 @@
+package users
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/memstore"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// userRepositoryMemory is an in-memory implementation of UserRepository with the
+// semantics of the MongoDB one, to run tests without a database.
+type userRepositoryMemory struct {
+	users *memstore.Collection[UserModel]
+}
+
+// NewMemoryUserRepository creates a new instance of UserRepository keeping the
+// users in memory.
+func NewMemoryUserRepository() UserRepository {
+	return &userRepositoryMemory{
+		users: memstore.NewCollection[UserModel]("email"),
+	}
+}
+
+// EnsureIndexes implements UserRepository, the unique email is always enforced
+// regardless of its case.
+func (r *userRepositoryMemory) EnsureIndexes(context.Context) error {
+	return nil
+}
+
+// Create implements UserRepository.
+func (r *userRepositoryMemory) Create(
+	_ context.Context,
+	user *UserModel,
+) (*UserModel, *response.Error) {
+	user.BeforeCreate()
+	user.lowerNames()
+	if err := r.insert(user); err != nil {
+		return nil, memoryError(err)
+	}
+	return user, nil
+}
+
+// CreateMany implements UserRepository.
+func (r *userRepositoryMemory) CreateMany(
+	_ context.Context,
+	users []*UserModel,
+) (map[int]*response.Error, *response.Error) {
+	var failed map[int]*response.Error
+	for i, user := range users {
+		user.BeforeCreate()
+		user.lowerNames()
+		if err := r.insert(user); err != nil {
+			if failed == nil {
+				failed = map[int]*response.Error{}
+			}
+			failed[i] = memoryError(err)
+		}
+	}
+	return failed, nil
+}
+
+// insert inserts the user unless their email is taken, regardless of its case.
+func (r *userRepositoryMemory) insert(user *UserModel) error {
+	if _, err := r.findByEmail(user.Email); err == nil {
+		return memstore.ErrDuplicateKey
+	}
+	return r.users.Insert(user)
+}
+
+// findByEmail returns the user with the email, regardless of its case.
+func (r *userRepositoryMemory) findByEmail(email string) (*UserModel, error) {
+	all, err := r.users.Find(bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range all {
+		if strings.EqualFold(user.Email, email) {
+			return &user, nil
+		}
+	}
+	return nil, memstore.ErrNotFound
+}
+
+// FindByEmail implements UserRepository.
+func (r *userRepositoryMemory) FindByEmail(
+	_ context.Context,
+	email string,
+) (*UserModel, *response.Error) {
+	user, err := r.findByEmail(email)
+	if err != nil {
+		return nil, memoryError(err)
+	}
+	return user, nil
+}
+
+// FindByID implements UserRepository.
+func (r *userRepositoryMemory) FindByID(
+	_ context.Context,
+	id string,
+) (*UserModel, *response.Error) {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, response.NewError(response.ErrInvalidObjectID, nil)
+	}
+
+	user, err := r.users.FindOne(bson.M{"_id": _id})
+	if err != nil {
+		return nil, memoryError(err)
+	}
+	return user, nil
+}
+
+// Update implements UserRepository.
+func (r *userRepositoryMemory) Update(
+	_ context.Context,
+	id string, payload bson.D,
+) (*UserModel, *response.Error) {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, response.NewError(response.ErrInvalidObjectID, nil)
+	}
+
+	user, err := r.users.FindOneAndUpdate(
+		bson.M{"_id": _id},
+		common.WithUpdatedAt(withLowerNames(payload), time.Now()),
+	)
+	if err != nil {
+		return nil, memoryError(err)
+	}
+	return user, nil
+}
+
+// Delete implements UserRepository.
+func (r *userRepositoryMemory) Delete(_ context.Context, id string) *response.Error {
+	_id, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		return response.NewError(response.ErrInvalidObjectID, nil)
+	}
+
+	deleted, err := r.users.DeleteOne(bson.M{"_id": _id})
+	if err != nil {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	if !deleted {
+		return errUserNotFound()
+	}
+	return nil
+}
+
+// Search implements UserRepository. The text score approximates the one of the
+// MongoDB text index, without stemming or stop words.
+func (r *userRepositoryMemory) Search(
+	_ context.Context,
+	query string, skip, limit int64,
+) ([]UserMatch, int64, *response.Error) {
+	all, err := r.users.Find(bson.M{})
+	if err != nil {
+		return nil, 0, response.NewError(response.ErrInternalError, err)
+	}
+
+	var matches []UserMatch
+	terms := searchTerms(query)
+	for _, user := range all {
+		if score := textScore(&user, terms); score > 0 {
+			matches = append(matches, UserMatch{UserModel: user, Score: score})
+		}
+	}
+	slices.SortFunc(matches, func(a, b UserMatch) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	if len(matches) == 0 {
+		for _, user := range all {
+			if hasPrefix(&user, query) {
+				matches = append(matches, UserMatch{UserModel: user})
+			}
+		}
+		slices.SortFunc(matches, func(a, b UserMatch) int {
+			if c := strings.Compare(a.Email, b.Email); c != 0 {
+				return c
+			}
+			return bytes.Compare(a.ID[:], b.ID[:])
+		})
+	}
+
+	total := int64(len(matches))
+	matches = matches[min(skip, total):min(skip+limit, total)]
+	for i := range matches {
+		matches[i].Password, matches[i].VerificationCode = "", ""
+	}
+	return matches, total, nil
+}
+
+// Iterate implements UserRepository.
+func (r *userRepositoryMemory) Iterate(
+	ctx context.Context,
+	filter UserFilter,
+	fn func(user *UserModel) error,
+) *response.Error {
+	all, err := r.users.Find(bson.M{})
+	if err != nil {
+		return response.NewError(response.ErrInternalError, err)
+	}
+	slices.SortFunc(all, func(a, b UserModel) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	for _, user := range all {
+		if !filter.matches(&user) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return response.NewError(response.ErrInternalError, err)
+		}
+		user.Password, user.VerificationCode = "", ""
+		if err := fn(&user); err != nil {
+			return response.NewError(response.ErrInternalError, err)
+		}
+	}
+	return nil
+}
+
+// memoryError converts the errors of the in-memory collection into the errors
+// returned by the MongoDB repository.
+func memoryError(err error) *response.Error {
+	switch {
+	case errors.Is(err, memstore.ErrNotFound):
+		return errUserNotFound()
+	case errors.Is(err, memstore.ErrDuplicateKey):
+		return errEmailTaken()
+	default:
+		return response.NewError(response.ErrInternalError, err)
+	}
+}
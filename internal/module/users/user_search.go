@@ -0,0 +1,166 @@
+package users
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// searchField is a field of the users searched by text, with its weight in the
+// relevance of the matches.
+type searchField struct {
+	name   string
+	weight int32
+}
+
+// searchFields are the fields of the text index of the users, matching the email
+// counting twice as much as matching a name.
+var searchFields = []searchField{
+	{name: "email", weight: 10},
+	{name: "first_name", weight: 5},
+	{name: "last_name", weight: 5},
+}
+
+// lowerNames stores the lower-cased names of the user.
+func (user *UserModel) lowerNames() {
+	user.FirstNameLower = strings.ToLower(user.FirstName)
+	user.LastNameLower = strings.ToLower(user.LastName)
+}
+
+// withLowerNames adds the lower-cased names to the $set of an update payload
+// setting the names.
+func withLowerNames(payload bson.D) bson.D {
+	for i, elem := range payload {
+		if elem.Key != "$set" {
+			continue
+		}
+		var set bson.D
+		switch value := elem.Value.(type) {
+		case bson.D:
+			set = value
+		case *bson.D:
+			if value == nil {
+				continue
+			}
+			set = *value
+		default:
+			continue
+		}
+		for _, field := range set {
+			name, ok := field.Value.(string)
+			if !ok || (field.Key != "first_name" && field.Key != "last_name") {
+				continue
+			}
+			set = append(set, bson.E{Key: field.Key + "_lower", Value: strings.ToLower(name)})
+		}
+		payload[i].Value = set
+	}
+	return payload
+}
+
+// normalizeEmail returns the form the emails are stored and looked up in.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// hasPrefix reports whether the email or a lower-cased name of the user starts
+// with the lower-cased search q, the way MongoDB matches the prefixes.
+func hasPrefix(user *UserModel, q string) bool {
+	prefix := strings.ToLower(strings.TrimSpace(q))
+	for _, value := range []string{user.Email, user.FirstNameLower, user.LastNameLower} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchTerms splits a search into lower-cased terms the way the text index splits
+// the values: on anything but letters and digits.
+func searchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchValue returns the value of a searched field of the user.
+func (user *UserModel) searchValue(field string) string {
+	switch field {
+	case "email":
+		return user.Email
+	case "first_name":
+		return user.FirstName
+	case "last_name":
+		return user.LastName
+	default:
+		return ""
+	}
+}
+
+// textScore approximates the relevance given by the text index to the user: the
+// weights of the fields having a term among their words.
+func textScore(user *UserModel, terms []string) float64 {
+	var score float64
+	for _, field := range searchFields {
+		words := searchTerms(user.searchValue(field.name))
+		for _, term := range terms {
+			if slices.Contains(words, term) {
+				score += float64(field.weight)
+			}
+		}
+	}
+	return score
+}
+
+// highlights returns the fields of the user matching the search q, with the ranges
+// of their value containing a term of the search, or the search as a whole for a
+// prefix of the value.
+func highlights(user *UserModel, q string) []Highlight {
+	terms := searchTerms(q)
+	found := []Highlight{}
+	for _, field := range searchFields {
+		value := []rune(strings.ToLower(user.searchValue(field.name)))
+		var ranges []TextRange
+		for _, term := range terms {
+			ranges = append(ranges, occurrences(value, []rune(term))...)
+		}
+		if prefix := []rune(strings.ToLower(strings.TrimSpace(q))); len(prefix) > 0 &&
+			strings.HasPrefix(string(value), string(prefix)) {
+			ranges = append(ranges, TextRange{Start: 0, End: len(prefix)})
+		}
+		if len(ranges) > 0 {
+			found = append(found, Highlight{Field: field.name, Ranges: mergeRanges(ranges)})
+		}
+	}
+	return found
+}
+
+// occurrences returns the ranges of the text containing term.
+func occurrences(text, term []rune) []TextRange {
+	var ranges []TextRange
+	for i := 0; i+len(term) <= len(text); i++ {
+		if slices.Equal(text[i:i+len(term)], term) {
+			ranges = append(ranges, TextRange{Start: i, End: i + len(term)})
+		}
+	}
+	return ranges
+}
+
+// mergeRanges sorts the ranges and merges the overlapping ones.
+func mergeRanges(ranges []TextRange) []TextRange {
+	slices.SortFunc(ranges, func(a, b TextRange) int {
+		return a.Start - b.Start
+	})
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start > last.End {
+			merged = append(merged, r)
+			continue
+		}
+		last.End = max(last.End, r.End)
+	}
+	return merged
+}
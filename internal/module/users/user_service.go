@@ -3,12 +3,16 @@ package users
 import (
 	"context"
 	"crypto/rand"
+	"errors"
+	"strings"
 
 	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
 
 	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/helpers"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/validations"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.uber.org/zap"
 )
 
 // UserService defines the interface for user-related operations.
@@ -18,17 +22,26 @@ type UserService interface {
 	GetUserByID(ctx context.Context, id string) (*UserDto, *response.Error)
 	UpdateUser(ctx context.Context, id string, user *UpdateUserDto) (*UserDto, *response.Error)
 	DeleteUser(ctx context.Context, id string) *response.Error
+	Authenticate(ctx context.Context, credentials *LoginDto) (*UserDto, *response.Error)
+	SearchUsers(
+		ctx context.Context,
+		query *SearchUsersQuery,
+	) (*common.Page[UserSearchHit], *response.Error)
+	ExportUsers(ctx context.Context, filter UserFilter, fn func(user *UserDto) error) *response.Error
 }
 
 // userServiceImpl is the concrete implementation of UserService
 type userServiceImpl struct {
-	repo UserRepository
+	repo   UserRepository
+	logger *zap.Logger
 }
 
-// NewUserService creates a new instance of UserService
-func NewUserService(repo UserRepository) UserService {
+// NewUserService creates a new instance of UserService logging with the logger
+// of the module.
+func NewUserService(repo UserRepository, logger *zap.Logger) UserService {
 	return &userServiceImpl{
-		repo: repo,
+		repo:   repo,
+		logger: logger,
 	}
 }
 
@@ -37,22 +50,11 @@ func (s *userServiceImpl) CreateUser(
 	ctx context.Context,
 	user *CreateUserDto,
 ) (*UserDto, *response.Error) {
-	// Hash password before storing
-	passwordHashed, err := helpers.HashPassword(user.Password)
+	newUser, err := newUserModel(user)
 	if err != nil {
 		return nil, err
 	}
 
-	newUser := &UserModel{
-		Email:            user.Email,
-		FirstName:        user.FirstName,
-		LastName:         user.LastName,
-		Password:         passwordHashed,
-		Image:            user.Image,
-		VerificationCode: generateVerificationCode(),
-		Verified:         false,
-	}
-
 	// Create user in repository
 	userCreated, err := s.repo.Create(ctx, newUser)
 	if err != nil {
@@ -67,7 +69,7 @@ func (s *userServiceImpl) GetUserByEmail(
 	ctx context.Context,
 	email string,
 ) (*UserDto, *response.Error) {
-	user, err := s.repo.FindByEmail(ctx, email)
+	user, err := s.repo.FindByEmail(ctx, normalizeEmail(email))
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +112,142 @@ func (s *userServiceImpl) DeleteUser(ctx context.Context, id string) *response.E
 	return s.repo.Delete(ctx, id)
 }
 
+// Authenticate verifies the credentials of a user. Password hashes made with an
+// outdated algorithm or parameters are upgraded on success. Unknown emails still
+// go through a password verification, so that the time taken does not reveal
+// which emails are registered.
+func (s *userServiceImpl) Authenticate(
+	ctx context.Context,
+	credentials *LoginDto,
+) (*UserDto, *response.Error) {
+	errInvalidCredentials := response.NewError(
+		response.ErrUnauthorized,
+		errors.New("invalid email or password"),
+	).WithCode(ErrCodeInvalidCredential)
+
+	user, err := s.repo.FindByEmail(ctx, normalizeEmail(credentials.Email))
+	if err != nil {
+		helpers.VerifyPassword(credentials.Password, helpers.DummyPasswordHash())
+		if err.ErrorCode() == ErrCodeUserNotFound {
+			return nil, errInvalidCredentials
+		}
+		return nil, err
+	}
+
+	ok, needsRehash := helpers.VerifyPassword(credentials.Password, user.Password)
+	if !ok {
+		return nil, errInvalidCredentials
+	}
+
+	if needsRehash {
+		s.rehashPassword(ctx, user.ID.Hex(), credentials.Password)
+	}
+
+	return user.ToDto(), nil
+}
+
+// SearchUsers finds the users whose name or email match the query, the most
+// relevant first, along with the fields matching it.
+func (s *userServiceImpl) SearchUsers(
+	ctx context.Context,
+	query *SearchUsersQuery,
+) (*common.Page[UserSearchHit], *response.Error) {
+	q := strings.TrimSpace(query.Q)
+	if q == "" {
+		return common.NewPage[UserSearchHit](nil, query.PageQuery, 0), nil
+	}
+
+	matches, total, err := s.repo.Search(ctx, q, query.Skip(), int64(query.Size()))
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]UserSearchHit, len(matches))
+	for i, match := range matches {
+		hits[i] = UserSearchHit{
+			User:       match.ToDto(),
+			Score:      match.Score,
+			Highlights: highlights(&match.UserModel, q),
+		}
+	}
+	return common.NewPage(hits, query.PageQuery, total), nil
+}
+
+// ExportUsers calls fn with the users matching the filter, in order of creation.
+// An error of fn stops the export.
+func (s *userServiceImpl) ExportUsers(
+	ctx context.Context,
+	filter UserFilter,
+	fn func(user *UserDto) error,
+) *response.Error {
+	return s.repo.Iterate(ctx, filter, func(user *UserModel) error {
+		return fn(user.ToDto())
+	})
+}
+
+// rehashPassword replaces the stored password hash with one made by the current
+// hasher. Failures are only logged, the user is authenticated either way.
+func (s *userServiceImpl) rehashPassword(ctx context.Context, id, password string) {
+	passwordHashed, err := helpers.HashPassword(password)
+	if err != nil {
+		s.logger.Error("hash password fail", zap.String("user_id", id), zap.Error(err))
+		return
+	}
+
+	payload := bson.D{{Key: "$set", Value: bson.D{{Key: "password", Value: passwordHashed}}}}
+	if _, err := s.repo.Update(ctx, id, payload); err != nil {
+		s.logger.Error("rehash password fail", zap.String("user_id", id), zap.Error(err))
+	}
+}
+
+// newUserModel returns the unverified user created by the DTO, with its email in
+// the stored form and its password hashed once it meets the password policy.
+func newUserModel(user *CreateUserDto) (*UserModel, *response.Error) {
+	// Store the emails in a single form, for lookups and searches to find them
+	email := normalizeEmail(user.Email)
+
+	// Enforce the password policy, the DTO may not have been validated
+	err := checkPasswordPolicy(user.Password, email, user.FirstName, user.LastName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hash password before storing
+	passwordHashed, err := helpers.HashPassword(user.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserModel{
+		Email:            email,
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Password:         passwordHashed,
+		Image:            user.Image,
+		VerificationCode: generateVerificationCode(),
+		Verified:         false,
+	}, nil
+}
+
+// checkPasswordPolicy returns a validation error listing the violated password
+// policy rules, if any.
+func checkPasswordPolicy(password string, personal ...string) *response.Error {
+	violated := validations.CurrentPasswordPolicy().Check(password, personal...)
+	if len(violated) == 0 {
+		return nil
+	}
+
+	return response.NewError(
+		response.ErrValidation,
+		errors.New("password does not meet the password policy"),
+	).WithCode(ErrCodePasswordViolation).WithViolations(response.Violation{
+		Field:   "password",
+		Rule:    "strongPassword",
+		Params:  violated,
+		Message: "password does not meet the password policy: " + strings.Join(violated, ", "),
+	})
+}
+
 // Helper function to generate verification code
 func generateVerificationCode() string {
 	otpChars := "1234567890"
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"expvar"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/admin"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/openapi"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+)
+
+// AdminSecurity is the security scheme of the administration routes, a bearer
+// admin token.
+const AdminSecurity = "adminToken"
+
+// RegisterAdminRoutes sets up the administration routes, restricted by the auth
+// middleware to the holders of the admin token.
+func RegisterAdminRoutes(
+	router *gin.Engine,
+	adminController *admin.AdminController,
+	auth gin.HandlerFunc,
+) {
+	adminRoutes := router.Group("admin", auth, response.Acceptable())
+	{
+		adminRoutes.GET("/log-level", adminController.GetLogLevel())    // Get the level of a logger
+		adminRoutes.PUT("/log-level", adminController.UpdateLogLevel()) // Change the level of a logger
+		adminRoutes.GET("/metrics", gin.WrapH(expvar.Handler()))        // Get the expvar metrics
+	}
+}
+
+// AdminRouteDocs documents the administration routes.
+func AdminRouteDocs() []openapi.Route {
+	tags := []string{"admin"}
+	security := []string{AdminSecurity}
+	return []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/admin/log-level",
+			OperationID: "getLogLevel",
+			Summary:     "Get the level of a logger",
+			Tags:        tags,
+			Query: []openapi.Param{{
+				Name:        "logger",
+				Description: "Module logger, the application logger when empty",
+			}},
+			Response: response.TDataResponse[*admin.LogLevelDto]{},
+			Errors:   []int{http.StatusUnauthorized},
+			Security: security,
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/admin/log-level",
+			OperationID: "updateLogLevel",
+			Summary:     "Change the level of a logger",
+			Tags:        tags,
+			Request:     admin.UpdateLogLevelDto{},
+			Response:    response.TDataResponse[*admin.LogLevelDto]{},
+			Errors:      []int{http.StatusBadRequest, http.StatusUnauthorized},
+			Security:    security,
+		},
+		{
+			// The expvar metrics are not wrapped in the response envelope.
+			Method: http.MethodGet,
+			Path:   "/admin/metrics",
+			Hidden: true,
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/health"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/openapi"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+)
+
+// RegisterHealthRoutes sets up the liveness and readiness probes.
+func RegisterHealthRoutes(router *gin.Engine, healthController *health.HealthController) {
+	healthRoutes := router.Group("health", response.Acceptable())
+	{
+		healthRoutes.GET("/live", healthController.Live)   // Liveness probe
+		healthRoutes.GET("/ready", healthController.Ready) // Readiness probe
+	}
+}
+
+// HealthRouteDocs documents the liveness and readiness probes.
+func HealthRouteDocs() []openapi.Route {
+	tags := []string{"health"}
+	return []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/health/live",
+			OperationID: "live",
+			Summary:     "Liveness probe",
+			Tags:        tags,
+			Response:    response.TDataResponse[any]{},
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/health/ready",
+			OperationID: "ready",
+			Summary:     "Readiness probe, reporting the availability of each dependency",
+			Tags:        tags,
+			Response:    response.TDataResponse[map[string]bool]{},
+			Errors:      []int{http.StatusServiceUnavailable},
+		},
+	}
+}
@@ -0,0 +1,60 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/metadata"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/openapi"
+)
+
+// apiTitle is the title of the OpenAPI document.
+const apiTitle = "Gin Boilerplate API"
+
+// RegisterOpenAPIRoutes serves the OpenAPI document of the routes registered on
+// the router, which must all be documented by the health routes docs or docs, and
+// the Swagger UI displaying it when ui is set and openapi.UIAvailable. It must be
+// called once every other route is registered, and reports the routes drifting
+// from their documentation.
+func RegisterOpenAPIRoutes(router *gin.Engine, docs []openapi.Route, ui bool) error {
+	ui = ui && openapi.UIAvailable
+	var doc *openapi.Document
+	router.GET("/openapi.json", openapi.Handler(&doc)) // Get the OpenAPI document
+	if ui {
+		// Browse the API
+		router.GET("/docs", openapi.UIHandler(apiTitle, "/openapi.json", "/docs/assets"))
+		// Get the assets of the Swagger UI
+		router.GET("/docs/assets/*filepath", openapi.AssetsHandler())
+	}
+
+	spec := openapi.New(openapi.Info{Title: apiTitle, Version: metadata.Version}).
+		SecurityScheme(AdminSecurity, &openapi.SecurityScheme{
+			Type:        "http",
+			Scheme:      "bearer",
+			Description: "Admin token of the configuration",
+		}).
+		SecurityScheme(StaffSecurity, &openapi.SecurityScheme{
+			Type:        "http",
+			Scheme:      "bearer",
+			Description: "Staff token of the configuration",
+		}).
+		Add(HealthRouteDocs()...).
+		Add(docs...).
+		Add(openAPIRouteDocs(ui)...)
+
+	doc, err := spec.Build(router.Routes())
+	return err
+}
+
+// openAPIRouteDocs documents the routes serving the documentation, left out of
+// the document.
+func openAPIRouteDocs(ui bool) []openapi.Route {
+	docs := []openapi.Route{{Method: http.MethodGet, Path: "/openapi.json", Hidden: true}}
+	if ui {
+		docs = append(docs,
+			openapi.Route{Method: http.MethodGet, Path: "/docs", Hidden: true},
+			openapi.Route{Method: http.MethodGet, Path: "/docs/assets/*filepath", Hidden: true},
+		)
+	}
+	return docs
+}
@@ -1,19 +1,195 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/middlewares"
 	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/openapi"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
 )
 
+// StaffSecurity is the security scheme of the routes restricted to the support
+// staff, a bearer staff token.
+const StaffSecurity = "staffToken"
+
 // RegisterUserRoutes sets up the routes for user-related operations.
-func RegisterUserRoutes(router *gin.Engine, userController *users.UserController) {
+// The idempotent middleware guards the creation endpoint against retried requests,
+// and the staff middleware restricts the search, the imports and the exports to the
+// support staff. The export streams its own formats, the other routes answer 406 to
+// the requests accepting none of the response formats.
+func RegisterUserRoutes(
+	router *gin.Engine,
+	userController *users.UserController,
+	idempotent gin.HandlerFunc,
+	staff gin.HandlerFunc,
+) {
 	// Group user-related routes
-	userRoutes := router.Group("v1/users")
+	userRoutes := router.Group("v1/users", middlewares.RateLimit("users"))
+	{
+		userRoutes.GET("/export", staff, userController.ExportUsers()) // Export the users
+	}
+	negotiatedRoutes := userRoutes.Group("", response.Acceptable())
 	{
-		userRoutes.POST("", userController.CreateUser)       // Create a new user
-		userRoutes.GET("/:id", userController.GetUserByID)   // Get a user by ID
-		userRoutes.GET("", userController.GetUserByEmail)    // Get a user by email
-		userRoutes.PUT("/:id", userController.UpdateUser)    // Update a user
-		userRoutes.DELETE("/:id", userController.DeleteUser) // Delete a user
+		negotiatedRoutes.POST("", idempotent, userController.CreateUser())     // Create a new user
+		negotiatedRoutes.POST("/login", userController.Login())                // Log a user in
+		negotiatedRoutes.GET("/search", staff, userController.SearchUsers())   // Search the users
+		negotiatedRoutes.POST("/import", staff, userController.ImportUsers())  // Import users
+		negotiatedRoutes.GET("/import/:id", staff, userController.GetImport()) // Get an import
+		negotiatedRoutes.GET("/:id", userController.GetUserByID())             // Get a user by ID
+		negotiatedRoutes.GET("", userController.GetUserByEmail())              // Get a user by email
+		negotiatedRoutes.PUT("/:id", userController.UpdateUser())              // Update a user
+		negotiatedRoutes.DELETE("/:id", userController.DeleteUser())           // Delete a user
+	}
+}
+
+// UserRouteDocs documents the routes for user-related operations.
+func UserRouteDocs() []openapi.Route {
+	tags := []string{"users"}
+	return []openapi.Route{
+		{
+			Method:      http.MethodPost,
+			Path:        "/v1/users",
+			OperationID: "createUser",
+			Summary:     "Create a new user",
+			Tags:        tags,
+			Request:     users.CreateUserDto{},
+			Status:      http.StatusCreated,
+			Response:    response.TDataResponse[*users.UserDto]{},
+			Errors: []int{
+				http.StatusBadRequest,
+				http.StatusConflict,
+				http.StatusUnprocessableEntity,
+				http.StatusTooManyRequests,
+			},
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/v1/users/login",
+			OperationID: "login",
+			Summary:     "Log a user in",
+			Tags:        tags,
+			Request:     users.LoginDto{},
+			Response:    response.TDataResponse[*users.UserDto]{},
+			Errors: []int{
+				http.StatusBadRequest,
+				http.StatusUnauthorized,
+				http.StatusTooManyRequests,
+			},
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/users/:id",
+			OperationID: "getUserByID",
+			Summary:     "Get a user by ID",
+			Tags:        tags,
+			Query:       []openapi.Param{openapi.FieldsParam},
+			Response:    response.TDataResponse[*users.UserDto]{},
+			Errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusTooManyRequests},
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/users",
+			OperationID: "getUserByEmail",
+			Summary:     "Get a user by email",
+			Tags:        tags,
+			Query:       []openapi.Param{{Name: "email", Required: true}, openapi.FieldsParam},
+			Response:    response.TDataResponse[*users.UserDto]{},
+			Errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusTooManyRequests},
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/users/search",
+			OperationID: "searchUsers",
+			Summary:     "Search the users by name or email",
+			Tags:        tags,
+			Query: []openapi.Param{
+				{Name: "q", Description: "Words of the names or emails, or an email prefix", Required: true},
+				{Name: "page", Description: "Number of the page, from 1"},
+				{Name: "limit", Description: "Size of the page, 20 by default and 100 at most"},
+			},
+			Security: []string{StaffSecurity},
+			Response: response.TDataResponse[*common.Page[users.UserSearchHit]]{},
+			Errors: []int{
+				http.StatusBadRequest,
+				http.StatusUnauthorized,
+				http.StatusTooManyRequests,
+			},
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/v1/users/import",
+			OperationID: "importUsers",
+			Summary:     "Import users from a CSV or NDJSON upload in the background",
+			Tags:        tags,
+			Upload:      []string{users.MIMECSV, users.MIMENDJSON},
+			Security:    []string{StaffSecurity},
+			Status:      http.StatusAccepted,
+			Response:    response.TDataResponse[*users.ImportJobDto]{},
+			Errors: []int{
+				http.StatusBadRequest,
+				http.StatusUnauthorized,
+				http.StatusRequestEntityTooLarge,
+				http.StatusUnsupportedMediaType,
+				http.StatusTooManyRequests,
+			},
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/users/import/:id",
+			OperationID: "getImport",
+			Summary:     "Get the progress of an import",
+			Tags:        tags,
+			Security:    []string{StaffSecurity},
+			Response:    response.TDataResponse[*users.ImportJobDto]{},
+			Errors: []int{
+				http.StatusBadRequest,
+				http.StatusUnauthorized,
+				http.StatusNotFound,
+				http.StatusTooManyRequests,
+			},
+		},
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/users/export",
+			OperationID: "exportUsers",
+			Summary:     "Export the users in CSV or NDJSON",
+			Tags:        tags,
+			Query: []openapi.Param{
+				{Name: "format", Description: "csv, by default, or ndjson"},
+				{Name: "verified", Description: "Whether the users verified their email"},
+				{Name: "email_domain", Description: "Domain of the emails of the users"},
+				{Name: "created_from", Description: "Earliest creation date, in RFC 3339"},
+				{Name: "created_to", Description: "Creation date before which, in RFC 3339"},
+			},
+			Security: []string{StaffSecurity},
+			Download: []string{users.MIMECSV, users.MIMENDJSON},
+			Errors: []int{
+				http.StatusBadRequest,
+				http.StatusUnauthorized,
+				http.StatusTooManyRequests,
+			},
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/v1/users/:id",
+			OperationID: "updateUser",
+			Summary:     "Update a user",
+			Tags:        tags,
+			Request:     users.UpdateUserDto{},
+			Response:    response.TDataResponse[*users.UserDto]{},
+			Errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusTooManyRequests},
+		},
+		{
+			Method:      http.MethodDelete,
+			Path:        "/v1/users/:id",
+			OperationID: "deleteUser",
+			Summary:     "Delete a user",
+			Tags:        tags,
+			Response:    response.TDataResponse[any]{},
+			Errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusTooManyRequests},
+		},
 	}
 }
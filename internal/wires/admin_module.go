@@ -0,0 +1,49 @@
+package wires
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/middlewares"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/admin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/routes"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/openapi"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+)
+
+// AdminModule provides the administration endpoints.
+type AdminModule struct {
+	module.Base
+	controller *admin.AdminController
+	auth       gin.HandlerFunc
+}
+
+// NewAdminModule creates a new instance of AdminModule.
+func NewAdminModule() *AdminModule {
+	return &AdminModule{}
+}
+
+// Name implements module.Module.
+func (m *AdminModule) Name() string {
+	return "admin"
+}
+
+// Init implements module.Module.
+func (m *AdminModule) Init(deps module.Deps) error {
+	logLevelService := admin.NewLogLevelService(
+		deps.Logger,
+		func() setting.LoggerSettings { return deps.Config().Logger },
+	)
+	m.controller = admin.NewAdminController(logLevelService, deps.Validator)
+	m.auth = middlewares.AdminAuth(func() string { return deps.Config().Admin.Token })
+	return nil
+}
+
+// RegisterRoutes implements module.Module.
+func (m *AdminModule) RegisterRoutes(router *gin.Engine) {
+	routes.RegisterAdminRoutes(router, m.controller, m.auth)
+}
+
+// OpenAPIRoutes implements module.Module.
+func (m *AdminModule) OpenAPIRoutes() []openapi.Route {
+	return routes.AdminRouteDocs()
+}
@@ -0,0 +1,59 @@
+package wires
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/middlewares"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/idempotency"
+	"go.uber.org/zap"
+)
+
+// IdempotencyModuleName is the name other modules depend on to use the
+// idempotency middleware.
+const IdempotencyModuleName = "idempotency"
+
+// IdempotencyModule saves the responses of the requests sent with an
+// idempotency key, to replay them on retries.
+type IdempotencyModule struct {
+	module.Base
+	repo       idempotency.IdempotencyRepository
+	middleware gin.HandlerFunc
+	logger     *zap.Logger
+}
+
+// NewIdempotencyModule creates a new instance of IdempotencyModule saving the
+// records with the given repository.
+func NewIdempotencyModule(repo idempotency.IdempotencyRepository) *IdempotencyModule {
+	return &IdempotencyModule{repo: repo}
+}
+
+// Name implements module.Module.
+func (m *IdempotencyModule) Name() string {
+	return IdempotencyModuleName
+}
+
+// Init implements module.Module.
+func (m *IdempotencyModule) Init(deps module.Deps) error {
+	m.middleware = middlewares.Idempotency(m.repo, func() time.Duration {
+		return deps.Config().Idempotency.TTL
+	})
+	m.logger = deps.Logger.Module(m.Name())
+	return nil
+}
+
+// Start implements module.Module. The application still starts when the indexes
+// cannot be created, expired records are then kept.
+func (m *IdempotencyModule) Start(ctx context.Context) error {
+	if err := m.repo.EnsureIndexes(ctx); err != nil {
+		m.logger.Error("create idempotency indexes fail", zap.Error(err))
+	}
+	return nil
+}
+
+// Middleware returns the middleware guarding routes against retried requests.
+func (m *IdempotencyModule) Middleware() gin.HandlerFunc {
+	return m.middleware
+}
@@ -0,0 +1,69 @@
+package wires
+
+import (
+	"context"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/module"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/jobs"
+	"go.uber.org/zap"
+)
+
+// JobsModuleName is the name other modules depend on to run background jobs.
+const JobsModuleName = "jobs"
+
+// JobsModule runs the background jobs of the other modules, which register their
+// handlers on its queue when initialized.
+type JobsModule struct {
+	module.Base
+	store  jobs.Store
+	queue  *jobs.Queue
+	logger *zap.Logger
+}
+
+// NewJobsModule creates a new instance of JobsModule keeping the jobs in the
+// given store.
+func NewJobsModule(store jobs.Store) *JobsModule {
+	return &JobsModule{store: store}
+}
+
+// Name implements module.Module.
+func (m *JobsModule) Name() string {
+	return JobsModuleName
+}
+
+// Init implements module.Module.
+func (m *JobsModule) Init(deps module.Deps) error {
+	cfg := deps.Config().Jobs
+	m.logger = deps.Logger.Module(m.Name())
+	m.queue = jobs.NewQueue(
+		m.store,
+		jobs.WithConcurrency(cfg.Concurrency),
+		jobs.WithPollInterval(cfg.PollInterval),
+		jobs.WithVisibilityTimeout(cfg.VisibilityTimeout),
+		jobs.WithMaxAttempts(cfg.MaxAttempts),
+		jobs.WithBackoff(cfg.RetryBackoff, cfg.MaxRetryBackoff),
+		jobs.WithLogger(m.logger),
+	)
+	return nil
+}
+
+// Start implements module.Module. The application still starts when the indexes
+// cannot be created, the jobs are then claimed more slowly.
+func (m *JobsModule) Start(ctx context.Context) error {
+	if err := m.store.EnsureIndexes(ctx); err != nil {
+		m.logger.Error("create jobs indexes fail", zap.Error(err))
+	}
+	m.queue.Start()
+	return nil
+}
+
+// Stop implements module.Module. The running jobs are given until ctx is done to
+// complete, then interrupted and queued again.
+func (m *JobsModule) Stop(ctx context.Context) error {
+	return m.queue.Stop(ctx)
+}
+
+// Queue returns the queue the jobs are enqueued on and handled by.
+func (m *JobsModule) Queue() *jobs.Queue {
+	return m.queue
+}
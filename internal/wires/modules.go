@@ -0,0 +1,20 @@
+package wires
+
+import (
+	"github.com/hainguyen27798/gin-boilerplate/internal/module"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/idempotency"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/jobs"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Modules returns the feature modules of the application, backed by the given
+// database, to be registered in the module registry. New modules are added here.
+func Modules(db *mongo.Database) []module.Module {
+	return []module.Module{
+		NewJobsModule(jobs.NewMongoStore(db)),
+		NewIdempotencyModule(idempotency.NewIdempotencyRepository(db)),
+		NewUserModule(users.NewUserRepository(db), users.NewImportJobRepository(db)),
+		NewAdminModule(),
+	}
+}
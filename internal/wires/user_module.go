@@ -0,0 +1,86 @@
+package wires
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/middlewares"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
+	"github.com/hainguyen27798/gin-boilerplate/internal/routes"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/openapi"
+	"go.uber.org/zap"
+)
+
+// UserModule manages the users of the application.
+type UserModule struct {
+	module.Base
+	repo       users.UserRepository
+	importRepo users.ImportJobRepository
+	imports    users.UserImportService
+	controller *users.UserController
+	idempotent gin.HandlerFunc
+	staff      gin.HandlerFunc
+	logger     *zap.Logger
+}
+
+// NewUserModule creates a new instance of UserModule keeping the users and the
+// jobs importing them with the given repositories.
+func NewUserModule(repo users.UserRepository, importRepo users.ImportJobRepository) *UserModule {
+	return &UserModule{repo: repo, importRepo: importRepo}
+}
+
+// Name implements module.Module.
+func (m *UserModule) Name() string {
+	return "users"
+}
+
+// Dependencies implements module.Module.
+func (m *UserModule) Dependencies() []string {
+	return []string{IdempotencyModuleName, JobsModuleName}
+}
+
+// Init implements module.Module.
+func (m *UserModule) Init(deps module.Deps) error {
+	idempotencyModule, ok := deps.Lookup(IdempotencyModuleName).(*IdempotencyModule)
+	if !ok {
+		return fmt.Errorf("module %q is not an idempotency module", IdempotencyModuleName)
+	}
+	m.logger = deps.Logger.Module(m.Name())
+	m.idempotent = idempotencyModule.Middleware()
+	jobsModule, ok := deps.Lookup(JobsModuleName).(*JobsModule)
+	if !ok {
+		return fmt.Errorf("module %q is not a jobs module", JobsModuleName)
+	}
+	m.staff = middlewares.AdminAuth(func() string { return deps.Config().Staff.Token })
+	m.imports = users.NewUserImportService(
+		m.repo, m.importRepo, jobsModule.Queue(), deps.Validator,
+	)
+	m.controller = InitializeUserModule(m.repo, m.imports, deps.Validator, m.logger)
+	return nil
+}
+
+// Start implements module.Module. The application still starts when the indexes
+// cannot be created: the emails only differing by their case are then not
+// refused, the searches are slower and the staged rows of the imports do not
+// expire, until the cause logged is fixed and the application restarted.
+func (m *UserModule) Start(ctx context.Context) error {
+	if err := m.importRepo.EnsureIndexes(ctx); err != nil {
+		m.logger.Error("create user import indexes fail", zap.Error(err))
+	}
+	if err := m.repo.EnsureIndexes(ctx); err != nil {
+		m.logger.Error("create user indexes fail", zap.Error(err))
+	}
+	return nil
+}
+
+// RegisterRoutes implements module.Module.
+func (m *UserModule) RegisterRoutes(router *gin.Engine) {
+	routes.RegisterUserRoutes(router, m.controller, m.idempotent, m.staff)
+}
+
+// OpenAPIRoutes implements module.Module.
+func (m *UserModule) OpenAPIRoutes() []openapi.Route {
+	return routes.UserRouteDocs()
+}
@@ -4,15 +4,20 @@
 package wires
 
 import (
+	"github.com/go-playground/validator/v10"
 	"github.com/google/wire"
 	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
-	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.uber.org/zap"
 )
 
 // InitializeUserModule sets up the UserController with its dependencies.
-func InitializeUserModule(db *mongo.Database) *users.UserController {
+func InitializeUserModule(
+	repo users.UserRepository,
+	imports users.UserImportService,
+	validate *validator.Validate,
+	logger *zap.Logger,
+) *users.UserController {
 	wire.Build(
-		users.NewUserRepository,
 		users.NewUserService,
 		users.NewUserController,
 	)
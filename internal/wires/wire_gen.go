@@ -7,16 +7,16 @@
 package wires
 
 import (
+	"github.com/go-playground/validator/v10"
 	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
-	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.uber.org/zap"
 )
 
 // Injectors from user_wire.go:
 
 // InitializeUserModule sets up the UserController with its dependencies.
-func InitializeUserModule(db *mongo.Database) *users.UserController {
-	userRepository := users.NewUserRepository(db)
-	userService := users.NewUserService(userRepository)
-	userController := users.NewUserController(userService)
+func InitializeUserModule(repo users.UserRepository, imports users.UserImportService, validate *validator.Validate, logger *zap.Logger) *users.UserController {
+	userService := users.NewUserService(repo, logger)
+	userController := users.NewUserController(userService, imports, validate)
 	return userController
 }
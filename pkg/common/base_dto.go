@@ -1,18 +1,17 @@
 package common
 
 import (
-	"fmt"
 	"time"
 
-	"github.com/hainguyen27798/gin-boilerplate/global"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 // BaseDto is a basic data transfer object (DTO) that contains common fields
 // for entities in the application, such as an ID, creation timestamp, and
-// modification timestamp.
+// modification timestamp. The bson tag names the model field the ID comes from,
+// for the sparse fieldsets to project it.
 type BaseDto struct {
-	ID        string    `json:"id"`
+	ID        string    `json:"id" bson:"_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -32,12 +31,3 @@ func ToBson(dto interface{}) (doc *bson.D, err error) {
 
 	return doc, err
 }
-
-// ValidateStruct validates the given struct against the global validator. If the
-// global validator is not initialized, it returns an error.
-func ValidateStruct(s interface{}) error {
-	if global.Validator == nil {
-		return fmt.Errorf("global.Validator is not initialized")
-	}
-	return global.Validator.Struct(s)
-}
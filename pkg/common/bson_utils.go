@@ -1,9 +1,36 @@
 package common
 
-import "go.mongodb.org/mongo-driver/v2/bson"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
 
 // IsValidObjectID checks if the given string is a valid BSON ObjectID.
 func IsValidObjectID(id string) bool {
 	_, err := bson.ObjectIDFromHex(id)
 	return err == nil
 }
+
+// WithUpdatedAt adds the "updated_at" field to the "$set" operator of an update
+// payload, creating the operator when missing. A document must not contain the
+// same operator twice.
+func WithUpdatedAt(payload bson.D, now time.Time) bson.D {
+	updatedAt := bson.E{Key: "updated_at", Value: now}
+	for i, elem := range payload {
+		if elem.Key != "$set" {
+			continue
+		}
+		switch set := elem.Value.(type) {
+		case bson.D:
+			payload[i].Value = append(set, updatedAt)
+			return payload
+		case *bson.D:
+			if set != nil {
+				payload[i].Value = append(*set, updatedAt)
+				return payload
+			}
+		}
+	}
+	return append(payload, bson.E{Key: "$set", Value: bson.D{updatedAt}})
+}
@@ -0,0 +1,61 @@
+package common
+
+// Sizes of the pages, when none is requested and at most.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// PageQuery is the page requested by the page and limit query parameters, pages
+// being numbered from 1.
+type PageQuery struct {
+	Page  int `form:"page" json:"page" validate:"omitempty,gte=1"`
+	Limit int `form:"limit" json:"limit" validate:"omitempty,gte=1,lte=100"`
+}
+
+// Number returns the number of the requested page, the first one by default.
+func (q PageQuery) Number() int {
+	if q.Page < 1 {
+		return 1
+	}
+	return q.Page
+}
+
+// Size returns the size of the requested page, DefaultPageSize by default and
+// MaxPageSize at most.
+func (q PageQuery) Size() int {
+	switch {
+	case q.Limit < 1:
+		return DefaultPageSize
+	case q.Limit > MaxPageSize:
+		return MaxPageSize
+	default:
+		return q.Limit
+	}
+}
+
+// Skip returns the number of items before the requested page.
+func (q PageQuery) Skip() int64 {
+	return int64(q.Number()-1) * int64(q.Size())
+}
+
+// Page is a page of items, along with the total number of items.
+type Page[T any] struct {
+	Items []T   `json:"items"`
+	Page  int   `json:"page"`
+	Limit int   `json:"limit"`
+	Total int64 `json:"total"`
+}
+
+// NewPage returns the page of items answering the query.
+func NewPage[T any](items []T, query PageQuery, total int64) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return &Page[T]{
+		Items: items,
+		Page:  query.Number(),
+		Limit: query.Size(),
+		Total: total,
+	}
+}
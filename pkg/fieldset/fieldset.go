@@ -0,0 +1,275 @@
+// Package fieldset implements sparse fieldsets: the selection of the fields of a
+// DTO by their JSON path, as sent in the fields query parameter, used to filter
+// the responses and to project the documents read from MongoDB.
+package fieldset
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Param is the query parameter carrying the selected fields.
+const Param = "fields"
+
+// Set is a selection of fields by their JSON name. A field maps to nil when it is
+// selected as a whole, and to the selection of its own fields otherwise. A nil Set
+// selects every field.
+type Set map[string]Set
+
+// Parse parses a comma-separated list of JSON paths, nested fields being separated
+// by dots, e.g. "id,first_name,address.city". An empty list selects every field.
+func Parse(fields string) (Set, error) {
+	if strings.TrimSpace(fields) == "" {
+		return nil, nil
+	}
+
+	set := Set{}
+	for _, path := range strings.Split(fields, ",") {
+		path = strings.TrimSpace(path)
+		names := strings.Split(path, ".")
+		if slices.Contains(names, "") {
+			return nil, fmt.Errorf("invalid field %q", path)
+		}
+		set.add(names)
+	}
+	return set, nil
+}
+
+// add selects the field at the path of names, a field selected as a whole taking
+// precedence over the selection of its own fields.
+func (s Set) add(names []string) {
+	sub, ok := s[names[0]]
+	if len(names) == 1 {
+		s[names[0]] = nil
+		return
+	}
+	if ok && sub == nil {
+		return
+	}
+	if sub == nil {
+		sub = Set{}
+		s[names[0]] = sub
+	}
+	sub.add(names[1:])
+}
+
+// Validate checks that the selected fields are fields of the JSON encoding of t.
+// Fields hidden from the encoding, such as json:"-" ones, are unknown.
+func (s Set) Validate(t reflect.Type) error {
+	return s.validate(t, "")
+}
+
+func (s Set) validate(t reflect.Type, prefix string) error {
+	t = elem(t)
+	if t.Kind() == reflect.Map {
+		return nil
+	}
+	if t.Kind() != reflect.Struct || isLeaf(t) {
+		return fmt.Errorf("field %q has no fields", strings.TrimSuffix(prefix, "."))
+	}
+
+	fields := fieldsOf(t)
+	for _, name := range slices.Sorted(maps.Keys(s)) {
+		f, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown field %q", prefix+name)
+		}
+		if sub := s[name]; sub != nil {
+			if err := sub.validate(f.typ, prefix+name+"."); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Filter returns the selected fields of v, structs becoming maps of their JSON
+// names. Slices and arrays are filtered element by element.
+func (s Set) Filter(v any) any {
+	if s == nil {
+		return v
+	}
+	return s.filter(reflect.ValueOf(v))
+}
+
+func (s Set) filter(v reflect.Value) any {
+	if s == nil {
+		return v.Interface()
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]any, v.Len())
+		for i := range v.Len() {
+			items[i] = s.filter(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		filtered := map[string]any{}
+		for name, sub := range s {
+			key := reflect.ValueOf(name).Convert(v.Type().Key())
+			if value := v.MapIndex(key); value.IsValid() {
+				filtered[name] = sub.filter(value)
+			}
+		}
+		return filtered
+	case reflect.Struct:
+		if isLeaf(v.Type()) {
+			return v.Interface()
+		}
+		filtered := map[string]any{}
+		for name, f := range fieldsOf(v.Type()) {
+			sub, ok := s[name]
+			if !ok {
+				continue
+			}
+			value, err := v.FieldByIndexErr(f.index)
+			if err != nil || (f.omitEmpty && value.IsZero()) {
+				continue
+			}
+			filtered[name] = sub.filter(value)
+		}
+		return filtered
+	default:
+		return v.Interface()
+	}
+}
+
+// Projection returns the MongoDB projection reading the selected fields of t. The
+// fields are named by their bson tag, or by their JSON name when they have none,
+// so the DTOs name the model fields they come from. A nil Set projects nothing.
+func (s Set) Projection(t reflect.Type) bson.D {
+	if s == nil {
+		return nil
+	}
+	projection := bson.D{}
+	s.project(&projection, elem(t), "")
+	return projection
+}
+
+func (s Set) project(projection *bson.D, t reflect.Type, prefix string) {
+	fields := fieldsOf(t)
+	for _, name := range slices.Sorted(maps.Keys(s)) {
+		f, ok := fields[name]
+		if !ok {
+			continue
+		}
+		sub, fieldType := s[name], elem(f.typ)
+		if sub != nil && fieldType.Kind() == reflect.Struct && !isLeaf(fieldType) {
+			sub.project(projection, fieldType, prefix+f.bsonName+".")
+			continue
+		}
+		*projection = append(*projection, bson.E{Key: prefix + f.bsonName, Value: 1})
+	}
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the selection, for the repositories to
+// read only the selected fields.
+func NewContext(ctx context.Context, s Set) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// FromContext returns the selection carried by ctx, nil when there is none.
+func FromContext(ctx context.Context) Set {
+	s, _ := ctx.Value(contextKey{}).(Set)
+	return s
+}
+
+// field is a field of the JSON encoding of a struct.
+type field struct {
+	index     []int
+	typ       reflect.Type
+	bsonName  string
+	omitEmpty bool
+}
+
+// fieldsOf returns the fields of the JSON encoding of the struct t by name, with
+// the fields of its embedded structs inlined the way encoding/json does.
+func fieldsOf(t reflect.Type) map[string]field {
+	fields := map[string]field{}
+	addFields(fields, t, nil)
+	return fields
+}
+
+func addFields(fields map[string]field, t reflect.Type, index []int) {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+
+		fieldIndex := append(slices.Clone(index), i)
+		if sf.Anonymous && name == "" && elem(sf.Type).Kind() == reflect.Struct {
+			addFields(fields, elem(sf.Type), fieldIndex)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		// Fields of the outer struct hide the embedded ones.
+		if _, ok := fields[name]; ok && len(index) > 0 {
+			continue
+		}
+
+		bsonName, _, _ := strings.Cut(sf.Tag.Get("bson"), ",")
+		if bsonName == "" || bsonName == "-" {
+			bsonName = name
+		}
+		fields[name] = field{
+			index:     fieldIndex,
+			typ:       sf.Type,
+			bsonName:  bsonName,
+			omitEmpty: slices.Contains(strings.Split(opts, ","), "omitempty"),
+		}
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// isLeaf reports whether the struct t is encoded as a value, such as time.Time.
+func isLeaf(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t.Implements(jsonMarshalerType) || pt.Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || pt.Implements(textMarshalerType)
+}
+
+// elem returns the type of the values of t, dereferencing pointers, slices and arrays.
+func elem(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
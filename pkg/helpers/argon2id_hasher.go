@@ -0,0 +1,137 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2idPrefix is the PHC identifier of argon2id hashes.
+const argon2idPrefix = "$argon2id$"
+
+// DefaultArgon2idSettings holds the argon2id parameters used when none are configured.
+var DefaultArgon2idSettings = setting.Argon2idSettings{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// errInvalidArgon2idHash is returned when a hash is not a valid PHC encoded argon2id hash.
+var errInvalidArgon2idHash = errors.New("invalid argon2id hash")
+
+// Argon2idHasher hashes passwords with argon2id, encoding them in the PHC string format:
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+type Argon2idHasher struct {
+	params setting.Argon2idSettings
+}
+
+// NewArgon2idHasher creates a new instance of Argon2idHasher.
+// Zero parameters are replaced by their default value.
+func NewArgon2idHasher(params setting.Argon2idSettings) *Argon2idHasher {
+	if params.Memory == 0 {
+		params.Memory = DefaultArgon2idSettings.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = DefaultArgon2idSettings.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = DefaultArgon2idSettings.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = DefaultArgon2idSettings.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = DefaultArgon2idSettings.KeyLength
+	}
+	return &Argon2idHasher{params: params}
+}
+
+// Hash implements PasswordHasher.
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey(
+		[]byte(password), salt,
+		h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength,
+	)
+
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version,
+		h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify implements PasswordHasher. A hash made with other parameters than the
+// configured ones needs a rehash.
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, bool) {
+	params, salt, key, err := decodeArgon2idHash(encoded)
+	if err != nil {
+		return false, false
+	}
+
+	otherKey := argon2.IDKey(
+		[]byte(password), salt,
+		params.Iterations, params.Memory, params.Parallelism, params.KeyLength,
+	)
+	if subtle.ConstantTimeCompare(key, otherKey) != 1 {
+		return false, false
+	}
+
+	params.SaltLength = uint32(len(salt))
+	return true, params != h.params
+}
+
+// Supports implements PasswordHasher.
+func (h *Argon2idHasher) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2idPrefix)
+}
+
+// decodeArgon2idHash parses a PHC encoded argon2id hash.
+func decodeArgon2idHash(encoded string) (setting.Argon2idSettings, []byte, []byte, error) {
+	var params setting.Argon2idSettings
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, errInvalidArgon2idHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errInvalidArgon2idHash
+	}
+
+	_, err := fmt.Sscanf(
+		parts[3], "m=%d,t=%d,p=%d",
+		&params.Memory, &params.Iterations, &params.Parallelism,
+	)
+	if err != nil {
+		return params, nil, nil, errInvalidArgon2idHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errInvalidArgon2idHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errInvalidArgon2idHash
+	}
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptHasher hashes passwords with bcrypt. bcrypt only supports passwords
+// of up to 72 bytes, longer passwords are rejected instead of being truncated.
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher creates a new instance of BcryptHasher. A zero cost is
+// replaced by bcrypt.DefaultCost.
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+// Hash implements PasswordHasher.
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPassword), nil
+}
+
+// Verify implements PasswordHasher. A hash made with another cost than the
+// configured one needs a rehash.
+func (h *BcryptHasher) Verify(password, encoded string) (bool, bool) {
+	if err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)); err != nil {
+		return false, false
+	}
+
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return true, err != nil || cost != h.cost
+}
+
+// Supports implements PasswordHasher.
+func (h *BcryptHasher) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
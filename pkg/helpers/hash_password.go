@@ -1,26 +1,66 @@
 package helpers
 
 import (
+	"errors"
 	"log"
+	"sync"
 
 	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword securely hashes a password using bcrypt
+// passwordHasher is the hasher used by HashPassword and the verify functions,
+// argon2id with its default parameters until another one is set.
+var passwordHasher = MustValue(NewPasswordHasher(setting.PasswordHashSettings{}))
+
+// dummyHash is a hash made by passwordHasher, verified in place of the hash of
+// unknown users so that their lookups take as long as the ones of known users.
+var dummyHash struct {
+	sync.Mutex
+	hash string
+}
+
+// SetPasswordHasher replaces the hasher used by HashPassword and the verify functions.
+func SetPasswordHasher(hasher PasswordHasher) {
+	passwordHasher = hasher
+	dummyHash.Lock()
+	dummyHash.hash = ""
+	dummyHash.Unlock()
+}
+
+// DummyPasswordHash returns a hash of a fixed password made by the configured
+// hasher, to verify passwords against when there is no hash to compare with.
+func DummyPasswordHash() string {
+	dummyHash.Lock()
+	defer dummyHash.Unlock()
+	if dummyHash.hash == "" {
+		dummyHash.hash, _ = passwordHasher.Hash("dummy password, never matched")
+	}
+	return dummyHash.hash
+}
+
+// HashPassword securely hashes a password with the configured hasher
 func HashPassword(password string) (string, *response.Error) {
-	// Use bcrypt with default cost (12)
-	// Higher cost means more computational time but more secure
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := passwordHasher.Hash(password)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", response.NewError(response.ErrValidation, err)
+	}
 	if err != nil {
 		log.Println(err)
 		return "", response.NewError(response.ErrInternalError, nil)
 	}
-	return string(hashedPassword), nil
+	return hashedPassword, nil
 }
 
 // CheckPasswordHash compares a plain text password with its hash
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	ok, _ := passwordHasher.Verify(password, hash)
+	return ok
+}
+
+// VerifyPassword compares a plain text password with its hash and reports whether
+// the hash should be upgraded, e.g. a legacy bcrypt hash once argon2id is configured.
+func VerifyPassword(password, hash string) (ok bool, needsRehash bool) {
+	return passwordHasher.Verify(password, hash)
 }
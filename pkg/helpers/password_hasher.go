@@ -0,0 +1,118 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+)
+
+// Supported password hashing algorithms.
+const (
+	Argon2idAlgorithm = "argon2id"
+	BcryptAlgorithm   = "bcrypt"
+)
+
+// PasswordHasher hashes passwords and verifies them against encoded hashes.
+type PasswordHasher interface {
+	// Hash returns the encoded hash of the password.
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the encoded hash, and whether
+	// the hash should be replaced because it uses outdated parameters.
+	Verify(password, encoded string) (ok bool, needsRehash bool)
+	// Supports reports whether the encoded hash was made by this hasher's algorithm.
+	Supports(encoded string) bool
+}
+
+// configuredHasher hashes passwords with the configured algorithm and verifies
+// hashes of every supported algorithm, flagging hashes of other algorithms for a
+// rehash. When a pepper is set, passwords are mixed with it before hashing.
+type configuredHasher struct {
+	primary PasswordHasher
+	hashers []PasswordHasher
+	pepper  []byte
+}
+
+// NewPasswordHasher creates the PasswordHasher described by the settings,
+// argon2id being the default algorithm.
+func NewPasswordHasher(settings setting.PasswordHashSettings) (PasswordHasher, error) {
+	pepper := settings.Pepper
+	if settings.PepperFile != "" {
+		content, err := os.ReadFile(settings.PepperFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pepper file: %w", err)
+		}
+		pepper = strings.TrimSpace(string(content))
+	}
+
+	argon2id := NewArgon2idHasher(settings.Argon2id)
+	bcryptHasher := NewBcryptHasher(settings.BcryptCost)
+
+	h := &configuredHasher{}
+	if pepper != "" {
+		h.pepper = []byte(pepper)
+	}
+
+	switch settings.Algorithm {
+	case "", Argon2idAlgorithm:
+		h.primary = argon2id
+		h.hashers = []PasswordHasher{argon2id, bcryptHasher}
+	case BcryptAlgorithm:
+		h.primary = bcryptHasher
+		h.hashers = []PasswordHasher{bcryptHasher, argon2id}
+	default:
+		return nil, fmt.Errorf("unsupported password hashing algorithm %q", settings.Algorithm)
+	}
+
+	return h, nil
+}
+
+// Hash implements PasswordHasher.
+func (h *configuredHasher) Hash(password string) (string, error) {
+	return h.primary.Hash(h.season(password))
+}
+
+// Verify implements PasswordHasher.
+func (h *configuredHasher) Verify(password, encoded string) (bool, bool) {
+	for _, hasher := range h.hashers {
+		if !hasher.Supports(encoded) {
+			continue
+		}
+
+		ok, needsRehash := hasher.Verify(h.season(password), encoded)
+		if !ok && h.pepper != nil {
+			// The hash may predate the pepper, it is replaced once verified.
+			if ok, _ = hasher.Verify(password, encoded); ok {
+				needsRehash = true
+			}
+		}
+		return ok, ok && (needsRehash || hasher != h.primary)
+	}
+	return false, false
+}
+
+// Supports implements PasswordHasher.
+func (h *configuredHasher) Supports(encoded string) bool {
+	for _, hasher := range h.hashers {
+		if hasher.Supports(encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+// season mixes the pepper into the password with HMAC-SHA256. The hex encoded
+// result also keeps peppered passwords within bcrypt's 72 bytes limit.
+func (h *configuredHasher) season(password string) string {
+	if h.pepper == nil {
+		return password
+	}
+
+	mac := hmac.New(sha256.New, h.pepper)
+	mac.Write([]byte(password))
+	return hex.EncodeToString(mac.Sum(nil))
+}
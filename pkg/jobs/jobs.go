@@ -0,0 +1,128 @@
+// Package jobs runs deferred work in the background. Jobs are stored in a queue,
+// claimed atomically by the workers for a visibility timeout, retried with an
+// exponential backoff when they fail, and moved to the dead letters once out of
+// attempts.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Status values of a job.
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDead    = "dead"
+)
+
+// ErrNoJob is returned by Store.Claim when no job is ready to run.
+var ErrNoJob = errors.New("no job ready to run")
+
+// ErrLeaseLost is returned when a job was claimed again by another worker, its
+// visibility timeout having expired.
+var ErrLeaseLost = errors.New("job lease lost")
+
+// abandonedCause is the error of the dead letters of the jobs whose lease expired
+// on their last attempt, their worker having died without recording an outcome.
+const abandonedCause = "the lease expired on the last attempt"
+
+// Job is a unit of deferred work, run by the handler of its type with its
+// payload. A queued job runs once RunAt is past, the jobs with the highest
+// Priority first. A running job is leased to a worker until RunAt, after which
+// another worker may claim it.
+type Job struct {
+	ID          bson.ObjectID `bson:"_id"`
+	Type        string        `bson:"type"`
+	Payload     bson.RawValue `bson:"payload"`
+	Priority    int           `bson:"priority"`
+	Status      string        `bson:"status"`
+	RunAt       time.Time     `bson:"run_at"`
+	Attempts    int           `bson:"attempts"`
+	MaxAttempts int           `bson:"max_attempts"`
+	Lease       string        `bson:"lease,omitempty"`
+	LastError   string        `bson:"last_error,omitempty"`
+	CreatedAt   time.Time     `bson:"created_at"`
+	UpdatedAt   time.Time     `bson:"updated_at"`
+}
+
+// LastAttempt reports whether the running job fails for good if it fails again.
+func (j *Job) LastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
+
+// exhausted reports whether the job just claimed had no attempt left.
+func (j *Job) exhausted() bool {
+	return j.Attempts > j.MaxAttempts
+}
+
+// Store keeps the jobs of a queue. Claims must be atomic: a job is only leased
+// to a single worker at a time.
+type Store interface {
+	// EnsureIndexes creates the indexes claiming the jobs.
+	EnsureIndexes(ctx context.Context) error
+	// Enqueue adds a queued job.
+	Enqueue(ctx context.Context, job *Job) error
+	// Claim leases the next job of the types ready to run at now, the most
+	// urgent first, until the given time. It returns ErrNoJob when there is none.
+	// The jobs whose lease expired on their last attempt are buried instead.
+	Claim(ctx context.Context, types []string, now, until time.Time) (*Job, error)
+	// Extend leases the running job until the given time.
+	Extend(ctx context.Context, job *Job, until time.Time) error
+	// Complete removes the job, once run.
+	Complete(ctx context.Context, job *Job) error
+	// Retry queues the failed job again, to run at the given time.
+	Retry(ctx context.Context, job *Job, runAt time.Time, cause string) error
+	// Release queues the interrupted job again, its attempt not counting.
+	Release(ctx context.Context, job *Job) error
+	// Bury moves the job out of the queue into the dead letters.
+	Bury(ctx context.Context, job *Job, cause string) error
+	// DeadJobs returns the most recent dead letters, at most limit.
+	DeadJobs(ctx context.Context, limit int) ([]Job, error)
+}
+
+// Type identifies the jobs run by a handler, whose payloads are of type T. The
+// payloads are stored as BSON.
+type Type[T any] struct {
+	name string
+}
+
+// NewType returns the type of jobs with the given name, unique in a queue.
+func NewType[T any](name string) Type[T] {
+	return Type[T]{name: name}
+}
+
+// Name returns the name of the type.
+func (t Type[T]) Name() string {
+	return t.name
+}
+
+// permanentError is an error no retry would fix.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks an error returned by a handler as one that no retry would fix,
+// the job goes to the dead letters at once.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+type contextKey struct{}
+
+// FromContext returns the job run by the handler given ctx.
+func FromContext(ctx context.Context) *Job {
+	job, _ := ctx.Value(contextKey{}).(*Job)
+	return job
+}
@@ -0,0 +1,164 @@
+package jobs
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// MemoryStore is an in-process Store, suitable for tests and single instance
+// deployments whose jobs may be lost on restart.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[bson.ObjectID]*Job
+	dead []Job
+	now  func() time.Time
+}
+
+// NewMemoryStore creates a new instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		jobs: make(map[bson.ObjectID]*Job),
+		now:  time.Now,
+	}
+}
+
+// EnsureIndexes implements Store.
+func (s *MemoryStore) EnsureIndexes(_ context.Context) error {
+	return nil
+}
+
+// Enqueue implements Store.
+func (s *MemoryStore) Enqueue(_ context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *job
+	s.jobs[job.ID] = &stored
+	return nil
+}
+
+// Claim implements Store.
+func (s *MemoryStore) Claim(
+	_ context.Context,
+	types []string,
+	now, until time.Time,
+) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		var next *Job
+		for _, job := range s.jobs {
+			if !slices.Contains(types, job.Type) || job.RunAt.After(now) {
+				continue
+			}
+			if next == nil || before(job, next) {
+				next = job
+			}
+		}
+		if next == nil {
+			return nil, ErrNoJob
+		}
+
+		next.UpdatedAt = s.now().UTC()
+		if next.Attempts >= next.MaxAttempts {
+			s.bury(next, abandonedCause)
+			continue
+		}
+		next.Status = StatusRunning
+		next.Lease = bson.NewObjectID().Hex()
+		next.RunAt = until
+		next.Attempts++
+		claimed := *next
+		return &claimed, nil
+	}
+}
+
+// before reports whether job a is more urgent than job b.
+func before(a, b *Job) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if !a.RunAt.Equal(b.RunAt) {
+		return a.RunAt.Before(b.RunAt)
+	}
+	return a.ID.Hex() < b.ID.Hex()
+}
+
+// Extend implements Store.
+func (s *MemoryStore) Extend(_ context.Context, job *Job, until time.Time) error {
+	return s.update(job, func(stored *Job) {
+		stored.RunAt = until
+	})
+}
+
+// Complete implements Store.
+func (s *MemoryStore) Complete(_ context.Context, job *Job) error {
+	return s.update(job, func(stored *Job) {
+		delete(s.jobs, stored.ID)
+	})
+}
+
+// Retry implements Store.
+func (s *MemoryStore) Retry(_ context.Context, job *Job, runAt time.Time, cause string) error {
+	return s.update(job, func(stored *Job) {
+		stored.Status = StatusQueued
+		stored.RunAt = runAt
+		stored.Lease = ""
+		stored.LastError = cause
+	})
+}
+
+// Release implements Store.
+func (s *MemoryStore) Release(_ context.Context, job *Job) error {
+	return s.update(job, func(stored *Job) {
+		stored.Status = StatusQueued
+		stored.RunAt = s.now().UTC()
+		stored.Lease = ""
+		stored.Attempts--
+	})
+}
+
+// Bury implements Store.
+func (s *MemoryStore) Bury(_ context.Context, job *Job, cause string) error {
+	return s.update(job, func(stored *Job) {
+		s.bury(stored, cause)
+	})
+}
+
+// bury moves the stored job into the dead letters, under the lock.
+func (s *MemoryStore) bury(stored *Job, cause string) {
+	delete(s.jobs, stored.ID)
+	stored.Status = StatusDead
+	stored.Lease = ""
+	stored.LastError = cause
+	s.dead = append(s.dead, *stored)
+}
+
+// DeadJobs implements Store.
+func (s *MemoryStore) DeadJobs(_ context.Context, limit int) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dead := slices.Clone(s.dead)
+	slices.Reverse(dead)
+	return dead[:min(limit, len(dead))], nil
+}
+
+// update applies fn to the stored job while leased by job, under the lock.
+func (s *MemoryStore) update(job *Job, fn func(stored *Job)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.jobs[job.ID]
+	if !ok || stored.Lease != job.Lease {
+		return ErrLeaseLost
+	}
+	stored.UpdatedAt = s.now().UTC()
+	fn(stored)
+	return nil
+}
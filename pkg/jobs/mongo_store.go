@@ -0,0 +1,213 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MongoStore is a Store backed by MongoDB, so the jobs survive restarts and are
+// shared between all instances of the application.
+type MongoStore struct {
+	jobs *mongo.Collection
+	dead *mongo.Collection
+}
+
+// NewMongoStore creates a new instance of MongoStore, keeping the jobs in the
+// "jobs" collection and the dead letters in "dead_jobs".
+func NewMongoStore(db *mongo.Database) *MongoStore {
+	return &MongoStore{
+		jobs: db.Collection("jobs"),
+		dead: db.Collection("dead_jobs"),
+	}
+}
+
+// EnsureIndexes implements Store.
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.jobs.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "type", Value: 1},
+			{Key: "priority", Value: -1},
+			{Key: "run_at", Value: 1},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the jobs index: %w", err)
+	}
+
+	_, err = s.dead.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "updated_at", Value: -1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the dead jobs index: %w", err)
+	}
+	return nil
+}
+
+// Enqueue implements Store.
+func (s *MongoStore) Enqueue(ctx context.Context, job *Job) error {
+	_, err := s.jobs.InsertOne(ctx, job)
+	return err
+}
+
+// Claim implements Store. The job is leased by a single atomic update, so two
+// workers never claim the same job.
+func (s *MongoStore) Claim(
+	ctx context.Context,
+	types []string,
+	now, until time.Time,
+) (*Job, error) {
+	for {
+		job, err := s.claim(ctx, types, now, until)
+		if err != nil || !job.exhausted() {
+			return job, err
+		}
+
+		// The attempt of the claim does not count.
+		job.Attempts--
+		if err := s.Bury(ctx, job, abandonedCause); err != nil && !errors.Is(err, ErrLeaseLost) {
+			return nil, err
+		}
+	}
+}
+
+// claim leases the next job ready to run, counting an attempt.
+func (s *MongoStore) claim(
+	ctx context.Context,
+	types []string,
+	now, until time.Time,
+) (*Job, error) {
+	var job Job
+	err := s.jobs.FindOneAndUpdate(
+		ctx,
+		bson.M{"type": bson.M{"$in": types}, "run_at": bson.M{"$lte": now}},
+		bson.D{
+			{Key: "$set", Value: bson.M{
+				"status":     StatusRunning,
+				"lease":      bson.NewObjectID().Hex(),
+				"run_at":     until,
+				"updated_at": now,
+			}},
+			{Key: "$inc", Value: bson.M{"attempts": 1}},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "run_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&job)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNoJob
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Extend implements Store.
+func (s *MongoStore) Extend(ctx context.Context, job *Job, until time.Time) error {
+	return s.update(ctx, job, bson.D{{Key: "$set", Value: bson.M{
+		"run_at":     until,
+		"updated_at": time.Now(),
+	}}})
+}
+
+// Complete implements Store.
+func (s *MongoStore) Complete(ctx context.Context, job *Job) error {
+	result, err := s.jobs.DeleteOne(ctx, leased(job))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Retry implements Store.
+func (s *MongoStore) Retry(ctx context.Context, job *Job, runAt time.Time, cause string) error {
+	return s.update(ctx, job, bson.D{
+		{Key: "$set", Value: bson.M{
+			"status":     StatusQueued,
+			"run_at":     runAt,
+			"last_error": cause,
+			"updated_at": time.Now(),
+		}},
+		{Key: "$unset", Value: bson.M{"lease": ""}},
+	})
+}
+
+// Release implements Store.
+func (s *MongoStore) Release(ctx context.Context, job *Job) error {
+	now := time.Now()
+	return s.update(ctx, job, bson.D{
+		{Key: "$set", Value: bson.M{
+			"status":     StatusQueued,
+			"run_at":     now,
+			"updated_at": now,
+		}},
+		{Key: "$unset", Value: bson.M{"lease": ""}},
+		{Key: "$inc", Value: bson.M{"attempts": -1}},
+	})
+}
+
+// Bury implements Store. The dead letter is inserted before the job is removed,
+// so that no job is lost in between; it is removed again when the lease was lost.
+func (s *MongoStore) Bury(ctx context.Context, job *Job, cause string) error {
+	dead := *job
+	dead.Status = StatusDead
+	dead.Lease = ""
+	dead.LastError = cause
+	dead.UpdatedAt = time.Now()
+	// A duplicate is a dead letter left by a previous attempt to bury the job.
+	if _, err := s.dead.InsertOne(ctx, &dead); err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	err := s.Complete(ctx, job)
+	if errors.Is(err, ErrLeaseLost) {
+		if _, err := s.dead.DeleteOne(ctx, bson.M{"_id": job.ID}); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// DeadJobs implements Store.
+func (s *MongoStore) DeadJobs(ctx context.Context, limit int) ([]Job, error) {
+	cursor, err := s.dead.Find(
+		ctx,
+		bson.M{},
+		options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []Job{}
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// update applies the update to the job while leased by job.
+func (s *MongoStore) update(ctx context.Context, job *Job, update bson.D) error {
+	result, err := s.jobs.UpdateOne(ctx, leased(job), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// leased returns the filter of the job while leased by job.
+func leased(job *Job) bson.M {
+	return bson.M{"_id": job.ID, "lease": job.Lease}
+}
@@ -0,0 +1,391 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.uber.org/zap"
+)
+
+// Defaults of the queues.
+const (
+	DefaultConcurrency       = 4
+	DefaultPollInterval      = time.Second
+	DefaultVisibilityTimeout = 5 * time.Minute
+	DefaultMaxAttempts       = 5
+	DefaultRetryBackoff      = 10 * time.Second
+	DefaultMaxRetryBackoff   = time.Hour
+)
+
+// handler runs a job of a registered type.
+type handler func(ctx context.Context, job *Job) error
+
+// Queue runs the jobs of a Store with the handlers of their type. Concurrency
+// workers claim the jobs, polling the store every PollInterval when it has none
+// ready, or as soon as a job is enqueued through the queue.
+type Queue struct {
+	store        Store
+	logger       *zap.Logger
+	concurrency  int
+	pollInterval time.Duration
+	visibility   time.Duration
+	maxAttempts  int
+	backoff      time.Duration
+	maxBackoff   time.Duration
+
+	mu       sync.Mutex
+	handlers map[string]handler
+	types    []string
+	running  bool
+	stopping chan struct{}
+	cancel   context.CancelFunc
+	wake     chan struct{}
+	workers  sync.WaitGroup
+}
+
+// Option customizes a Queue.
+type Option func(q *Queue)
+
+// WithConcurrency sets the number of jobs run at the same time.
+func WithConcurrency(n int) Option {
+	return func(q *Queue) {
+		q.concurrency = n
+	}
+}
+
+// WithPollInterval sets how long the workers wait for a job when none is ready.
+func WithPollInterval(d time.Duration) Option {
+	return func(q *Queue) {
+		q.pollInterval = d
+	}
+}
+
+// WithVisibilityTimeout sets how long a claimed job is hidden from the other
+// workers. The lease is renewed while the job runs, so the timeout only expires
+// when its worker is gone.
+func WithVisibilityTimeout(d time.Duration) Option {
+	return func(q *Queue) {
+		q.visibility = d
+	}
+}
+
+// WithMaxAttempts sets how many times the jobs run at most, unless enqueued with
+// WithAttempts.
+func WithMaxAttempts(n int) Option {
+	return func(q *Queue) {
+		q.maxAttempts = n
+	}
+}
+
+// WithBackoff sets the delay before the first retry of a failed job, doubled for
+// each following retry up to max.
+func WithBackoff(base, max time.Duration) Option {
+	return func(q *Queue) {
+		q.backoff, q.maxBackoff = base, max
+	}
+}
+
+// WithLogger sets the logger of the failures, nothing is logged by default.
+func WithLogger(logger *zap.Logger) Option {
+	return func(q *Queue) {
+		q.logger = logger
+	}
+}
+
+// NewQueue creates a new Queue running the jobs of the store.
+func NewQueue(store Store, opts ...Option) *Queue {
+	q := &Queue{
+		store:        store,
+		logger:       zap.NewNop(),
+		concurrency:  DefaultConcurrency,
+		pollInterval: DefaultPollInterval,
+		visibility:   DefaultVisibilityTimeout,
+		maxAttempts:  DefaultMaxAttempts,
+		backoff:      DefaultRetryBackoff,
+		maxBackoff:   DefaultMaxRetryBackoff,
+		handlers:     map[string]handler{},
+		wake:         make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Handle registers the handler of the jobs of type t, before the queue starts.
+// The jobs failing with an error are retried, unless the error is Permanent.
+func Handle[T any](q *Queue, t Type[T], fn func(ctx context.Context, payload T) error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.running {
+		panic("jobs: handlers must be registered before the queue starts")
+	}
+	if _, ok := q.handlers[t.name]; ok {
+		panic(fmt.Sprintf("jobs: type %q already has a handler", t.name))
+	}
+
+	q.handlers[t.name] = func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := job.Payload.Unmarshal(&payload); err != nil {
+			return Permanent(fmt.Errorf("decode payload: %w", err))
+		}
+		return fn(ctx, payload)
+	}
+	q.types = append(q.types, t.name)
+}
+
+// EnqueueOption customizes an enqueued job.
+type EnqueueOption func(job *Job)
+
+// WithPriority sets the priority of the job, 0 by default. The jobs with the
+// highest priority run first.
+func WithPriority(priority int) EnqueueOption {
+	return func(job *Job) {
+		job.Priority = priority
+	}
+}
+
+// WithDelay delays the job, to run once d elapsed.
+func WithDelay(d time.Duration) EnqueueOption {
+	return func(job *Job) {
+		job.RunAt = job.RunAt.Add(d)
+	}
+}
+
+// WithRunAt schedules the job, to run once t is past.
+func WithRunAt(t time.Time) EnqueueOption {
+	return func(job *Job) {
+		job.RunAt = t.UTC()
+	}
+}
+
+// WithAttempts sets how many times the job runs at most.
+func WithAttempts(n int) EnqueueOption {
+	return func(job *Job) {
+		job.MaxAttempts = n
+	}
+}
+
+// Enqueue adds a job of type t with the payload to the queue. The job runs
+// right away unless delayed, on any worker of the store.
+func Enqueue[T any](
+	ctx context.Context,
+	q *Queue,
+	t Type[T],
+	payload T,
+	opts ...EnqueueOption,
+) (*Job, error) {
+	typ, data, err := bson.MarshalValue(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode payload: %w", err)
+	}
+
+	now := time.Now().UTC()
+	job := &Job{
+		ID:   bson.NewObjectID(),
+		Type: t.name,
+		// The encoded value may share the buffer of later encodings.
+		Payload:     bson.RawValue{Type: typ, Value: bytes.Clone(data)},
+		Status:      StatusQueued,
+		RunAt:       now,
+		MaxAttempts: q.maxAttempts,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	if err := q.store.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Start starts the workers, which run the jobs of the registered types until
+// the queue stops.
+func (q *Queue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.running {
+		return
+	}
+	q.running = true
+	q.stopping = make(chan struct{})
+
+	var ctx context.Context
+	ctx, q.cancel = context.WithCancel(context.Background())
+	if len(q.types) == 0 {
+		return
+	}
+	for range q.concurrency {
+		q.workers.Add(1)
+		go q.work(ctx)
+	}
+}
+
+// Stop stops claiming jobs and waits for the running ones to complete. When ctx
+// is done first, the running jobs are interrupted and queued again, for them to
+// run once more later.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.running {
+		q.mu.Unlock()
+		return nil
+	}
+	q.running = false
+	close(q.stopping)
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+	defer q.cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work claims and runs jobs until the queue stops.
+func (q *Queue) work(ctx context.Context) {
+	defer q.workers.Done()
+	for {
+		select {
+		case <-q.stopping:
+			return
+		default:
+		}
+
+		now := time.Now().UTC()
+		job, err := q.store.Claim(ctx, q.types, now, now.Add(q.visibility))
+		if err == nil {
+			q.run(ctx, job)
+			continue
+		}
+		if !errors.Is(err, ErrNoJob) && ctx.Err() == nil {
+			q.logger.Error("claim job fail", zap.Error(err))
+		}
+
+		timer := time.NewTimer(q.pollInterval)
+		select {
+		case <-q.stopping:
+			timer.Stop()
+			return
+		case <-q.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// run runs a claimed job, renewing its lease meanwhile, then records its outcome.
+func (q *Queue) run(ctx context.Context, job *Job) {
+	logger := q.logger.With(
+		zap.String("job_id", job.ID.Hex()),
+		zap.String("job_type", job.Type),
+		zap.Int("attempt", job.Attempts),
+	)
+
+	jobCtx, cancel := context.WithCancel(context.WithValue(ctx, contextKey{}, job))
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		q.renew(jobCtx, cancel, job, logger)
+	}()
+	err := q.call(jobCtx, job)
+	cancel()
+	<-renewed
+
+	// The outcome is recorded even when the queue is interrupted.
+	storeCtx := context.WithoutCancel(ctx)
+	var permanent permanentError
+	switch {
+	case err == nil:
+		err = q.store.Complete(storeCtx, job)
+	case ctx.Err() != nil:
+		logger.Warn("job interrupted", zap.Error(err))
+		err = q.store.Release(storeCtx, job)
+	case errors.As(err, &permanent) || job.LastAttempt():
+		logger.Error("job failed, moved to the dead letters", zap.Error(err))
+		err = q.store.Bury(storeCtx, job, err.Error())
+	default:
+		delay := q.retryDelay(job.Attempts)
+		logger.Warn("job failed, retrying", zap.Error(err), zap.Duration("delay", delay))
+		err = q.store.Retry(storeCtx, job, time.Now().UTC().Add(delay), err.Error())
+	}
+	if err != nil {
+		logger.Error("record job outcome fail", zap.Error(err))
+	}
+}
+
+// call runs the handler of the job, a panic failing the job.
+func (q *Queue) call(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	q.mu.Lock()
+	h := q.handlers[job.Type]
+	q.mu.Unlock()
+	return h(ctx, job)
+}
+
+// renew extends the lease of the running job until ctx is done. When another
+// worker claimed the job in the meantime, the job is canceled.
+func (q *Queue) renew(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	job *Job,
+	logger *zap.Logger,
+) {
+	ticker := time.NewTicker(q.visibility / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := q.store.Extend(ctx, job, time.Now().UTC().Add(q.visibility))
+		if errors.Is(err, ErrLeaseLost) {
+			logger.Warn("job lease lost, canceling the job")
+			cancel()
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			logger.Error("extend job lease fail", zap.Error(err))
+		}
+	}
+}
+
+// retryDelay returns the delay before the retry following the given attempt.
+func (q *Queue) retryDelay(attempt int) time.Duration {
+	delay := q.backoff
+	for range attempt - 1 {
+		if delay >= q.maxBackoff/2 {
+			return q.maxBackoff
+		}
+		delay *= 2
+	}
+	return min(delay, q.maxBackoff)
+}
@@ -3,53 +3,243 @@ package logger
 import (
 	"fmt"
 	"os"
+	"sync"
 
+	"github.com/hainguyen27798/gin-boilerplate/pkg/redact"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// Log formats supported by the outputs.
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
 // Zap is a wrapper around zap.Logger
 // Providing additional logging functionality and integration capabilities.
+// Its level, and the level of each module logger, can be changed at runtime.
 type Zap struct {
 	*zap.Logger
+	level zap.AtomicLevel
+
+	// base writes to the outputs, without the level of the logger applied.
+	base     zapcore.Core
+	redactor *redact.Redactor
+
+	mu           sync.RWMutex
+	moduleLevels map[string]zap.AtomicLevel
 }
 
 // NewLogger creates and returns a new instance of
 // the Zap logger configured with the provided settings and application mode.
-func NewLogger(config setting.LoggerSettings, mode setting.AppMode, version string) *Zap {
-	logLevel := config.Level
-	var level zapcore.Level
-	switch logLevel {
+// Every line carries the service name, instance ID, version and commit, and
+// the values of the fields matching the redaction patterns are hidden.
+func NewLogger(config setting.LoggerSettings, mode setting.AppMode, version, commit string) *Zap {
+	z := &Zap{
+		level:        zap.NewAtomicLevelAt(parseLevel(config.Level)),
+		redactor:     redact.New(config.RedactFields),
+		moduleLevels: map[string]zap.AtomicLevel{},
+	}
+	z.SetModuleLevels(config.Modules)
+
+	var base zapcore.Core = &redactCore{
+		Core:     zapcore.NewTee(getCores(config)...),
+		redactor: z.redactor,
+	}
+	if config.Sampling.Enabled {
+		base = zapcore.NewSamplerWithOptions(
+			base,
+			config.Sampling.Tick,
+			config.Sampling.Initial,
+			config.Sampling.Thereafter,
+		)
+	}
+	z.base = base.With([]zapcore.Field{
+		zap.String("service", config.ServiceName),
+		zap.String("instance_id", instanceID(config.InstanceID)),
+		zap.String("version", version),
+		zap.String("commit", commit),
+	})
+
+	z.Logger = z.newLogger(z.level).Named(fmt.Sprintf("[%s-%s]", mode, version))
+	return z
+}
+
+// Module returns the child logger of a module. Its level is the one configured
+// for the module, or the level of the logger when there is none.
+func (z *Zap) Module(name string) *zap.Logger {
+	return z.newLogger(moduleLevel{z: z, name: name}).Named(name)
+}
+
+// Redactor returns the redactor hiding sensitive values from the logs, to be
+// used for data logged as a whole such as documents or query strings.
+func (z *Zap) Redactor() *redact.Redactor {
+	return z.redactor
+}
+
+// SetLevel changes the level of the logger, unknown levels fall back to info.
+func (z *Zap) SetLevel(level string) {
+	z.level.SetLevel(parseLevel(level))
+}
+
+// Level returns the current level of the logger.
+func (z *Zap) Level() zapcore.Level {
+	return z.level.Level()
+}
+
+// SetModuleLevel changes the level of a module logger. An empty level makes the
+// module follow the level of the logger again.
+func (z *Zap) SetModuleLevel(name, level string) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	if level == "" {
+		delete(z.moduleLevels, name)
+		return
+	}
+	if current, ok := z.moduleLevels[name]; ok {
+		current.SetLevel(parseLevel(level))
+		return
+	}
+	z.moduleLevels[name] = zap.NewAtomicLevelAt(parseLevel(level))
+}
+
+// SetModuleLevels replaces the levels of all module loggers.
+func (z *Zap) SetModuleLevels(levels map[string]string) {
+	z.mu.Lock()
+	for name := range z.moduleLevels {
+		if _, ok := levels[name]; !ok {
+			delete(z.moduleLevels, name)
+		}
+	}
+	z.mu.Unlock()
+
+	for name, level := range levels {
+		z.SetModuleLevel(name, level)
+	}
+}
+
+// ModuleLevel returns the current level of a module logger.
+func (z *Zap) ModuleLevel(name string) zapcore.Level {
+	return z.levelOf(name).Level()
+}
+
+// levelOf returns the level applying to a module logger.
+func (z *Zap) levelOf(name string) zap.AtomicLevel {
+	z.mu.RLock()
+	defer z.mu.RUnlock()
+	if level, ok := z.moduleLevels[name]; ok {
+		return level
+	}
+	return z.level
+}
+
+// newLogger builds a logger writing to the outputs at the given level.
+func (z *Zap) newLogger(level zapcore.LevelEnabler) *zap.Logger {
+	return zap.New(
+		&levelFilterCore{Core: z.base, level: level},
+		zap.AddCaller(),
+		zap.AddStacktrace(zap.ErrorLevel),
+	)
+}
+
+// moduleLevel looks up the level of a module logger on every entry, so that
+// changes made after the logger was created apply.
+type moduleLevel struct {
+	z    *Zap
+	name string
+}
+
+// Enabled implements zapcore.LevelEnabler.
+func (l moduleLevel) Enabled(level zapcore.Level) bool {
+	return l.z.levelOf(l.name).Enabled(level)
+}
+
+// levelFilterCore applies its own level on top of the wrapped core. Unlike
+// zapcore.NewIncreaseLevelCore, the level may be lower than the wrapped one.
+type levelFilterCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+// Enabled implements zapcore.LevelEnabler.
+func (c *levelFilterCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) && c.Core.Enabled(level)
+}
+
+// With implements zapcore.Core.
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilterCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// Check implements zapcore.Core.
+func (c *levelFilterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// parseLevel converts a configured level name into a zapcore.Level.
+func parseLevel(level string) zapcore.Level {
+	switch level {
 	case "debug":
-		level = zapcore.DebugLevel
+		return zapcore.DebugLevel
 	case "info":
-		level = zapcore.InfoLevel
+		return zapcore.InfoLevel
 	case "warn":
-		level = zapcore.WarnLevel
+		return zapcore.WarnLevel
 	case "error":
-		level = zapcore.ErrorLevel
+		return zapcore.ErrorLevel
 	default:
-		level = zapcore.InfoLevel
-	}
-	encoder := getEncodeLog()
-	sync := getWriteSync(config)
-	core := zapcore.NewCore(encoder, sync, level)
-
-	return &Zap{
-		zap.New(
-			core, zap.AddCaller(),
-			zap.AddStacktrace(zap.ErrorLevel)).Named(fmt.Sprintf("[%s-%s]",
-			mode,
-			version),
+		return zapcore.InfoLevel
+	}
+}
+
+// outputLevel returns the minimum level of an output. When none is configured,
+// the output writes everything, the lines being filtered by the logger and module
+// levels only.
+func outputLevel(level string) zapcore.Level {
+	if level == "" {
+		return zapcore.DebugLevel
+	}
+	return parseLevel(level)
+}
+
+// getCores returns a core per output: the console and, when a file name is
+// configured, a file rotated by lumberjack.
+func getCores(config setting.LoggerSettings) []zapcore.Core {
+	cores := []zapcore.Core{
+		zapcore.NewCore(
+			getEncodeLog(config.Console.Format, FormatConsole),
+			zapcore.AddSync(os.Stdout),
+			outputLevel(config.Console.Level),
 		),
 	}
+
+	if config.FileName != "" {
+		hook := &lumberjack.Logger{
+			Filename:   config.FileName,
+			MaxSize:    config.MaxSize,
+			MaxBackups: config.MaxBackups,
+			MaxAge:     config.MaxAge,
+			Compress:   config.Compress,
+		}
+		cores = append(cores, zapcore.NewCore(
+			getEncodeLog(config.File.Format, FormatJSON),
+			zapcore.AddSync(hook),
+			outputLevel(config.File.Level),
+		))
+	}
+
+	return cores
 }
 
-// getEncodeLog returns a zapcore.Encoder configured with a console encoder
-// and custom timestamp, level, and caller formatting.
-func getEncodeLog() zapcore.Encoder {
+// getEncodeLog returns a zapcore.Encoder of the given format, or of the fallback
+// format when none is set, with custom timestamp, level, and caller formatting.
+func getEncodeLog(format, fallback string) zapcore.Encoder {
 	encoderConfig := zap.NewProductionEncoderConfig()
 
 	// format timestamp
@@ -63,22 +253,23 @@ func getEncodeLog() zapcore.Encoder {
 
 	// "caller": "cli/main.log.go:3"
 	encoderConfig.EncodeCaller = zapcore.ShortCallerEncoder
+
+	if format == "" {
+		format = fallback
+	}
+	if format == FormatJSON {
+		return zapcore.NewJSONEncoder(encoderConfig)
+	}
 	return zapcore.NewConsoleEncoder(encoderConfig)
 }
 
-// getWriteSync creates a zapcore.WriteSyncer that combines console output
-// and file output based on the provided configuration.
-func getWriteSync(config setting.LoggerSettings) zapcore.WriteSyncer {
-	syncConsole := zapcore.AddSync(os.Stdout)
-	if config.FileName == "" {
-		return zapcore.NewMultiWriteSyncer(syncConsole)
+// instanceID returns the configured instance ID, or the host name.
+func instanceID(configured string) string {
+	if configured != "" {
+		return configured
 	}
-	hook := lumberjack.Logger{
-		Filename:   config.FileName,
-		MaxSize:    config.MaxSize,
-		MaxBackups: config.MaxBackups,
-		MaxAge:     config.MaxAge,
-		Compress:   config.Compress,
+	if hostname, err := os.Hostname(); err == nil {
+		return hostname
 	}
-	return zapcore.NewMultiWriteSyncer(syncConsole, zapcore.AddSync(&hook))
+	return "unknown"
 }
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/redact"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactCore hides the values of the sensitive fields before they reach the
+// wrapped core. Fields holding maps or structs are redacted at any depth.
+type redactCore struct {
+	zapcore.Core
+	redactor *redact.Redactor
+}
+
+// With implements zapcore.Core.
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactFields(fields)), redactor: c.redactor}
+}
+
+// Check implements zapcore.Core.
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write implements zapcore.Core.
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.redactFields(fields))
+}
+
+// redactFields returns the fields with the sensitive values hidden.
+func (c *redactCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, field := range fields {
+		replacement, changed := c.redactField(field)
+		if !changed {
+			continue
+		}
+		if redacted == nil {
+			redacted = append([]zapcore.Field(nil), fields...)
+		}
+		redacted[i] = replacement
+	}
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+// redactField hides the value of a sensitive field, or the sensitive content of
+// a field holding an object, an array, a map or a struct. The field is kept as is
+// when it holds nothing sensitive.
+func (c *redactCore) redactField(field zapcore.Field) (zapcore.Field, bool) {
+	if c.redactor.Matches(field.Key) {
+		return zap.String(field.Key, redact.Placeholder), true
+	}
+
+	var value any
+	switch field.Type {
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		// Encode the object or the array to reach the keys it holds.
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		value = enc.Fields[field.Key]
+	case zapcore.ReflectType:
+		if field.Interface == nil {
+			return field, false
+		}
+		// Go through JSON to reach the fields of any map or struct, keeping the
+		// numbers as written.
+		content, err := json.Marshal(field.Interface)
+		if err != nil {
+			return field, false
+		}
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return field, false
+		}
+	default:
+		// Errors and the other fields only hold values, hidden when their key
+		// matches.
+		return field, false
+	}
+
+	if !c.redactor.Sensitive(value) {
+		return field, false
+	}
+	return zap.Any(field.Key, c.redactor.Value(value)), true
+}
@@ -0,0 +1,289 @@
+// Package memstore keeps documents in memory with the semantics of a MongoDB
+// collection, backing the in-memory repositories used by tests.
+package memstore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ErrNotFound is returned when no document matches the filter.
+var ErrNotFound = errors.New("document not found")
+
+// ErrDuplicateKey is returned when a write would break a unique key.
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// Collection keeps the documents of type T as BSON, so that they go through the
+// same encoding as with MongoDB. Filters match the top-level fields by equality
+// and updates support the $set and $unset operators on top-level fields.
+type Collection[T any] struct {
+	mu sync.RWMutex
+	// docs keeps the insertion order, as a collection scan would.
+	docs   []bson.Raw
+	unique []string
+}
+
+// NewCollection creates an empty collection. Every unique key must have a
+// distinct value across the documents, a missing field counting as null.
+func NewCollection[T any](unique ...string) *Collection[T] {
+	return &Collection[T]{unique: append([]string{"_id"}, unique...)}
+}
+
+// Insert adds a document, which must have an _id.
+func (c *Collection[T]) Insert(doc *T) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	if _, err := bson.Raw(raw).LookupErr("_id"); err != nil {
+		return errors.New("document has no _id")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.checkUnique(raw, -1); err != nil {
+		return err
+	}
+	c.docs = append(c.docs, raw)
+	return nil
+}
+
+// FindOne returns the first document matching the filter.
+func (c *Collection[T]) FindOne(filter bson.M) (*T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	i, err := c.find(filter)
+	if err != nil {
+		return nil, err
+	}
+	return decode[T](c.docs[i])
+}
+
+// Find returns the documents matching the filter, in insertion order.
+func (c *Collection[T]) Find(filter bson.M) ([]T, error) {
+	match, err := matcher(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	found := []T{}
+	for _, raw := range c.docs {
+		if !match(raw) {
+			continue
+		}
+		doc, err := decode[T](raw)
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, *doc)
+	}
+	return found, nil
+}
+
+// FindOneAndUpdate applies the update to the first document matching the filter
+// and returns the document after the update.
+func (c *Collection[T]) FindOneAndUpdate(filter bson.M, update bson.D) (*T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := apply(c.docs[i], update)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkUnique(raw, i); err != nil {
+		return nil, err
+	}
+	c.docs[i] = raw
+	return decode[T](raw)
+}
+
+// ReplaceOne replaces the first document matching the filter, keeping its _id.
+func (c *Collection[T]) ReplaceOne(filter bson.M, doc *T) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.find(filter)
+	if err != nil {
+		return err
+	}
+	if !lookup(raw, "_id").Equal(lookup(c.docs[i], "_id")) {
+		return errors.New("replacement changes the _id")
+	}
+	if err := c.checkUnique(raw, i); err != nil {
+		return err
+	}
+	c.docs[i] = raw
+	return nil
+}
+
+// DeleteOne removes the first document matching the filter and reports whether
+// there was one.
+func (c *Collection[T]) DeleteOne(filter bson.M) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, err := c.find(filter)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	c.docs = append(c.docs[:i], c.docs[i+1:]...)
+	return true, nil
+}
+
+// Len returns the number of documents.
+func (c *Collection[T]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.docs)
+}
+
+// find returns the index of the first document matching the filter.
+func (c *Collection[T]) find(filter bson.M) (int, error) {
+	match, err := matcher(filter)
+	if err != nil {
+		return 0, err
+	}
+	for i, raw := range c.docs {
+		if match(raw) {
+			return i, nil
+		}
+	}
+	return 0, ErrNotFound
+}
+
+// checkUnique returns ErrDuplicateKey when another document than the one at
+// index self has the same value for a unique key.
+func (c *Collection[T]) checkUnique(raw bson.Raw, self int) error {
+	for _, key := range c.unique {
+		value := lookup(raw, key)
+		for i, other := range c.docs {
+			if i != self && lookup(other, key).Equal(value) {
+				return fmt.Errorf("%w: %s", ErrDuplicateKey, key)
+			}
+		}
+	}
+	return nil
+}
+
+// matcher returns a function reporting whether a document has the values of
+// the filter.
+func matcher(filter bson.M) (func(bson.Raw) bool, error) {
+	for key := range filter {
+		if strings.HasPrefix(key, "$") || strings.Contains(key, ".") {
+			return nil, fmt.Errorf("unsupported filter key %q", key)
+		}
+	}
+	raw, err := bson.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	elems, err := bson.Raw(raw).Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(doc bson.Raw) bool {
+		for _, elem := range elems {
+			if !lookup(doc, elem.Key()).Equal(elem.Value()) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// apply returns the document with the update operators applied.
+func apply(raw bson.Raw, update bson.D) (bson.Raw, error) {
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	for _, op := range update {
+		fields, err := toD(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op.Key, err)
+		}
+		for _, field := range fields {
+			if field.Key == "_id" || strings.Contains(field.Key, ".") {
+				return nil, fmt.Errorf("unsupported update of field %q", field.Key)
+			}
+			switch op.Key {
+			case "$set":
+				doc = set(doc, field)
+			case "$unset":
+				doc = unset(doc, field.Key)
+			default:
+				return nil, fmt.Errorf("unsupported update operator %q", op.Key)
+			}
+		}
+	}
+
+	return bson.Marshal(doc)
+}
+
+// set replaces the value of a field, or appends the field when missing.
+func set(doc bson.D, field bson.E) bson.D {
+	for i := range doc {
+		if doc[i].Key == field.Key {
+			doc[i].Value = field.Value
+			return doc
+		}
+	}
+	return append(doc, field)
+}
+
+// unset removes a field.
+func unset(doc bson.D, key string) bson.D {
+	for i := range doc {
+		if doc[i].Key == key {
+			return append(doc[:i], doc[i+1:]...)
+		}
+	}
+	return doc
+}
+
+// toD converts the value of an update operator, a document of any type, into
+// a bson.D.
+func toD(value any) (bson.D, error) {
+	raw, err := bson.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var fields bson.D
+	return fields, bson.Unmarshal(raw, &fields)
+}
+
+// lookup returns the value of a field, null when missing.
+func lookup(raw bson.Raw, key string) bson.RawValue {
+	value, err := raw.LookupErr(key)
+	if err != nil {
+		return bson.RawValue{Type: bson.TypeNull}
+	}
+	return value
+}
+
+// decode unmarshals a document into a new T.
+func decode[T any](raw bson.Raw) (*T, error) {
+	doc := new(T)
+	if err := bson.Unmarshal(raw, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	_ "embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed swagger.html
+var swaggerPage string
+
+var swaggerTemplate = template.Must(template.New("swagger").Parse(swaggerPage))
+
+// Handler serves the document as JSON. The document may be set after the
+// handler is registered, once every route is, and must be by the first request.
+func Handler(doc **Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, *doc)
+	}
+}
+
+// UIHandler serves the Swagger UI displaying the document served at specURL,
+// loading its assets from assetsURL, where AssetsHandler serves them.
+func UIHandler(title, specURL, assetsURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		err := swaggerTemplate.Execute(c.Writer, map[string]string{
+			"Title":     title,
+			"SpecURL":   specURL,
+			"AssetsURL": assetsURL,
+		})
+		if err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// AssetsHandler serves the assets of the Swagger UI, the file named by the
+// filepath wildcard of the route. It serves none when UIAvailable is not set.
+func AssetsHandler() gin.HandlerFunc {
+	assets, err := fs.Sub(swaggerUI, "swaggerui")
+	if err != nil {
+		panic(err)
+	}
+	return func(c *gin.Context) {
+		c.FileFromFS(c.Param("filepath"), http.FS(assets))
+	}
+}
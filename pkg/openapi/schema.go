@@ -0,0 +1,290 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(bson.ObjectID{})
+)
+
+// Schemas generates the schemas of Go types. Named structs become component
+// schemas, referenced by the schemas using them.
+type Schemas struct {
+	components map[string]*Schema
+	types      map[string]reflect.Type
+}
+
+// NewSchemas creates a new instance of Schemas without any component.
+func NewSchemas() *Schemas {
+	return &Schemas{
+		components: map[string]*Schema{},
+		types:      map[string]reflect.Type{},
+	}
+}
+
+// Components returns the component schemas generated so far, by name.
+func (s *Schemas) Components() map[string]*Schema {
+	return s.components
+}
+
+// Of returns the schema of the type of v.
+func (s *Schemas) Of(v any) *Schema {
+	return s.schema(reflect.TypeOf(v))
+}
+
+// schema returns the schema of the type t.
+func (s *Schemas) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIDType:
+		return &Schema{Type: "string", Pattern: "^[0-9a-f]{24}$"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.component(t)
+	default:
+		// Interfaces may hold any value.
+		return &Schema{}
+	}
+}
+
+// component registers the schema of the named struct t and references it.
+func (s *Schemas) component(t reflect.Type) *Schema {
+	name := componentName(t)
+	if _, ok := s.types[name]; !ok {
+		// Registered before being generated, for recursive types.
+		s.types[name] = t
+		s.components[name] = s.object(t)
+	}
+	return Ref(name)
+}
+
+// object returns the schema of the struct t, with the properties of its embedded
+// structs inlined the way encoding/json does.
+func (s *Schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(schema, t)
+	return schema
+}
+
+func (s *Schemas) addFields(schema *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			s.addFields(schema, fieldType)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.schema(field.Type)
+		if applyRules(property, fieldType, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyRules adds the constraints of the validate rules to the schema of a field
+// of type t, reporting whether the field is required. Rules without an equivalent
+// are left out.
+func applyRules(schema *Schema, t reflect.Type, rules string) bool {
+	if rules == "" {
+		return false
+	}
+	// Rules of a referenced schema would change the component.
+	if schema.Ref != "" {
+		return strings.Contains(","+rules+",", ",required,")
+	}
+
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		// The rules after dive apply to the elements.
+		if rule == "dive" {
+			break
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "uuid":
+			schema.Format = "uuid"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(t, value))
+			}
+		case "min", "gte":
+			applyBound(schema, param, boundMin, false)
+		case "max", "lte":
+			applyBound(schema, param, boundMax, false)
+		case "gt":
+			applyBound(schema, param, boundMin, true)
+		case "lt":
+			applyBound(schema, param, boundMax, true)
+		case "len":
+			applyBound(schema, param, boundMin, false)
+			applyBound(schema, param, boundMax, false)
+		}
+	}
+	return required
+}
+
+type bound int
+
+const (
+	boundMin bound = iota
+	boundMax
+)
+
+// applyBound applies a bound to the length of strings and arrays, or to the
+// value of numbers.
+func applyBound(schema *Schema, param string, b bound, exclusive bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	switch schema.Type {
+	case "string":
+		setLength(&schema.MinLength, &schema.MaxLength, int(value), b, exclusive)
+	case "array":
+		setLength(&schema.MinItems, &schema.MaxItems, int(value), b, exclusive)
+	case "integer", "number":
+		switch {
+		case b == boundMin && exclusive:
+			schema.ExclusiveMinimum = &value
+		case b == boundMin:
+			schema.Minimum = &value
+		case exclusive:
+			schema.ExclusiveMaximum = &value
+		default:
+			schema.Maximum = &value
+		}
+	}
+}
+
+// setLength sets the minimum or the maximum of a length, exclusive bounds being
+// turned into inclusive ones.
+func setLength(minimum, maximum **int, length int, b bound, exclusive bool) {
+	if b == boundMin {
+		if exclusive {
+			length++
+		}
+		*minimum = &length
+		return
+	}
+	if exclusive {
+		length--
+	}
+	*maximum = &length
+}
+
+// enumValue converts a oneof value to the type of the field.
+func enumValue(t reflect.Type, value string) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+// componentName returns the name of the component schema of a named type. The
+// type arguments of generic types are part of the name, e.g. TDataResponse_UserDto.
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	base, args, ok := strings.Cut(name, "[")
+	if !ok {
+		return name
+	}
+
+	parts := []string{base}
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		parts = append(parts, typeArgName(arg))
+	}
+	return strings.Join(parts, "_")
+}
+
+// typeArgName returns the name of a type argument without its package path, e.g.
+// UserDto for *example.com/users.UserDto, UserDtoList for a slice, UserDtoMap for
+// a map and Any for any.
+func typeArgName(arg string) string {
+	if arg == "interface {}" {
+		return "Any"
+	}
+	if elem, ok := strings.CutPrefix(arg, "[]"); ok {
+		return typeArgName(elem) + "List"
+	}
+	if rest, ok := strings.CutPrefix(arg, "map["); ok {
+		_, elem, _ := strings.Cut(rest, "]")
+		return typeArgName(elem) + "Map"
+	}
+	arg = strings.TrimLeft(arg, "*")
+	arg = arg[strings.LastIndex(arg, "/")+1:]
+	return arg[strings.LastIndex(arg, ".")+1:]
+}
@@ -0,0 +1,298 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/fieldset"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+)
+
+// Route documents a route of the API. The path uses the gin syntax, its
+// parameters are documented as string path parameters.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Tags        []string
+	Query       []Param
+
+	// Request is the body of the requests, if any.
+	Request any
+	// Upload lists the media types of the raw bodies, such as files, accepted
+	// instead of a Request.
+	Upload []string
+	// Status is the status of the successful responses, 200 when not set.
+	Status int
+	// Response is the body of the successful responses, if any, such as a
+	// response.TDataResponse[UserDto].
+	Response any
+	// Download lists the media types of the raw bodies, such as files, streamed
+	// instead of a Response.
+	Download []string
+	// Errors are the statuses of the error responses.
+	Errors []int
+	// Security names the security schemes authenticating the requests.
+	Security []string
+	// Hidden routes are left out of the document, e.g. the documentation itself.
+	Hidden bool
+}
+
+// Param documents a query parameter.
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// FieldsParam documents the fields query parameter of the routes letting the clients
+// select the fields of the data, see response.WithFields.
+var FieldsParam = Param{
+	Name:        fieldset.Param,
+	Description: "Comma-separated JSON paths of the fields to return, nested fields separated by dots",
+}
+
+// Spec gathers the documentation of the routes and builds the OpenAPI document.
+type Spec struct {
+	info            Info
+	routes          []Route
+	securitySchemes map[string]*SecurityScheme
+}
+
+// New creates a new instance of Spec describing the API with info.
+func New(info Info) *Spec {
+	return &Spec{
+		info:            info,
+		securitySchemes: map[string]*SecurityScheme{},
+	}
+}
+
+// Add documents routes.
+func (s *Spec) Add(routes ...Route) *Spec {
+	s.routes = append(s.routes, routes...)
+	return s
+}
+
+// SecurityScheme declares a security scheme the routes may name.
+func (s *Spec) SecurityScheme(name string, scheme *SecurityScheme) *Spec {
+	s.securitySchemes[name] = scheme
+	return s
+}
+
+// Build returns the document of the documented routes. It also fails when the
+// registered routes and the documented ones drift apart, reporting each route
+// missing on either side, and each route documenting other request, response or
+// status than its handler built by response.Handle.
+func (s *Spec) Build(registered gin.RoutesInfo) (*Document, error) {
+	schemas := NewSchemas()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas:         schemas.Components(),
+			SecuritySchemes: s.securitySchemes,
+		},
+	}
+
+	documented := make(map[string]bool, len(s.routes))
+	for _, route := range s.routes {
+		documented[route.Method+" "+route.Path] = true
+	}
+
+	var errs []error
+	for _, route := range registered {
+		if !documented[route.Method+" "+route.Path] {
+			errs = append(errs, fmt.Errorf("route %s %s is not documented", route.Method, route.Path))
+		}
+	}
+
+	handlers := make(map[string]gin.HandlerFunc, len(registered))
+	for _, route := range registered {
+		handlers[route.Method+" "+route.Path] = route.HandlerFunc
+	}
+	for _, route := range s.routes {
+		handler, ok := handlers[route.Method+" "+route.Path]
+		if !ok {
+			errs = append(errs, fmt.Errorf(
+				"documented route %s %s is not registered", route.Method, route.Path,
+			))
+			continue
+		}
+		errs = append(errs, checkHandler(route, handler)...)
+		if route.Hidden {
+			continue
+		}
+
+		path, params := pathParams(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(route.Method)] = s.operation(schemas, route, params)
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return doc, errors.Join(errs...)
+}
+
+// checkHandler reports the request, response and status of the route documented
+// otherwise than by its handler, when built by response.Handle. The documented
+// request is either the request of the handler or embedded by it, along with its
+// path and query parameters.
+func checkHandler(route Route, handler gin.HandlerFunc) []error {
+	desc, ok := response.Describe(handler)
+	if !ok {
+		return nil
+	}
+
+	var errs []error
+	drift := func(what string, documented, handled any) {
+		errs = append(errs, fmt.Errorf(
+			"route %s %s documents %s %v, its handler has %v",
+			route.Method, route.Path, what, documented, handled,
+		))
+	}
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status != desc.Status {
+		drift("status", status, desc.Status)
+	}
+	if response := reflect.TypeOf(route.Response); response != desc.Response {
+		drift("response", response, desc.Response)
+	}
+	if request := reflect.TypeOf(route.Request); request != nil && !bindsInto(request, desc.Request) {
+		drift("request", request, desc.Request)
+	}
+	return errs
+}
+
+// bindsInto reports whether the body of the request is bound into handled, being
+// the type itself or embedded by it.
+func bindsInto(request, handled reflect.Type) bool {
+	if request == handled {
+		return true
+	}
+	if handled.Kind() != reflect.Struct {
+		return false
+	}
+	for i := range handled.NumField() {
+		if field := handled.Field(i); field.Anonymous && field.Type == request {
+			return true
+		}
+	}
+	return false
+}
+
+// operation returns the operation documenting the route.
+func (s *Spec) operation(schemas *Schemas, route Route, params []*Parameter) *Operation {
+	op := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Tags:        route.Tags,
+		Parameters:  params,
+		Responses:   map[string]*Response{},
+	}
+	for _, param := range route.Query {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+	for _, name := range route.Security {
+		op.Security = append(op.Security, map[string][]string{name: {}})
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  envelopeContent(schemas.Of(route.Request)),
+		}
+	}
+	if len(route.Upload) > 0 {
+		op.RequestBody = &RequestBody{Required: true, Content: rawContent(route.Upload)}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	op.Responses[strconv.Itoa(status)] = &Response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		op.Responses[strconv.Itoa(status)].Content = envelopeContent(schemas.Of(route.Response))
+	}
+	if len(route.Download) > 0 {
+		op.Responses[strconv.Itoa(status)].Content = rawContent(route.Download)
+	}
+
+	for _, status := range route.Errors {
+		content := envelopeContent(errorEnvelope(schemas))
+		content[response.ProblemContentType] = &MediaType{Schema: schemas.Of(response.ProblemDetails{})}
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content:     content,
+		}
+	}
+	return op
+}
+
+// envelopeContent returns the content of a body of the given schema in each of the
+// formats negotiated by the response package, the structure being the same.
+func envelopeContent(schema *Schema) map[string]*MediaType {
+	content := map[string]*MediaType{}
+	for _, mediaType := range response.MediaTypes() {
+		content[mediaType] = &MediaType{Schema: schema}
+	}
+	return content
+}
+
+// rawContent returns the content of a raw body in the given media types.
+func rawContent(mediaTypes []string) map[string]*MediaType {
+	content := map[string]*MediaType{}
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = &MediaType{Schema: &Schema{Type: "string"}}
+	}
+	return content
+}
+
+// errorEnvelope returns the schema of the response.TErrResponse envelopes, whose
+// errors are either the error message or the field violations.
+func errorEnvelope(schemas *Schemas) *Schema {
+	return &Schema{OneOf: []*Schema{
+		schemas.Of(response.TErrResponse[string]{}),
+		schemas.Of(response.TErrResponse[[]response.Violation]{}),
+	}}
+}
+
+// pathParams converts a gin path into an OpenAPI one, returning its parameters.
+func pathParams(path string) (string, []*Parameter) {
+	var params []*Parameter
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "" || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
@@ -0,0 +1,15 @@
+//go:build !noswaggerui
+
+package openapi
+
+import "embed"
+
+// UIAvailable reports whether the assets of the Swagger UI are embedded in the
+// binary, left out by the noswaggerui build tag.
+const UIAvailable = true
+
+// swaggerUI holds the assets of swagger-ui-dist, vendored for the documentation to
+// display without reaching a CDN. `make swagger-ui` updates them.
+//
+//go:embed swaggerui
+var swaggerUI embed.FS
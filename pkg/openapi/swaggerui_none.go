@@ -0,0 +1,12 @@
+//go:build noswaggerui
+
+package openapi
+
+import "embed"
+
+// UIAvailable reports whether the assets of the Swagger UI are embedded in the
+// binary, left out by the noswaggerui build tag.
+const UIAvailable = false
+
+// swaggerUI is empty, the assets being left out of the binary.
+var swaggerUI embed.FS
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval controls how many calls to Allow happen between two sweeps
+// of expired counters.
+const sweepInterval = 1000
+
+// counter holds the request counts of the current and the previous window.
+type counter struct {
+	windowStart time.Time
+	window      time.Duration
+	prev        int64
+	curr        int64
+}
+
+// MemoryStore is an in-process Store, suitable for single instance deployments.
+type MemoryStore struct {
+	mu       sync.Mutex
+	counters map[string]*counter
+	calls    int
+	now      func() time.Time
+}
+
+// NewMemoryStore creates a new instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		counters: make(map[string]*counter),
+		now:      time.Now,
+	}
+}
+
+// Allow implements Store.
+func (s *MemoryStore) Allow(
+	_ context.Context,
+	key string,
+	limit int,
+	window time.Duration,
+) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	windowStart := now.Truncate(window)
+
+	s.calls++
+	if s.calls%sweepInterval == 0 {
+		s.sweep(now)
+	}
+
+	c, ok := s.counters[key]
+	if !ok {
+		c = &counter{windowStart: windowStart, window: window}
+		s.counters[key] = c
+	}
+
+	// Roll the windows forward when the current one is over.
+	if !c.windowStart.Equal(windowStart) {
+		if windowStart.Sub(c.windowStart) == window {
+			c.prev = c.curr
+		} else {
+			c.prev = 0
+		}
+		c.curr = 0
+		c.windowStart = windowStart
+	}
+
+	res := decide(c.prev, c.curr, limit, window, now.Sub(windowStart))
+	if res.Allowed {
+		c.curr++
+	}
+	return res, nil
+}
+
+// Close implements Store.
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// sweep drops counters that no longer affect any sliding window.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, c := range s.counters {
+		if now.Sub(c.windowStart) >= 2*c.window {
+			delete(s.counters, key)
+		}
+	}
+}
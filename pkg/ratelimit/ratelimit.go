@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Key strategies supported by the rate limit middleware.
+const (
+	KeyByIP     = "ip"
+	KeyByUser   = "user"
+	KeyByAPIKey = "api_key"
+)
+
+// Result describes the outcome of a single rate limit check.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // time until the current window resets
+	RetryAfter time.Duration // only set when the request is not allowed
+}
+
+// Store defines the backend used to count requests for a key.
+type Store interface {
+	// Allow records a hit for the key if it still fits into the limit
+	// for the given window and reports the resulting state.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (*Result, error)
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// decide applies the sliding window counter algorithm. The previous window
+// count is weighted by how much of it still overlaps the sliding window and
+// added to the count of the current window (before this request).
+func decide(prev, curr int64, limit int, window, elapsed time.Duration) *Result {
+	weight := 1 - float64(elapsed)/float64(window)
+	estimated := float64(prev)*weight + float64(curr)
+	res := &Result{
+		Limit: limit,
+		Reset: window - elapsed,
+	}
+
+	if estimated+1 > float64(limit) {
+		res.RetryAfter = retryAfter(prev, curr, limit, window, elapsed)
+		return res
+	}
+
+	res.Allowed = true
+	res.Remaining = max(limit-int(math.Ceil(estimated))-1, 0)
+	return res
+}
+
+// retryAfter estimates how long a client has to wait until the weighted
+// count drops low enough to admit one more request.
+func retryAfter(prev, curr int64, limit int, window, elapsed time.Duration) time.Duration {
+	// The current window alone is full, nothing frees up before it ends.
+	if curr+1 > int64(limit) || prev == 0 {
+		return window - elapsed
+	}
+
+	// Solve prev*(1-e/window) + curr + 1 <= limit for e.
+	ratio := float64(int64(limit)-curr-1) / float64(prev)
+	needed := time.Duration(float64(window) * (1 - ratio))
+	if needed <= elapsed {
+		return time.Second
+	}
+	return needed - elapsed
+}
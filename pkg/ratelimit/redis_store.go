@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript checks and increments the counter of the current window
+// atomically. It returns whether the hit was accepted along with the counts of
+// the current (before the hit) and the previous window.
+var slidingWindowScript = redis.NewScript(`
+local curr = tonumber(redis.call('GET', KEYS[1]) or '0')
+local prev = tonumber(redis.call('GET', KEYS[2]) or '0')
+local limit = tonumber(ARGV[1])
+local weight = tonumber(ARGV[2])
+
+if prev * weight + curr + 1 > limit then
+	return {0, curr, prev}
+end
+
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {1, curr, prev}
+`)
+
+// RedisStore is a Store backed by any server speaking the Redis protocol,
+// so the limits are shared between all instances of the application.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisStore creates a new instance of RedisStore using the given client.
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: "ratelimit",
+		now:    time.Now,
+	}
+}
+
+// Allow implements Store.
+func (s *RedisStore) Allow(
+	ctx context.Context,
+	key string,
+	limit int,
+	window time.Duration,
+) (*Result, error) {
+	now := s.now()
+	windowStart := now.Truncate(window)
+	elapsed := now.Sub(windowStart)
+	weight := 1 - float64(elapsed)/float64(window)
+
+	keys := []string{
+		fmt.Sprintf("%s:%s:%d", s.prefix, key, windowStart.UnixMilli()),
+		fmt.Sprintf("%s:%s:%d", s.prefix, key, windowStart.Add(-window).UnixMilli()),
+	}
+	values, err := slidingWindowScript.Run(
+		ctx, s.client, keys,
+		limit, weight, (2 * window).Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+
+	return decide(values[2], values[1], limit, window, elapsed), nil
+}
+
+// Close implements Store.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,194 @@
+// Package redact hides sensitive values, such as passwords and tokens, from
+// logged data.
+package redact
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Placeholder replaces the redacted values.
+const Placeholder = "[REDACTED]"
+
+// DefaultPatterns are the field patterns redacted when none are configured.
+var DefaultPatterns = []string{
+	"password",
+	"verification_code",
+	"token",
+	"authorization",
+	"secret",
+	"pepper",
+	"cookie",
+}
+
+// Redactor hides the values of the fields whose name contains one of its
+// patterns, ignoring case. A nil Redactor redacts nothing.
+type Redactor struct {
+	patterns []string
+}
+
+// New creates a new instance of Redactor matching the given field patterns,
+// or DefaultPatterns when none are given.
+func New(patterns []string) *Redactor {
+	if len(patterns) == 0 {
+		patterns = DefaultPatterns
+	}
+	normalized := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern = normalize(pattern); pattern != "" {
+			normalized = append(normalized, pattern)
+		}
+	}
+	return &Redactor{patterns: normalized}
+}
+
+// Matches reports whether the value of the named field must be hidden.
+func (r *Redactor) Matches(field string) bool {
+	if r == nil {
+		return false
+	}
+	field = normalize(field)
+	for _, pattern := range r.patterns {
+		if strings.Contains(field, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns a copy of v, a decoded JSON value or BSON document, with the
+// values of the sensitive fields hidden at any depth.
+func (r *Redactor) Value(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, value := range v {
+			redacted[key] = r.field(key, value)
+		}
+		return redacted
+	case bson.M:
+		redacted := make(bson.M, len(v))
+		for key, value := range v {
+			redacted[key] = r.field(key, value)
+		}
+		return redacted
+	case bson.D:
+		redacted := make(bson.D, len(v))
+		for i, e := range v {
+			redacted[i] = bson.E{Key: e.Key, Value: r.field(e.Key, e.Value)}
+		}
+		return redacted
+	case bson.A:
+		redacted := make(bson.A, len(v))
+		for i, value := range v {
+			redacted[i] = r.Value(value)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, value := range v {
+			redacted[i] = r.Value(value)
+		}
+		return redacted
+	default:
+		return v
+	}
+}
+
+// Sensitive reports whether v, a decoded JSON value or BSON document, holds a
+// sensitive field at any depth, that is whether Value would change it.
+func (r *Redactor) Sensitive(v any) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if r.Matches(key) || r.Sensitive(value) {
+				return true
+			}
+		}
+	case bson.M:
+		for key, value := range v {
+			if r.Matches(key) || r.Sensitive(value) {
+				return true
+			}
+		}
+	case bson.D:
+		for _, e := range v {
+			if r.Matches(e.Key) || r.Sensitive(e.Value) {
+				return true
+			}
+		}
+	case bson.A:
+		for _, value := range v {
+			if r.Sensitive(value) {
+				return true
+			}
+		}
+	case []any:
+		for _, value := range v {
+			if r.Sensitive(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Document returns a BSON document, such as a Mongo command, as extended JSON
+// with the values of the sensitive fields hidden.
+func (r *Redactor) Document(raw bson.Raw) string {
+	var doc bson.D
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return Placeholder
+	}
+	out, err := bson.MarshalExtJSON(r.Value(doc), false, false)
+	if err != nil {
+		return Placeholder
+	}
+	return string(out)
+}
+
+// Query returns the query string with the values of the sensitive parameters hidden.
+func (r *Redactor) Query(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Placeholder
+	}
+	for key := range values {
+		if r.Matches(key) {
+			values[key] = []string{Placeholder}
+		}
+	}
+	return values.Encode()
+}
+
+// Header returns a copy of the headers with the values of the sensitive ones hidden.
+func (r *Redactor) Header(header http.Header) http.Header {
+	redacted := make(http.Header, len(header))
+	for key, values := range header {
+		if r.Matches(key) {
+			redacted[key] = []string{Placeholder}
+			continue
+		}
+		redacted[key] = values
+	}
+	return redacted
+}
+
+// field hides the value of a sensitive field, or redacts its content.
+func (r *Redactor) field(key string, value any) any {
+	if r.Matches(key) {
+		return Placeholder
+	}
+	return r.Value(value)
+}
+
+// normalize makes "verificationCode", "Verification-Code" and "verification_code" equal.
+func normalize(name string) string {
+	return strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(name))
+}
@@ -17,13 +17,33 @@ var (
 	ErrStolenToken      = errors.New("stolen token")
 	ErrInvalidObjectID  = errors.New("invalid object id")
 	ErrValidation       = errors.New("validation error")
+	ErrTooManyRequests  = errors.New("too many requests")
+	ErrConflict         = errors.New("conflict")
+	ErrUnprocessable    = errors.New("unprocessable entity")
+	ErrUnavailable      = errors.New("service unavailable")
+	ErrNotAcceptable    = errors.New("not acceptable")
+	ErrUnsupportedMedia = errors.New("unsupported media type")
+	ErrPayloadTooLarge  = errors.New("payload too large")
 )
 
+// Violation describes a single invalid field of a request. Field is the JSON path
+// of the field, Rule the violated rule along with its Params.
+type Violation struct {
+	Field   string   `json:"field"`
+	Rule    string   `json:"rule,omitempty"`
+	Params  []string `json:"params,omitempty"`
+	Message string   `json:"message"`
+}
+
 // Error represents a composite error that contains both an application-level
-// error and a service-level error.
+// error and a service-level error. It may carry a stable machine-readable code,
+// a human-readable detail and field-level violations.
 type Error struct {
 	appErr     error
 	serviceErr error
+	code       string
+	detail     string
+	violations []Violation
 }
 
 // NewError creates a new Error instance with the given application-level and
@@ -35,6 +55,24 @@ func NewError(appErr, serviceErr error) *Error {
 	}
 }
 
+// WithCode sets the machine-readable code of the Error, e.g. "USER_NOT_FOUND".
+func (e *Error) WithCode(code string) *Error {
+	e.code = code
+	return e
+}
+
+// WithDetail sets a human-readable explanation specific to this occurrence of the Error.
+func (e *Error) WithDetail(detail string) *Error {
+	e.detail = detail
+	return e
+}
+
+// WithViolations appends field-level violations to the Error.
+func (e *Error) WithViolations(violations ...Violation) *Error {
+	e.violations = append(e.violations, violations...)
+	return e
+}
+
 // Error returns a string representation of the composite error, which includes
 // both the application-level error and the service-level error.
 func (e *Error) Error() string {
@@ -81,7 +119,80 @@ func (e *Error) Code() int {
 		return http.StatusBadRequest
 	case errors.Is(e.appErr, ErrValidation):
 		return http.StatusBadRequest
+	case errors.Is(e.appErr, ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	case errors.Is(e.appErr, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(e.appErr, ErrUnprocessable):
+		return http.StatusUnprocessableEntity
+	case errors.Is(e.appErr, ErrUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(e.appErr, ErrNotAcceptable):
+		return http.StatusNotAcceptable
+	case errors.Is(e.appErr, ErrUnsupportedMedia):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(e.appErr, ErrPayloadTooLarge):
+		return http.StatusRequestEntityTooLarge
 	default:
 		return http.StatusInternalServerError
 	}
 }
+
+// ErrorCode returns the machine-readable code of the Error. When no code was set,
+// a generic code is derived from the application-level error.
+func (e *Error) ErrorCode() string {
+	if e.code != "" {
+		return e.code
+	}
+
+	switch {
+	case errors.Is(e.appErr, ErrBadRequest):
+		return "BAD_REQUEST"
+	case errors.Is(e.appErr, ErrJWTInternalError):
+		return "JWT_INTERNAL_ERROR"
+	case errors.Is(e.appErr, ErrNotFound):
+		return "NOT_FOUND"
+	case errors.Is(e.appErr, ErrUnauthorized):
+		return "UNAUTHORIZED"
+	case errors.Is(e.appErr, ErrInvalidToken):
+		return "INVALID_TOKEN"
+	case errors.Is(e.appErr, ErrExpiredToken):
+		return "EXPIRED_TOKEN"
+	case errors.Is(e.appErr, ErrStolenToken):
+		return "STOLEN_TOKEN"
+	case errors.Is(e.appErr, ErrInvalidObjectID):
+		return "INVALID_OBJECT_ID"
+	case errors.Is(e.appErr, ErrValidation):
+		return "VALIDATION_ERROR"
+	case errors.Is(e.appErr, ErrTooManyRequests):
+		return "TOO_MANY_REQUESTS"
+	case errors.Is(e.appErr, ErrConflict):
+		return "CONFLICT"
+	case errors.Is(e.appErr, ErrUnprocessable):
+		return "UNPROCESSABLE_ENTITY"
+	case errors.Is(e.appErr, ErrUnavailable):
+		return "SERVICE_UNAVAILABLE"
+	case errors.Is(e.appErr, ErrNotAcceptable):
+		return "NOT_ACCEPTABLE"
+	case errors.Is(e.appErr, ErrUnsupportedMedia):
+		return "UNSUPPORTED_MEDIA_TYPE"
+	case errors.Is(e.appErr, ErrPayloadTooLarge):
+		return "PAYLOAD_TOO_LARGE"
+	default:
+		return "INTERNAL_ERROR"
+	}
+}
+
+// Detail returns the human-readable detail of the Error, falling back to the
+// service-level error.
+func (e *Error) Detail() string {
+	if e.detail != "" {
+		return e.detail
+	}
+	return e.ServiceErr()
+}
+
+// Violations returns the field-level violations associated with the Error.
+func (e *Error) Violations() []Violation {
+	return e.violations
+}
@@ -0,0 +1,262 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"github.com/ugorji/go/codec"
+)
+
+// Media types of the formats the responses are rendered in and the request bodies
+// are bound from.
+const (
+	MIMEJSON    = "application/json"
+	MIMEMsgPack = "application/msgpack"
+	MIMECBOR    = "application/cbor"
+)
+
+// format is a media type the API speaks, along with its binding and renderer.
+type format struct {
+	contentType string
+	// aliases are the other media types accepted for the format.
+	aliases []string
+	binding binding.Binding
+	render  func(data any) render.Render
+}
+
+// formats are the supported formats, in order of preference. JSON comes first,
+// being the format of the requests and responses that do not specify any.
+var formats = []*format{
+	{
+		contentType: MIMEJSON,
+		aliases:     []string{ProblemContentType},
+		binding:     binding.JSON,
+		render: func(data any) render.Render {
+			return render.JSON{Data: data}
+		},
+	},
+	newCodecFormat(MIMEMsgPack, []string{"application/x-msgpack", "application/vnd.msgpack"},
+		&codec.MsgpackHandle{WriteExt: true, BasicHandle: codecBasicHandle()}),
+	newCodecFormat(MIMECBOR, nil, &codec.CborHandle{BasicHandle: codecBasicHandle()}),
+}
+
+// MediaTypes returns the media types of the supported formats, JSON first.
+func MediaTypes() []string {
+	types := make([]string, len(formats))
+	for i, f := range formats {
+		types[i] = f.contentType
+	}
+	return types
+}
+
+// codecBasicHandle returns the options shared by the binary formats: the fields
+// are named by their json tags, as in JSON, and maps decode with string keys.
+func codecBasicHandle() codec.BasicHandle {
+	h := codec.BasicHandle{TypeInfos: codec.NewTypeInfos([]string{"json"})}
+	h.MapType = reflect.TypeOf(map[string]any(nil))
+	return h
+}
+
+// newCodecFormat returns a format encoded and decoded by a ugorji codec handle.
+func newCodecFormat(contentType string, aliases []string, h codec.Handle) *format {
+	return &format{
+		contentType: contentType,
+		aliases:     aliases,
+		binding:     codecBinding{name: contentType, handle: h},
+		render: func(data any) render.Render {
+			return codecRender{contentType: contentType, handle: h, data: data}
+		},
+	}
+}
+
+// is reports whether mediaType names the format.
+func (f *format) is(mediaType string) bool {
+	if mediaType == f.contentType {
+		return true
+	}
+	for _, alias := range f.aliases {
+		if mediaType == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// quality returns the quality given to the format by the media ranges of an
+// Accept header, the most specific matching range applying.
+func (f *format) quality(ranges []mediaRange) float64 {
+	quality, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case f.is(r.mediaType):
+			s = 2
+		case strings.HasSuffix(r.mediaType, "/*") &&
+			strings.HasPrefix(f.contentType, strings.TrimSuffix(r.mediaType, "*")):
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			quality, specificity = r.quality, s
+		}
+	}
+	return quality
+}
+
+// mediaRange is a media range of an Accept header with its quality.
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept parses the media ranges of an Accept header.
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		r := mediaRange{
+			mediaType: strings.ToLower(strings.TrimSpace(params[0])),
+			quality:   1,
+		}
+		if r.mediaType == "" {
+			continue
+		}
+		for _, param := range params[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.TrimSpace(name) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				r.quality = q
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// negotiate returns the format preferred by the Accept header of the request, JSON
+// when there is none. It returns false when none of the formats is acceptable.
+func negotiate(c *gin.Context) (*format, bool) {
+	accept := ""
+	if c.Request != nil {
+		accept = c.GetHeader("Accept")
+	}
+	if strings.TrimSpace(accept) == "" {
+		return formats[0], true
+	}
+
+	ranges := parseAccept(accept)
+	var best *format
+	bestQuality := 0.0
+	for _, f := range formats {
+		if q := f.quality(ranges); q > bestQuality {
+			best, bestQuality = f, q
+		}
+	}
+	return best, best != nil
+}
+
+// Acceptable returns a middleware answering 406 to the requests accepting none of
+// the formats, before any handler does any work. The responses of the requests it
+// lets through are rendered in the negotiated format.
+func Acceptable() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := negotiate(c); !ok {
+			ErrorResponse(c, errNotAcceptable(c))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// errNotAcceptable returns the error of a request accepting none of the formats.
+func errNotAcceptable(c *gin.Context) *Error {
+	return NewError(ErrNotAcceptable, fmt.Errorf(
+		"cannot render %q, expected one of %s",
+		c.GetHeader("Accept"), strings.Join(MediaTypes(), ", "),
+	))
+}
+
+// requestFormat returns the format of the request body by its Content-Type, JSON
+// when there is none.
+func requestFormat(c *gin.Context) (*format, *Error) {
+	contentType := c.ContentType()
+	if contentType == "" {
+		return formats[0], nil
+	}
+	for _, f := range formats {
+		if f.is(strings.ToLower(contentType)) {
+			return f, nil
+		}
+	}
+	return nil, NewError(ErrUnsupportedMedia, fmt.Errorf(
+		"cannot bind %q, expected one of %s",
+		contentType, strings.Join(MediaTypes(), ", "),
+	))
+}
+
+// renderBody writes body with the given status in the format negotiated from the
+// Accept header. Bodies that are not acceptable, such as the errors answering
+// those requests, are written in JSON.
+func renderBody(c *gin.Context, status int, body any) {
+	f, ok := negotiate(c)
+	if !ok {
+		f = formats[0]
+	}
+	c.Header("Vary", "Accept")
+	c.Render(status, f.render(body))
+}
+
+// codecBinding binds the request bodies encoded by a ugorji codec handle.
+type codecBinding struct {
+	name   string
+	handle codec.Handle
+}
+
+// Name implements binding.Binding.
+func (b codecBinding) Name() string {
+	return b.name
+}
+
+// Bind implements binding.Binding.
+func (b codecBinding) Bind(req *http.Request, obj any) error {
+	if err := codec.NewDecoder(req.Body, b.handle).Decode(obj); err != nil {
+		return err
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// codecRender renders data encoded by a ugorji codec handle.
+type codecRender struct {
+	contentType string
+	handle      codec.Handle
+	data        any
+}
+
+// Render implements render.Render.
+func (r codecRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return codec.NewEncoder(w, r.handle).Encode(r.data)
+}
+
+// WriteContentType implements render.Render.
+func (r codecRender) WriteContentType(w http.ResponseWriter) {
+	header := w.Header()
+	if val := header["Content-Type"]; len(val) == 0 {
+		header["Content-Type"] = []string{r.contentType}
+	}
+}
@@ -0,0 +1,257 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/fieldset"
+)
+
+// ErrCodeInvalidFields is the code of the errors answering an invalid selection of
+// fields.
+const ErrCodeInvalidFields = "INVALID_FIELDS"
+
+// describeKey is the context key asking a handler built by Handle for its
+// description, see Describe.
+const describeKey = "response.describe"
+
+// handleName is the name of the functions of the handlers built by Handle.
+var handleName = funcName(Handle(func(context.Context, struct{}) (any, *Error) { return nil, nil }))
+
+// HandlerDescription describes the requests and the responses of a handler built
+// by Handle.
+type HandlerDescription struct {
+	// Request is the type the requests are bound into.
+	Request reflect.Type
+	// Response is the type of the body of the successful responses, a TDataResponse.
+	Response reflect.Type
+	// Status is the status of the successful responses.
+	Status int
+}
+
+// Describe returns the description of a handler built by Handle, for its
+// documentation to be checked against it. The other handlers are not called and
+// have no description.
+func Describe(handler gin.HandlerFunc) (HandlerDescription, bool) {
+	var desc HandlerDescription
+	if funcName(handler) != handleName {
+		return desc, false
+	}
+	c := &gin.Context{}
+	c.Set(describeKey, &desc)
+	handler(c)
+	return desc, desc.Response != nil
+}
+
+// funcName returns the name of the function of a handler, without the type
+// arguments of the generic functions.
+func funcName(handler gin.HandlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	return strings.Replace(name, "[...]", "", 1)
+}
+
+// Validatable is implemented by the requests validating themselves, such as the DTOs.
+type Validatable interface {
+	Validate(v *validator.Validate) error
+}
+
+// HandleOption customizes the handlers built by Handle.
+type HandleOption func(o *handleOptions)
+
+type handleOptions struct {
+	status   int
+	message  string
+	validate *validator.Validate
+	fields   bool
+}
+
+// WithStatus sets the status of the successful responses, 200 by default.
+func WithStatus(status int) HandleOption {
+	return func(o *handleOptions) {
+		o.status = status
+	}
+}
+
+// WithMessage sets the message of the successful responses.
+func WithMessage(message string) HandleOption {
+	return func(o *handleOptions) {
+		o.message = message
+	}
+}
+
+// WithValidator sets the validator of the requests, required by the Validatable ones.
+func WithValidator(validate *validator.Validate) HandleOption {
+	return func(o *handleOptions) {
+		o.validate = validate
+	}
+}
+
+// WithFields lets the clients select the fields of the data with the fields query
+// parameter, see package fieldset. The selection is carried by the context given
+// to the handler, for the repositories to read only the selected fields.
+func WithFields() HandleOption {
+	return func(o *handleOptions) {
+		o.fields = true
+	}
+}
+
+// Handle adapts fn into a gin handler. The request is bound from the body of POST,
+// PUT and PATCH requests in the format of its Content-Type, then from the query and
+// path parameters by the form and uri tags, and validated when Validatable. The
+// data returned by fn, or its selected fields, is rendered in a TDataResponse, and
+// errors with ErrorResponse. Bodies in an unsupported format get 415 before fn
+// runs, as requests accepting none of the formats get 406 from the Acceptable
+// middleware.
+func Handle[Req, Res any](
+	fn func(ctx context.Context, req Req) (Res, *Error),
+	opts ...HandleOption,
+) gin.HandlerFunc {
+	o := handleOptions{status: http.StatusOK}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var zero Req
+	if _, ok := any(&zero).(Validatable); ok && o.validate == nil {
+		panic("response: Handle needs a validator for Validatable requests")
+	}
+
+	return func(c *gin.Context) {
+		if desc, ok := c.Get(describeKey); ok {
+			*desc.(*HandlerDescription) = HandlerDescription{
+				Request:  reflect.TypeFor[Req](),
+				Response: reflect.TypeFor[TDataResponse[Res]](),
+				Status:   o.status,
+			}
+			return
+		}
+
+		var req Req
+		if err := bind(c, &req); err != nil {
+			var appErr *Error
+			if errors.As(err, &appErr) {
+				ErrorResponse(c, appErr)
+				return
+			}
+			ValidateErrorResponse(c, err)
+			return
+		}
+
+		if v, ok := any(&req).(Validatable); ok {
+			if err := v.Validate(o.validate); err != nil {
+				ValidateErrorResponse(c, err)
+				return
+			}
+		}
+
+		ctx := context.Context(c)
+		var fields fieldset.Set
+		if o.fields {
+			var err *Error
+			if fields, err = selectFields[Res](c); err != nil {
+				ErrorResponse(c, err)
+				return
+			}
+			ctx = fieldset.NewContext(ctx, fields)
+		}
+
+		res, err := fn(ctx, req)
+		if err != nil {
+			ErrorResponse(c, err)
+			return
+		}
+		DataResponse(c, o.status, o.message, fields.Filter(res))
+	}
+}
+
+// selectFields returns the fields of Res selected by the fields query parameter.
+func selectFields[Res any](c *gin.Context) (fieldset.Set, *Error) {
+	fields, err := fieldset.Parse(c.Query(fieldset.Param))
+	if err == nil {
+		err = fields.Validate(reflect.TypeFor[Res]())
+	}
+	if err != nil {
+		return nil, NewError(ErrBadRequest, err).WithCode(ErrCodeInvalidFields)
+	}
+	return fields, nil
+}
+
+// bind fills req from the body, the query and the path of the request, the path
+// parameters taking precedence. Bodies in an unsupported format return an *Error.
+// Along with a body, only the fields tagged with form are bound from the query:
+// gin binds the untagged fields by their name, which would let the query override
+// the fields of the body.
+func bind(c *gin.Context, req any) error {
+	hasBody := false
+	switch c.Request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		f, appErr := requestFormat(c)
+		if appErr != nil {
+			return appErr
+		}
+		if err := c.ShouldBindWith(req, f.binding); err != nil {
+			return err
+		}
+		hasBody = true
+	}
+	if len(c.Request.URL.RawQuery) > 0 {
+		query := c.Request.URL.Query()
+		if hasBody {
+			query = taggedQuery(query, reflect.TypeOf(req))
+		}
+		if err := binding.MapFormWithTag(req, query, "form"); err != nil {
+			return err
+		}
+		if err := binding.Validator.ValidateStruct(req); err != nil {
+			return err
+		}
+	}
+	if len(c.Params) > 0 {
+		return c.ShouldBindUri(req)
+	}
+	return nil
+}
+
+// taggedQuery returns the parameters of the query named by the form tag of a
+// field of t.
+func taggedQuery(query url.Values, t reflect.Type) url.Values {
+	names := map[string]bool{}
+	formTags(t, names)
+
+	tagged := url.Values{}
+	for name, values := range query {
+		if names[name] {
+			tagged[name] = values
+		}
+	}
+	return tagged
+}
+
+// formTags adds the names of the form tags of the fields of t, and of its nested
+// structs, to names.
+func formTags(t reflect.Type, names map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		switch {
+		case name == "-":
+		case name != "":
+			names[name] = true
+		case field.Type.Kind() == reflect.Struct:
+			formTags(field.Type, names)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package response
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// ErrorFormat selects how errors are rendered by default.
+type ErrorFormat string
+
+const (
+	// EnvelopeFormat renders errors with the TErrResponse envelope.
+	EnvelopeFormat ErrorFormat = "envelope"
+	// ProblemFormat renders errors as RFC 7807 problem details.
+	ProblemFormat ErrorFormat = "problem"
+)
+
+// problemByDefault tells whether errors are rendered as problem details by
+// default, clients may still ask for them through the Accept header.
+var problemByDefault atomic.Bool
+
+// SetErrorFormat sets the default format used to render errors. It may be called
+// while requests are served.
+func SetErrorFormat(format ErrorFormat) {
+	problemByDefault.Store(format == ProblemFormat)
+}
+
+// ProblemDetails is an RFC 7807 problem details object, extended with the
+// machine-readable error code and the field-level violations.
+type ProblemDetails struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int         `json:"status"`
+	Detail   string      `json:"detail,omitempty"`
+	Instance string      `json:"instance,omitempty"`
+	Code     string      `json:"code"`
+	Errors   []Violation `json:"errors,omitempty"`
+}
+
+// NewProblemDetails builds the problem details of the given error.
+func NewProblemDetails(err *Error, instance string) ProblemDetails {
+	return ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(err.Code()),
+		Status:   err.Code(),
+		Detail:   err.Detail(),
+		Instance: instance,
+		Code:     err.ErrorCode(),
+		Errors:   err.Violations(),
+	}
+}
+
+// ProblemResponse writes the error to the provided gin.Context as application/problem+json.
+func ProblemResponse(c *gin.Context, err *Error) {
+	var instance string
+	if c.Request != nil {
+		instance = c.Request.URL.Path
+	}
+
+	// gin keeps an explicitly set content type when rendering JSON.
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(err.Code(), NewProblemDetails(err, instance))
+}
+
+// wantsProblem reports whether the error should be rendered as problem details,
+// either because it is the configured format or because the client asked for it.
+func wantsProblem(c *gin.Context) bool {
+	if problemByDefault.Load() {
+		return true
+	}
+	if c.Request == nil {
+		return false
+	}
+	return strings.Contains(c.GetHeader("Accept"), ProblemContentType)
+}
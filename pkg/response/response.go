@@ -1,12 +1,9 @@
 package response
 
 import (
-	"errors"
-	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 // TResponse is a struct that represents a basic response from the API. It contains
@@ -18,92 +15,91 @@ type TResponse struct {
 }
 
 // TDataResponse is a response type that includes a data field. It embeds the TResponse
-// struct and adds a Data field to hold the response data.
-type TDataResponse struct {
+// struct and adds a Data field to hold the response data of type T.
+type TDataResponse[T any] struct {
 	TResponse
-	Data interface{} `json:"data"`
+	Data T `json:"data"`
 }
 
 // TErrResponse is a response type that includes an error field. It embeds the TResponse
-// struct and adds an Errors field to hold any errors that occurred.
-type TErrResponse struct {
+// struct and adds an Errors field to hold any errors that occurred, along with the
+// machine-readable code of the error. Errors are either the message of the service
+// error, a string, or the field-level violations, a []Violation.
+type TErrResponse[E any] struct {
 	TResponse
-	Errors interface{} `json:"errors"`
+	ErrorCode string `json:"error_code,omitempty"`
+	Errors    E      `json:"errors"`
 }
 
 // OkResponse is a helper function that writes a JSON response to the provided gin.Context
 // with an HTTP status of http.StatusOK. The response includes the provided message and
 // the provided data.
 func OkResponse(c *gin.Context, msg string, data interface{}) {
-	c.JSON(http.StatusOK, TDataResponse{
-		TResponse: TResponse{
-			Code:    http.StatusOK,
-			Message: msg,
-		},
-		Data: data,
-	})
+	DataResponse(c, http.StatusOK, msg, data)
 }
 
 // CreatedResponse is a helper function that writes a JSON response to the provided gin.Context
 // with an HTTP status of http.StatusCreated. The response includes the provided message and
 // the provided data.
 func CreatedResponse(c *gin.Context, msg string, data interface{}) {
-	c.JSON(http.StatusCreated, TDataResponse{
+	DataResponse(c, http.StatusCreated, msg, data)
+}
+
+// DataResponse writes a response to the provided gin.Context with the given HTTP
+// status, in the format negotiated from the Accept header. The response includes
+// the provided message and the provided data. Requests accepting none of the
+// formats are answered 406 by the Acceptable middleware up front.
+func DataResponse[T any](c *gin.Context, status int, msg string, data T) {
+	renderBody(c, status, TDataResponse[T]{
 		TResponse: TResponse{
-			Code:    http.StatusCreated,
+			Code:    status,
 			Message: msg,
 		},
 		Data: data,
 	})
 }
 
-// ErrorResponse writes a JSON response to the provided gin.Context with an HTTP status
-// corresponding to the error code. The response includes the error message.
+// ErrorResponse writes a response to the provided gin.Context with an HTTP status
+// corresponding to the error code, in the format negotiated from the Accept header.
+// The response includes the error message, or the field-level violations when the
+// error has any. When problem details are selected, the error is rendered as
+// application/problem+json instead.
 func ErrorResponse(c *gin.Context, err *Error) {
-	c.JSON(err.Code(), TErrResponse{
-		TResponse: TResponse{
-			Code:    err.Code(),
-			Message: err.AppErr(),
-		},
-		Errors: err.ServiceErr(),
-	})
+	if wantsProblem(c) {
+		ProblemResponse(c, err)
+		return
+	}
+
+	if violations := err.Violations(); len(violations) > 0 {
+		renderBody(c, err.Code(), newErrResponse(err, violations))
+		return
+	}
+	renderBody(c, err.Code(), newErrResponse(err, err.ServiceErr()))
 }
 
-// ValidateErrorResponse writes a JSON response to the provided gin.Context with an HTTP status
+// ValidateErrorResponse writes a response to the provided gin.Context with an HTTP status
 // corresponding to the error code. The response includes the error message and a list of
-// validation error messages, if any.
+// field-level violations, if any, with messages in the language requested by the client.
 func ValidateErrorResponse(c *gin.Context, err error) {
-	errValidation := NewError(ErrValidation, err)
-	c.JSON(errValidation.Code(), TErrResponse{
-		TResponse: TResponse{
-			Code:    errValidation.Code(),
-			Message: errValidation.AppErr(),
-		},
-		Errors: validationErrorsToJSON(err),
-	})
-}
+	errValidation := NewError(ErrValidation, err).
+		WithDetail("one or more fields are invalid").
+		WithViolations(validationViolations(c, err)...)
+	if wantsProblem(c) {
+		ProblemResponse(c, errValidation)
+		return
+	}
 
-// validationErrorsToJSON is a helper function that converts a validator.ValidationErrors
-// error to a slice of strings containing the error messages. It handles both "required"
-// and other validation error types.
-func validationErrorsToJSON(err error) []string {
-	var res []string
+	renderBody(c, errValidation.Code(), newErrResponse(errValidation, errValidation.Violations()))
+}
 
-	// Check if it's a validation error
-	var validationErrs validator.ValidationErrors
-	if errors.As(err, &validationErrs) {
-		for _, fieldErr := range validationErrs {
-			// Collect error messages into a list
-			if fieldErr.Tag() == "required" {
-				res = append(res, fmt.Sprintf("Field %s is required", fieldErr.Field()))
-			} else {
-				res = append(
-					res,
-					fmt.Sprintf("Fields %s is invalid due to %s", fieldErr.Field(), fieldErr.Tag()),
-				)
-			}
-		}
+// newErrResponse builds the envelope of an error along with its errors.
+func newErrResponse[E any](err *Error, errs E) TErrResponse[E] {
+	return TErrResponse[E]{
+		TResponse: TResponse{
+			Code:    err.Code(),
+			Message: err.AppErr(),
+		},
+		ErrorCode: err.ErrorCode(),
+		Errors:    errs,
 	}
-
-	return res
 }
@@ -0,0 +1,124 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// translator translates validation messages, when nil messages are in English.
+var translator *ut.UniversalTranslator
+
+// SetTranslator sets the translator used for validation messages.
+func SetTranslator(uni *ut.UniversalTranslator) {
+	translator = uni
+}
+
+// validationViolations converts a validator.ValidationErrors error to field-level
+// violations, translated into the language requested by the client.
+func validationViolations(c *gin.Context, err error) []Violation {
+	return ValidationViolations(err, acceptedLanguages(c)...)
+}
+
+// ValidationViolations converts a validator.ValidationErrors error to field-level
+// violations, translated into the first of the languages having a translator, in
+// English by default. Other errors have no violations.
+func ValidationViolations(err error, languages ...string) []Violation {
+	var res []Violation
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return res
+	}
+
+	var trans ut.Translator
+	if translator != nil {
+		trans, _ = translator.FindTranslator(languages...)
+	}
+
+	for _, fieldErr := range validationErrs {
+		violation := Violation{
+			Field:   fieldPath(fieldErr),
+			Rule:    fieldErr.Tag(),
+			Params:  strings.Fields(fieldErr.Param()),
+			Message: validationMessage(fieldErr),
+		}
+		// Translate falls back to the raw error when the validator of the
+		// error has no message registered for the tag.
+		if trans != nil {
+			if msg := fieldErr.Translate(trans); msg != fieldErr.Error() {
+				violation.Message = msg
+			}
+		}
+		res = append(res, violation)
+	}
+
+	return res
+}
+
+// fieldPath returns the path of the field without the name of the validated struct,
+// e.g. "address.city" for "CreateUserDto.address.city".
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// validationMessage returns the untranslated message describing a single field error.
+func validationMessage(fieldErr validator.FieldError) string {
+	if fieldErr.Tag() == "required" {
+		return fmt.Sprintf("Field %s is required", fieldErr.Field())
+	}
+	return fmt.Sprintf("Fields %s is invalid due to %s", fieldErr.Field(), fieldErr.Tag())
+}
+
+// acceptedLanguages parses the Accept-Language header into locales ordered by
+// preference. Regional locales are followed by their base language,
+// e.g. "vi-VN" yields "vi_vn" and "vi".
+func acceptedLanguages(c *gin.Context) []string {
+	if c.Request == nil {
+		return nil
+	}
+
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	var languages []language
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = value
+			}
+		}
+		languages = append(languages, language{tag: tag, quality: quality})
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	var res []string
+	for _, lang := range languages {
+		locale := strings.ToLower(strings.ReplaceAll(lang.tag, "-", "_"))
+		res = append(res, locale)
+		if base, _, ok := strings.Cut(locale, "_"); ok {
+			res = append(res, base)
+		}
+	}
+	return res
+}
@@ -0,0 +1,94 @@
+package setting
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Subscriber is notified with the previous and the new configuration after a change.
+type Subscriber func(old, current *Config)
+
+// staticFields lists the settings that can't change at runtime, keyed by their
+// configuration path. Changes to them are rejected until the next restart.
+var staticFields = []struct {
+	key   string
+	field func(c *Config) any
+}{
+	{"server_config.port", func(c *Config) any { return &c.Server.Port }},
+	{"logger_config.file_name", func(c *Config) any { return &c.Logger.FileName }},
+	{"logger_config.service_name", func(c *Config) any { return &c.Logger.ServiceName }},
+	{"logger_config.instance_id", func(c *Config) any { return &c.Logger.InstanceID }},
+	{"logger_config.console", func(c *Config) any { return &c.Logger.Console }},
+	{"logger_config.file", func(c *Config) any { return &c.Logger.File }},
+	{"logger_config.sampling", func(c *Config) any { return &c.Logger.Sampling }},
+	{"logger_config.redact_fields", func(c *Config) any { return &c.Logger.RedactFields }},
+	{"mongo_config", func(c *Config) any { return &c.MongoDB }},
+	{"redis_config", func(c *Config) any { return &c.Redis }},
+	{"rate_limit_config.enabled", func(c *Config) any { return &c.RateLimit.Enabled }},
+	{"rate_limit_config.store", func(c *Config) any { return &c.RateLimit.Store }},
+	{"password_hash_config", func(c *Config) any { return &c.Hash }},
+	{"jobs_config", func(c *Config) any { return &c.Jobs }},
+}
+
+// Manager holds the live configuration. Updates are validated, swapped atomically
+// and then published to the registered subscribers.
+type Manager struct {
+	current     atomic.Pointer[Config]
+	mu          sync.Mutex
+	subscribers []Subscriber
+}
+
+// NewManager creates a new instance of Manager holding the initial configuration.
+func NewManager(initial Config) *Manager {
+	m := &Manager{}
+	m.current.Store(&initial)
+	return m
+}
+
+// Current returns the live configuration. It must be treated as read-only.
+func (m *Manager) Current() *Config {
+	return m.current.Load()
+}
+
+// Subscribe registers a function called after every configuration change.
+func (m *Manager) Subscribe(fn Subscriber) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, fn)
+}
+
+// Update validates the next configuration and makes it the live one. Changes to
+// settings that can't change at runtime are reverted and reported as warnings.
+// An invalid configuration is rejected as a whole.
+func (m *Manager) Update(next Config) ([]string, error) {
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old := m.current.Load()
+
+	var warnings []string
+	for _, static := range staticFields {
+		oldValue := reflect.ValueOf(static.field(old)).Elem()
+		nextValue := reflect.ValueOf(static.field(&next)).Elem()
+		if !reflect.DeepEqual(oldValue.Interface(), nextValue.Interface()) {
+			nextValue.Set(oldValue)
+			warnings = append(warnings, fmt.Sprintf(
+				"%s can't change at runtime, restart the application to apply it",
+				static.key,
+			))
+		}
+	}
+
+	m.current.Store(&next)
+	for _, subscriber := range m.subscribers {
+		subscriber(old, &next)
+	}
+
+	return warnings, nil
+}
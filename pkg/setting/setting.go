@@ -1,36 +1,213 @@
 package setting
 
+import "time"
+
 // Config represents the application's configuration structure for server settings.
+// Fields may declare a default value with the `default` tag and validation rules
+// with the `validate` tag, both applied when the configuration is loaded.
 type Config struct {
-	Server  ServerSettings  `mapstructure:"server_config"`
-	Logger  LoggerSettings  `mapstructure:"logger_config"`
-	MongoDB MongoDBSettings `mapstructure:"mongo_config"`
+	Server      ServerSettings         `mapstructure:"server_config"`
+	Logger      LoggerSettings         `mapstructure:"logger_config"`
+	MongoDB     MongoDBSettings        `mapstructure:"mongo_config"`
+	Redis       RedisSettings          `mapstructure:"redis_config"`
+	RateLimit   RateLimitSettings      `mapstructure:"rate_limit_config"`
+	Idempotency IdempotencySettings    `mapstructure:"idempotency_config"`
+	Password    PasswordPolicySettings `mapstructure:"password_policy_config"`
+	Hash        PasswordHashSettings   `mapstructure:"password_hash_config"`
+	Admin       AdminSettings          `mapstructure:"admin_config"`
+	Staff       StaffSettings          `mapstructure:"staff_config"`
+	Jobs        JobsSettings           `mapstructure:"jobs_config"`
 }
 
 // ServerSettings defines the configuration settings for a server,
-// including the port it operates on.
+// including the port it operates on and the default error format,
+// either "envelope" or "problem" (RFC 7807).
 type ServerSettings struct {
-	Port string `mapstructure:"port"`
+	Port        string `mapstructure:"port" default:"8080" validate:"required,port"`
+	ErrorFormat string `mapstructure:"error_format" default:"envelope" validate:"oneof=envelope problem"` //nolint:lll
 }
 
 // LoggerSettings is a configuration structure for setting up logging behavior and file management.
+// Lines go to the console and, when FileName is set, to a rotated file. Modules maps
+// the name of a module logger to its own level, overriding Level. ServiceName and
+// InstanceID are added to every line, InstanceID defaulting to the host name.
+// The values of the fields whose name contains one of RedactFields are hidden,
+// a default list of sensitive names being used when it is empty.
 type LoggerSettings struct {
-	FileName   string `mapstructure:"file_name"`
-	Level      string `mapstructure:"log_level"`
-	MaxSize    int    `mapstructure:"max_size"`
-	MaxBackups int    `mapstructure:"max_backups"`
-	MaxAge     int    `mapstructure:"max_age"`
-	Compress   bool   `mapstructure:"compress"`
+	FileName     string              `mapstructure:"file_name" default:"./logs/app.log"`
+	Level        string              `mapstructure:"log_level" default:"info" validate:"oneof=debug info warn error"` //nolint:lll
+	MaxSize      int                 `mapstructure:"max_size" default:"500" validate:"gt=0"`
+	MaxBackups   int                 `mapstructure:"max_backups" default:"3" validate:"gte=0"`
+	MaxAge       int                 `mapstructure:"max_age" default:"28" validate:"gte=0"`
+	Compress     bool                `mapstructure:"compress"`
+	ServiceName  string              `mapstructure:"service_name" default:"gin-boilerplate"`
+	InstanceID   string              `mapstructure:"instance_id"`
+	Console      LogOutputSettings   `mapstructure:"console"`
+	File         LogOutputSettings   `mapstructure:"file"`
+	Sampling     LogSamplingSettings `mapstructure:"sampling"`
+	Modules      map[string]string   `mapstructure:"modules" validate:"dive,oneof=debug info warn error"` //nolint:lll
+	RedactFields []string            `mapstructure:"redact_fields"`
+}
+
+// LogOutputSettings defines the minimum level and the format, "json" or "console",
+// of a log output. An empty level writes every line the logger lets through, by
+// its level or by the level of the module logging it, and an empty format falls
+// back to the format suited to the output: console for the terminal, json for the
+// file.
+type LogOutputSettings struct {
+	Level  string `mapstructure:"log_level" validate:"omitempty,oneof=debug info warn error"`
+	Format string `mapstructure:"format" validate:"omitempty,oneof=json console"`
+}
+
+// LogSamplingSettings limits the volume of repeated log lines: every Tick, the
+// first Initial lines with the same level and message are logged, then only one
+// line out of Thereafter.
+type LogSamplingSettings struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Tick       time.Duration `mapstructure:"tick" default:"1s" validate:"gt=0"`
+	Initial    int           `mapstructure:"initial" default:"100" validate:"gt=0"`
+	Thereafter int           `mapstructure:"thereafter" default:"100" validate:"gt=0"`
 }
 
-// MongoDBSettings defines the configuration settings for mongoDB
+// MongoDBSettings defines the configuration settings for mongoDB.
+// URI, when set, is used as the connection string as is, for example an Atlas
+// "mongodb+srv://" URI. Otherwise the connection string is built from Host and
+// Port, using the "mongodb+srv" scheme when SRV is set. The remaining fields
+// apply in both cases and override the options of the URI when set.
+// At startup, a failed connection is retried ConnectRetries times, waiting
+// RetryBackoff between attempts, doubled after each one up to MaxRetryBackoff.
 type MongoDBSettings struct {
-	Host             string `mapstructure:"host"`
-	Port             string `mapstructure:"port"`
-	Username         string `mapstructure:"username"`
-	Password         string `mapstructure:"password"`
-	Database         string `mapstructure:"database"`
-	MaxPoolSize      uint64 `mapstructure:"max_pool_size"`
-	EnableLog        bool   `mapstructure:"enable_log"`
-	DirectConnection bool   `mapstructure:"direct_connection"`
+	URI              string             `mapstructure:"uri"`
+	Host             string             `mapstructure:"host" default:"localhost" validate:"required_without=URI"` //nolint:lll
+	Port             string             `mapstructure:"port" default:"27017" validate:"omitempty,port"`
+	SRV              bool               `mapstructure:"srv"`
+	Username         string             `mapstructure:"username"`
+	Password         string             `mapstructure:"password"`
+	AuthSource       string             `mapstructure:"auth_source"`
+	Database         string             `mapstructure:"database" validate:"required"`
+	ReplicaSet       string             `mapstructure:"replica_set"`
+	ReadPreference   string             `mapstructure:"read_preference" validate:"omitempty,oneof=primary primaryPreferred secondary secondaryPreferred nearest"` //nolint:lll
+	WriteConcern     string             `mapstructure:"write_concern"`
+	TLS              MongoDBTLSSettings `mapstructure:"tls"`
+	MaxPoolSize      uint64             `mapstructure:"max_pool_size" default:"100" validate:"gt=0"`
+	ConnectTimeout   time.Duration      `mapstructure:"connect_timeout" default:"10s" validate:"gt=0"`
+	ConnectRetries   int                `mapstructure:"connect_retries" default:"5" validate:"gte=0"`
+	RetryBackoff     time.Duration      `mapstructure:"retry_backoff" default:"1s" validate:"gt=0"`
+	MaxRetryBackoff  time.Duration      `mapstructure:"max_retry_backoff" default:"30s" validate:"gtefield=RetryBackoff"` //nolint:lll
+	EnableLog        bool               `mapstructure:"enable_log"`
+	DirectConnection bool               `mapstructure:"direct_connection"`
+	SlowQuery        SlowQuerySettings  `mapstructure:"slow_query"`
+}
+
+// SlowQuerySettings defines how slow MongoDB commands are detected. Commands
+// running longer than Threshold are logged and counted, a zero Threshold
+// disabling the detection. Outside of production, Explain runs explain on the
+// slow queries to flag those scanning whole collections.
+type SlowQuerySettings struct {
+	Threshold time.Duration `mapstructure:"threshold" default:"100ms" validate:"gte=0"`
+	Explain   bool          `mapstructure:"explain"`
+}
+
+// MongoDBTLSSettings defines the TLS settings of the MongoDB connection. CAFile
+// verifies the server certificate, CertFile and KeyFile hold the client
+// certificate for X.509 authentication.
+type MongoDBTLSSettings struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"ca_file"`
+	CertFile           string `mapstructure:"cert_file" validate:"required_with=KeyFile"`
+	KeyFile            string `mapstructure:"key_file" validate:"required_with=CertFile"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+// RedisSettings defines the connection settings for a Redis compatible server.
+type RedisSettings struct {
+	Addr     string `mapstructure:"addr" default:"localhost:6379" validate:"hostname_port"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db" validate:"gte=0"`
+}
+
+// RateLimitSettings defines the rate limiting behavior.
+// Store selects the backend counting the requests, either "memory" or "redis".
+// Groups maps a route group name to the rule applied to it.
+type RateLimitSettings struct {
+	Enabled bool                     `mapstructure:"enabled"`
+	Store   string                   `mapstructure:"store" default:"memory" validate:"oneof=memory redis"` //nolint:lll
+	Groups  map[string]RateLimitRule `mapstructure:"groups" validate:"dive"`
+}
+
+// RateLimitRule defines how many requests are allowed per window for a route group.
+// KeyBy selects what a client is identified by: "ip", "user" or "api_key".
+type RateLimitRule struct {
+	Limit  int           `mapstructure:"limit" validate:"gt=0"`
+	Window time.Duration `mapstructure:"window" validate:"gt=0"`
+	KeyBy  string        `mapstructure:"key_by" validate:"omitempty,oneof=ip user api_key"`
+}
+
+// IdempotencySettings defines how long responses of requests made with an
+// Idempotency-Key are kept for replay.
+type IdempotencySettings struct {
+	TTL time.Duration `mapstructure:"ttl" default:"24h" validate:"gt=0"`
+}
+
+// PasswordPolicySettings defines the rules passwords must satisfy. BannedSubstrings
+// must not appear in passwords, ignoring case, and BreachedListFile points to a
+// local list of SHA-1 hashes of breached passwords.
+type PasswordPolicySettings struct {
+	MinLength        int      `mapstructure:"min_length" default:"8" validate:"gt=0"`
+	MaxLength        int      `mapstructure:"max_length" default:"128" validate:"gtefield=MinLength"`
+	RequireUpper     bool     `mapstructure:"require_upper" default:"true"`
+	RequireLower     bool     `mapstructure:"require_lower" default:"true"`
+	RequireNumber    bool     `mapstructure:"require_number" default:"true"`
+	RequireSpecial   bool     `mapstructure:"require_special" default:"true"`
+	BannedSubstrings []string `mapstructure:"banned_substrings"`
+	BreachedListFile string   `mapstructure:"breached_list_file"`
+}
+
+// PasswordHashSettings defines how passwords are hashed. Algorithm is either
+// "argon2id" or "bcrypt". The optional Pepper, or the content of PepperFile,
+// is a server-side secret mixed into every password before hashing.
+type PasswordHashSettings struct {
+	Algorithm  string           `mapstructure:"algorithm" default:"argon2id" validate:"oneof=argon2id bcrypt"` //nolint:lll
+	Argon2id   Argon2idSettings `mapstructure:"argon2id"`
+	BcryptCost int              `mapstructure:"bcrypt_cost" default:"10" validate:"min=4,max=31"`
+	Pepper     string           `mapstructure:"pepper"`
+	PepperFile string           `mapstructure:"pepper_file"`
+}
+
+// Argon2idSettings defines the parameters of the argon2id algorithm,
+// Memory being expressed in KiB.
+type Argon2idSettings struct {
+	Memory      uint32 `mapstructure:"memory" default:"65536" validate:"gt=0"`
+	Iterations  uint32 `mapstructure:"iterations" default:"3" validate:"gt=0"`
+	Parallelism uint8  `mapstructure:"parallelism" default:"2" validate:"gt=0"`
+	SaltLength  uint32 `mapstructure:"salt_length" default:"16" validate:"gt=0"`
+	KeyLength   uint32 `mapstructure:"key_length" default:"32" validate:"gt=0"`
+}
+
+// AdminSettings defines the access to the administration endpoints. Requests
+// must carry Token as a bearer token, the endpoints are disabled without one.
+type AdminSettings struct {
+	Token string `mapstructure:"token" validate:"omitempty,min=16"`
+}
+
+// StaffSettings defines the access of the support staff to the search, the
+// imports and the exports of the users. Requests must carry Token as a bearer
+// token, the endpoints are disabled without one.
+type StaffSettings struct {
+	Token string `mapstructure:"token" validate:"omitempty,min=16"`
+}
+
+// JobsSettings defines how the background jobs run. Concurrency jobs run at the
+// same time, claimed for VisibilityTimeout and polled every PollInterval when none
+// is ready. Failed jobs are retried up to MaxAttempts runs, after RetryBackoff
+// doubled on each retry up to MaxRetryBackoff. On shutdown, the running jobs are
+// given DrainTimeout to complete before being queued again.
+type JobsSettings struct {
+	Concurrency       int           `mapstructure:"concurrency" default:"4" validate:"gt=0"`
+	PollInterval      time.Duration `mapstructure:"poll_interval" default:"1s" validate:"gt=0"`
+	VisibilityTimeout time.Duration `mapstructure:"visibility_timeout" default:"5m" validate:"gt=0"`
+	MaxAttempts       int           `mapstructure:"max_attempts" default:"5" validate:"gt=0"`
+	RetryBackoff      time.Duration `mapstructure:"retry_backoff" default:"10s" validate:"gt=0"`
+	MaxRetryBackoff   time.Duration `mapstructure:"max_retry_backoff" default:"1h" validate:"gtefield=RetryBackoff"` //nolint:lll
+	DrainTimeout      time.Duration `mapstructure:"drain_timeout" default:"30s" validate:"gt=0"`
 }
@@ -0,0 +1,90 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// fileEnvSuffix marks an environment variable naming a file that holds the value,
+// as used by Docker and Kubernetes secrets.
+const fileEnvSuffix = "_FILE"
+
+// SetDefaults registers in v the default value of every setting declaring one.
+func SetDefaults(v *viper.Viper) {
+	walkSettings(reflect.TypeOf(Config{}), "", func(key string, field reflect.StructField) {
+		if value, ok := field.Tag.Lookup("default"); ok {
+			v.SetDefault(key, value)
+		}
+	})
+}
+
+// BindEnv maps every setting onto the environment variable named after its key,
+// mongo_config.password being read from MONGO_CONFIG_PASSWORD. The same variable
+// suffixed with _FILE names a file holding the value instead, setting both is
+// an error.
+func BindEnv(v *viper.Viper) error {
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	var errs []error
+	walkSettings(reflect.TypeOf(Config{}), "", func(key string, _ reflect.StructField) {
+		env := EnvName(key)
+		if err := v.BindEnv(key, env); err != nil {
+			errs = append(errs, err)
+			return
+		}
+
+		file, ok := os.LookupEnv(env + fileEnvSuffix)
+		if !ok {
+			return
+		}
+		if _, ok := os.LookupEnv(env); ok {
+			errs = append(errs, fmt.Errorf("%s and %s%s are both set", env, env, fileEnvSuffix))
+			return
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", env, fileEnvSuffix, err))
+			return
+		}
+		// Secret files usually end with a newline which isn't part of the value.
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	})
+
+	return errors.Join(errs...)
+}
+
+// EnvName returns the environment variable overriding the setting with the given key.
+func EnvName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// walkSettings calls fn with the key of every scalar setting of t. Maps and
+// slices are skipped, they can only be set from the configuration file.
+func walkSettings(t reflect.Type, prefix string, fn func(key string, field reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			walkSettings(field.Type, key, fn)
+		case reflect.Map, reflect.Slice:
+		default:
+			fn(key, field)
+		}
+	}
+}
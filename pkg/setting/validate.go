@@ -0,0 +1,104 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// configValidator checks the `validate` rules of the settings, reporting
+// fields by their configuration key.
+var configValidator = func() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("mapstructure")
+	})
+	// Ports are configured as strings, which the built-in rule doesn't support.
+	if err := v.RegisterValidation("port", isPort); err != nil {
+		panic(err)
+	}
+	return v
+}()
+
+// isPort reports whether the field holds a TCP port number.
+func isPort(fl validator.FieldLevel) bool {
+	port, err := strconv.ParseUint(fl.Field().String(), 10, 16)
+	return err == nil && port > 0
+}
+
+// Validate checks the settings against their validation rules and returns all
+// problems found as a single error, one line per invalid setting.
+func (c *Config) Validate() error {
+	err := configValidator.Struct(c)
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	errs := make([]error, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		errs = append(errs, fmt.Errorf("%s: %s", settingKey(fe.Namespace()), ruleMessage(fe)))
+	}
+	return errors.Join(errs...)
+}
+
+// settingKey converts a validator namespace such as "Config.rate_limit_config.groups[users].limit"
+// into the configuration key "rate_limit_config.groups.users.limit".
+func settingKey(namespace string) string {
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		namespace = namespace[i+1:]
+	}
+	return strings.NewReplacer("[", ".", "]", "").Replace(namespace)
+}
+
+// ruleMessage describes the validation rule a setting failed.
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required without %s", snakeCase(fe.Param()))
+	case "required_with":
+		return fmt.Sprintf("is required with %s", snakeCase(fe.Param()))
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s], got %q", fe.Param(), fmt.Sprint(fe.Value()))
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte", "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "gtefield":
+		return fmt.Sprintf("must not be less than %s", snakeCase(fe.Param()))
+	case "port":
+		return fmt.Sprintf("must be a valid port, got %q", fmt.Sprint(fe.Value()))
+	case "hostname_port":
+		return fmt.Sprintf("must be a host:port address, got %q", fmt.Sprint(fe.Value()))
+	default:
+		return fmt.Sprintf("fails the %q rule", fe.Tag())
+	}
+}
+
+// snakeCase converts a Go field name such as MinLength into its key, min_length.
+func snakeCase(name string) string {
+	var b strings.Builder
+	prev := ' '
+	for _, r := range name {
+		if unicode.IsUpper(r) {
+			if unicode.IsLower(prev) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+		prev = r
+	}
+	return b.String()
+}
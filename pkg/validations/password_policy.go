@@ -0,0 +1,206 @@
+package validations
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // SHA-1 is the format of breached password lists
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/helpers"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+)
+
+// Password policy rules reported when a password is rejected.
+const (
+	RuleMinLength       = "min_length"
+	RuleMaxLength       = "max_length"
+	RuleUppercase       = "uppercase"
+	RuleLowercase       = "lowercase"
+	RuleNumber          = "number"
+	RuleSpecial         = "special"
+	RuleBannedSubstring = "banned_substring"
+	RuleBreached        = "breached"
+)
+
+// specialChars lists the characters accepted as special characters.
+// You can adjust the set of allowed special characters as needed.
+const specialChars = "!@#$%^&*()-_=+[]{}|;:'\",.<>?/`~"
+
+// minBannedTermLength is the minimum length of a personal term, such as a first name,
+// to be banned from passwords. Shorter terms would reject too many passwords.
+const minBannedTermLength = 3
+
+// DefaultPasswordPolicySettings is used when no password policy is configured.
+var DefaultPasswordPolicySettings = setting.PasswordPolicySettings{
+	MinLength:      8,
+	MaxLength:      128,
+	RequireUpper:   true,
+	RequireLower:   true,
+	RequireNumber:  true,
+	RequireSpecial: true,
+}
+
+// currentPolicy is the policy applied by the strongPassword validation.
+var currentPolicy atomic.Pointer[PasswordPolicy]
+
+func init() {
+	currentPolicy.Store(helpers.MustValue(NewPasswordPolicy(DefaultPasswordPolicySettings)))
+}
+
+// PasswordPolicy checks passwords against the configured rules and an optional
+// offline list of breached passwords.
+type PasswordPolicy struct {
+	settings setting.PasswordPolicySettings
+	// breached maps the first 5 characters of a SHA-1 hash to the set of
+	// suffixes, the layout of the Pwned Passwords range files.
+	breached map[string]map[string]struct{}
+}
+
+// NewPasswordPolicy creates a new instance of PasswordPolicy, loading the breached
+// password list when one is configured.
+func NewPasswordPolicy(settings setting.PasswordPolicySettings) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{settings: settings}
+	if settings.BreachedListFile != "" {
+		breached, err := loadBreachedList(settings.BreachedListFile)
+		if err != nil {
+			return nil, err
+		}
+		policy.breached = breached
+	}
+	return policy, nil
+}
+
+// SetPasswordPolicy replaces the policy applied by the strongPassword validation.
+func SetPasswordPolicy(policy *PasswordPolicy) {
+	currentPolicy.Store(policy)
+}
+
+// CurrentPasswordPolicy returns the policy applied by the strongPassword validation.
+func CurrentPasswordPolicy() *PasswordPolicy {
+	return currentPolicy.Load()
+}
+
+// Check returns the rules violated by the password, none when it is accepted.
+// Personal terms, such as the user's name or email, must not appear in the
+// password; for emails only the local-part is considered.
+func (p *PasswordPolicy) Check(password string, personal ...string) []string {
+	var violated []string
+
+	length := len([]rune(password))
+	if length < p.settings.MinLength {
+		violated = append(violated, RuleMinLength)
+	}
+	if p.settings.MaxLength > 0 && length > p.settings.MaxLength {
+		violated = append(violated, RuleMaxLength)
+	}
+
+	var hasUpper, hasLower, hasNumber, hasSpecial bool
+	for _, char := range password {
+		switch {
+		case char >= 'A' && char <= 'Z':
+			hasUpper = true
+		case char >= 'a' && char <= 'z':
+			hasLower = true
+		case char >= '0' && char <= '9':
+			hasNumber = true
+		case strings.ContainsRune(specialChars, char):
+			hasSpecial = true
+		}
+	}
+	if p.settings.RequireUpper && !hasUpper {
+		violated = append(violated, RuleUppercase)
+	}
+	if p.settings.RequireLower && !hasLower {
+		violated = append(violated, RuleLowercase)
+	}
+	if p.settings.RequireNumber && !hasNumber {
+		violated = append(violated, RuleNumber)
+	}
+	if p.settings.RequireSpecial && !hasSpecial {
+		violated = append(violated, RuleSpecial)
+	}
+
+	if p.containsBannedSubstring(password, personal) {
+		violated = append(violated, RuleBannedSubstring)
+	}
+	if p.isBreached(password) {
+		violated = append(violated, RuleBreached)
+	}
+
+	return violated
+}
+
+// containsBannedSubstring reports whether the password contains a configured
+// banned substring or one of the personal terms, ignoring case.
+func (p *PasswordPolicy) containsBannedSubstring(password string, personal []string) bool {
+	lower := strings.ToLower(password)
+
+	terms := append([]string{}, p.settings.BannedSubstrings...)
+	for _, term := range personal {
+		if localPart, _, ok := strings.Cut(term, "@"); ok {
+			term = localPart
+		}
+		if len(term) >= minBannedTermLength {
+			terms = append(terms, term)
+		}
+	}
+
+	for _, term := range terms {
+		if term != "" && strings.Contains(lower, strings.ToLower(term)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBreached reports whether the password appears in the breached password list.
+func (p *PasswordPolicy) isBreached(password string) bool {
+	if p.breached == nil {
+		return false
+	}
+
+	sum := sha1.Sum([]byte(password)) //nolint:gosec // see import
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	_, found := p.breached[hash[:5]][hash[5:]]
+	return found
+}
+
+// loadBreachedList reads a list of SHA-1 password hashes, one per line and
+// optionally followed by ":<count>" as in the Pwned Passwords downloads.
+// Empty lines and lines starting with "#" are ignored.
+func loadBreachedList(path string) (map[string]map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	breached := make(map[string]map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(entry, ":")
+		hash = strings.ToUpper(hash)
+		if len(hash) != 2*sha1.Size {
+			return nil, fmt.Errorf("invalid SHA-1 hash in breached password list at line %d", line)
+		}
+
+		prefix, suffix := hash[:5], hash[5:]
+		if breached[prefix] == nil {
+			breached[prefix] = make(map[string]struct{})
+		}
+		breached[prefix][suffix] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return breached, nil
+}
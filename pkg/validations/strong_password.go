@@ -1,34 +1,60 @@
 package validations
 
 import (
+	"reflect"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
 
-// StrongPassword Custom validation function for strong passwords
+// strongPasswordMessages holds the strongPassword validation messages per locale,
+// {1} being the list of violated password policy rules.
+var strongPasswordMessages = map[string]string{
+	"en": "{0} does not meet the password policy: {1}",
+	"vi": "{0} không đáp ứng chính sách mật khẩu: {1}",
+}
+
+// personalFields lists the sibling fields whose values must not appear in a password.
+var personalFields = []string{"Email", "FirstName", "LastName"}
+
+// StrongPassword Custom validation function for strong passwords.
+// It applies the current password policy, banning the email, first name and
+// last name of the validated struct from the password.
 func StrongPassword(fl validator.FieldLevel) bool {
 	password := fl.Field().String()
+	return len(CurrentPasswordPolicy().Check(password, personalTerms(fl.Parent())...)) == 0
+}
 
-	// Check for minimum length
-	if len(password) < 8 {
-		return false
+// strongPasswordArgs returns the violated rules for the strongPassword message.
+// Sibling fields are unknown at this point, a violation caused by them only is
+// reported as a banned substring.
+func strongPasswordArgs(fe validator.FieldError) []string {
+	password, _ := fe.Value().(string)
+	violated := CurrentPasswordPolicy().Check(password)
+	if len(violated) == 0 {
+		violated = []string{RuleBannedSubstring}
 	}
+	return []string{strings.Join(violated, ", ")}
+}
 
-	var hasUpper, hasLower, hasNumber, hasSpecial bool
-	for _, char := range password {
-		switch {
-		case char >= 'A' && char <= 'Z':
-			hasUpper = true
-		case char >= 'a' && char <= 'z':
-			hasLower = true
-		case char >= '0' && char <= '9':
-			hasNumber = true
-		// You can adjust the set of allowed special characters as needed.
-		case strings.ContainsRune("!@#$%^&*()-_=+[]{}|;:'\",.<>?/`~", char):
-			hasSpecial = true
+// personalTerms collects the values of the personal fields of a struct.
+func personalTerms(parent reflect.Value) []string {
+	for parent.Kind() == reflect.Ptr {
+		if parent.IsNil() {
+			return nil
 		}
+		parent = parent.Elem()
+	}
+	if parent.Kind() != reflect.Struct {
+		return nil
 	}
 
-	return hasUpper && hasLower && hasNumber && hasSpecial
+	var terms []string
+	for _, name := range personalFields {
+		field := parent.FieldByName(name)
+		if field.IsValid() && field.Kind() == reflect.String && field.String() != "" {
+			terms = append(terms, field.String())
+		}
+	}
+	return terms
 }
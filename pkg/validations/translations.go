@@ -0,0 +1,107 @@
+package validations
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/vi"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	vitranslations "github.com/go-playground/validator/v10/translations/vi"
+)
+
+// CustomValidation describes a custom validation tag along with its messages
+// per locale. Messages may reference the field name with {0} and the values
+// returned by Args with {1} onwards.
+type CustomValidation struct {
+	Tag      string
+	Func     validator.Func
+	Messages map[string]string
+	Args     func(fe validator.FieldError) []string
+}
+
+// CustomValidations lists the custom validations registered on the validator.
+var CustomValidations = []CustomValidation{
+	{
+		Tag:      "strongPassword",
+		Func:     StrongPassword,
+		Messages: strongPasswordMessages,
+		Args:     strongPasswordArgs,
+	},
+}
+
+// defaultTranslations registers the built-in messages of a locale.
+var defaultTranslations = map[string]func(*validator.Validate, ut.Translator) error{
+	"en": entranslations.RegisterDefaultTranslations,
+	"vi": vitranslations.RegisterDefaultTranslations,
+}
+
+// JSONTagName returns the JSON name of a struct field, so that validation errors
+// refer to fields the way clients send them.
+func JSONTagName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
+
+// NewTranslator creates a universal translator for the supported locales, English
+// being the fallback, and registers the built-in and custom messages on v.
+func NewTranslator(v *validator.Validate) (*ut.UniversalTranslator, error) {
+	uni := ut.New(en.New(), en.New(), vi.New())
+
+	for locale, register := range defaultTranslations {
+		trans, _ := uni.GetTranslator(locale)
+		if err := register(v, trans); err != nil {
+			return nil, fmt.Errorf("failed to register %s translations: %w", locale, err)
+		}
+	}
+
+	for _, custom := range CustomValidations {
+		for locale, message := range custom.Messages {
+			trans, found := uni.GetTranslator(locale)
+			if !found {
+				return nil, fmt.Errorf("unsupported locale %s for %s", locale, custom.Tag)
+			}
+			if err := registerMessage(v, trans, custom, message); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return uni, nil
+}
+
+// registerMessage registers the message of a custom validation for a translator.
+func registerMessage(
+	v *validator.Validate,
+	trans ut.Translator,
+	custom CustomValidation,
+	message string,
+) error {
+	return v.RegisterTranslation(
+		custom.Tag, trans,
+		func(trans ut.Translator) error {
+			return trans.Add(custom.Tag, message, true)
+		},
+		func(trans ut.Translator, fe validator.FieldError) string {
+			params := []string{fe.Field()}
+			if custom.Args != nil {
+				params = append(params, custom.Args(fe)...)
+			}
+			msg, err := trans.T(custom.Tag, params...)
+			if err != nil {
+				return fe.Error()
+			}
+			return msg
+		},
+	)
+}
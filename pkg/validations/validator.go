@@ -0,0 +1,21 @@
+package validations
+
+import "github.com/go-playground/validator/v10"
+
+// NewValidator creates a validator reporting fields by their JSON names, with
+// the custom validations registered.
+func NewValidator() (*validator.Validate, error) {
+	v := validator.New()
+
+	// Report fields by their JSON names
+	v.RegisterTagNameFunc(JSONTagName)
+
+	// Register custom validations, such as strong password
+	for _, custom := range CustomValidations {
+		if err := v.RegisterValidation(custom.Tag, custom.Func); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.uber.org/zap"
+)
+
+var errUnreachable = errors.New("server selection timeout")
+
+// failingPing returns a ping failing the given number of times before answering,
+// counting its calls.
+func failingPing(failures int, calls *int) func(context.Context, *readpref.ReadPref) error {
+	return func(context.Context, *readpref.ReadPref) error {
+		*calls++
+		if *calls <= failures {
+			return errUnreachable
+		}
+		return nil
+	}
+}
+
+func TestPingWithRetry(t *testing.T) {
+	t.Run("should retry until the server answers", func(t *testing.T) {
+		calls := 0
+		opts := database.DBOptions{ConnectRetries: 5, RetryBackoff: time.Millisecond}
+
+		err := database.PingWithRetry(t.Context(), failingPing(2, &calls), opts, zap.NewNop())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("should return the last error once the retries are exhausted", func(t *testing.T) {
+		calls := 0
+		opts := database.DBOptions{ConnectRetries: 2, RetryBackoff: time.Millisecond}
+
+		err := database.PingWithRetry(t.Context(), failingPing(10, &calls), opts, zap.NewNop())
+
+		assert.ErrorIs(t, err, errUnreachable)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("should give each attempt the connection timeout", func(t *testing.T) {
+		var remaining time.Duration
+		ping := func(ctx context.Context, _ *readpref.ReadPref) error {
+			deadline, _ := ctx.Deadline()
+			remaining = time.Until(deadline)
+			return nil
+		}
+		opts := database.DBOptions{ConnectTimeout: time.Minute}
+
+		assert.NoError(t, database.PingWithRetry(t.Context(), ping, opts, zap.NewNop()))
+		assert.InDelta(t, time.Minute, remaining, float64(time.Second))
+	})
+
+	t.Run("should stop waiting for the next attempt once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		calls := 0
+		ping := func(context.Context, *readpref.ReadPref) error {
+			calls++
+			cancel()
+			return errUnreachable
+		}
+		opts := database.DBOptions{ConnectRetries: 3, RetryBackoff: time.Hour}
+
+		done := make(chan error, 1)
+		go func() { done <- database.PingWithRetry(ctx, ping, opts, zap.NewNop()) }()
+
+		select {
+		case err := <-done:
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.ErrorIs(t, err, errUnreachable)
+			assert.Equal(t, 1, calls)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the retries went on after the context was canceled")
+		}
+	})
+}
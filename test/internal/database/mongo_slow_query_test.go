@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// runCommand feeds the monitor with a command taking duration.
+func runCommand(
+	t *testing.T,
+	monitor *event.CommandMonitor,
+	requestID int64,
+	command bson.D,
+	duration time.Duration,
+) {
+	t.Helper()
+	raw, err := bson.Marshal(command)
+	require.NoError(t, err)
+
+	name := command[0].Key
+	monitor.Started(context.Background(), &event.CommandStartedEvent{
+		Command:      raw,
+		DatabaseName: "app",
+		CommandName:  name,
+		RequestID:    requestID,
+	})
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{
+			Duration:     duration,
+			CommandName:  name,
+			DatabaseName: "app",
+			RequestID:    requestID,
+		},
+	})
+}
+
+// slowQueryCount returns the number of slow queries counted for the collection.
+func slowQueryCount(collection string) int64 {
+	counts, ok := expvar.Get("mongo_slow_queries").(*expvar.Map)
+	if !ok {
+		return 0
+	}
+	count, ok := counts.Get(collection).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return count.Value()
+}
+
+func TestSlowQueryDetector(t *testing.T) {
+	findUser := bson.D{
+		{Key: "find", Value: "slow_users"},
+		{Key: "filter", Value: bson.D{
+			{Key: "email", Value: "john@example.com"},
+			{Key: "age", Value: bson.D{{Key: "$gte", Value: 42}}},
+			{Key: "$or", Value: bson.A{
+				bson.D{{Key: "first_name", Value: "John"}},
+				bson.D{{Key: "roles", Value: bson.D{{Key: "$in", Value: bson.A{"admin", "staff"}}}}},
+			}},
+		}},
+	}
+
+	t.Run("should report the commands slower than the threshold", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		detector := database.NewSlowQueryDetector(zap.New(core), 100*time.Millisecond, false)
+		before := slowQueryCount("slow_users")
+
+		runCommand(t, detector.Monitor(), 1, findUser, 10*time.Millisecond)
+		assert.Zero(t, logs.Len())
+		assert.Equal(t, before, slowQueryCount("slow_users"))
+
+		runCommand(t, detector.Monitor(), 2, findUser, 100*time.Millisecond)
+		require.Equal(t, 1, logs.Len())
+		fields := logs.All()[0].ContextMap()
+		assert.Equal(t, "find", fields["command"])
+		assert.Equal(t, "slow_users", fields["collection"])
+		assert.Equal(t, before+1, slowQueryCount("slow_users"))
+	})
+
+	t.Run("should only log the shape of the filter", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		detector := database.NewSlowQueryDetector(zap.New(core), time.Millisecond, false)
+
+		runCommand(t, detector.Monitor(), 3, findUser, time.Second)
+		require.Equal(t, 1, logs.Len())
+		filter, ok := logs.All()[0].ContextMap()["filter"].(string)
+		require.True(t, ok)
+
+		assert.JSONEq(t, `{
+			"email": "?",
+			"age": {"$gte": "?"},
+			"$or": [{"first_name": "?"}, {"roles": {"$in": ["?"]}}]
+		}`, filter)
+		for _, value := range []string{"john@example.com", "42", "John", "admin", "staff"} {
+			assert.NotContains(t, filter, value)
+		}
+	})
+
+	t.Run("should ignore the commands without a filter", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		detector := database.NewSlowQueryDetector(zap.New(core), time.Millisecond, false)
+
+		runCommand(t, detector.Monitor(), 4, bson.D{
+			{Key: "insert", Value: "slow_users"},
+			{Key: "documents", Value: bson.A{bson.D{{Key: "email", Value: "john@example.com"}}}},
+		}, time.Second)
+		assert.Zero(t, logs.Len())
+	})
+}
+
+func TestHasCollectionScan(t *testing.T) {
+	explain := func(winningPlan, rejectedPlans bson.D) bson.Raw {
+		raw, err := bson.Marshal(bson.D{
+			{Key: "queryPlanner", Value: bson.D{
+				{Key: "namespace", Value: "app.users"},
+				{Key: "winningPlan", Value: winningPlan},
+				{Key: "rejectedPlans", Value: bson.A{rejectedPlans}},
+			}},
+			{Key: "ok", Value: 1},
+		})
+		require.NoError(t, err)
+		return raw
+	}
+	collScan := bson.D{{Key: "stage", Value: "COLLSCAN"}, {Key: "direction", Value: "forward"}}
+	ixScan := bson.D{
+		{Key: "stage", Value: "FETCH"},
+		{Key: "inputStage", Value: bson.D{
+			{Key: "stage", Value: "IXSCAN"},
+			{Key: "indexName", Value: "email_1"},
+		}},
+	}
+
+	t.Run("should detect a winning collection scan", func(t *testing.T) {
+		assert.True(t, database.HasCollectionScan(explain(collScan, ixScan)))
+	})
+
+	t.Run("should detect a collection scan nested in the plan", func(t *testing.T) {
+		plan := bson.D{
+			{Key: "stage", Value: "SORT"},
+			{Key: "inputStages", Value: bson.A{ixScan, collScan}},
+		}
+
+		assert.True(t, database.HasCollectionScan(explain(plan, ixScan)))
+	})
+
+	t.Run("should ignore the rejected plans", func(t *testing.T) {
+		assert.False(t, database.HasCollectionScan(explain(ixScan, collScan)))
+	})
+}
@@ -0,0 +1,189 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/generator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testModulesFile = `package wires
+
+import (
+	"example.com/app/internal/module"
+	"example.com/app/internal/module/users"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func Modules(db *mongo.Database) []module.Module {
+	return []module.Module{
+		NewUserModule(users.NewUserRepository(db)),
+	}
+}
+`
+
+// repoRoot is the root of the repository, relative to the tests.
+const repoRoot = "../../.."
+
+// copyRepo copies the sources of the repository into a temporary module.
+func copyRepo(t *testing.T) string {
+	root := t.TempDir()
+	for _, file := range []string{"go.mod", "go.sum"} {
+		content, err := os.ReadFile(filepath.Join(repoRoot, file))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(root, file), content, 0o644))
+	}
+	dirs := []string{"api", "cmd", "configs", "global", "internal", "metadata", "pkg", "test"}
+	for _, dir := range dirs {
+		require.NoError(t, os.CopyFS(filepath.Join(root, dir), os.DirFS(filepath.Join(repoRoot, dir))))
+	}
+	return root
+}
+
+// goRun runs the go command in dir, failing the test with its output.
+func goRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "go %s:\n%s", strings.Join(args, " "), output)
+}
+
+// setupRoot creates a repository root holding the list of modules.
+func setupRoot(t *testing.T) string {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "internal", "wires"), 0o755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(root, "internal", "wires", "modules.go"), []byte(testModulesFile), 0o644,
+	))
+	return root
+}
+
+func TestNewModuleSpec(t *testing.T) {
+	t.Run("should decline the module name", func(t *testing.T) {
+		spec, err := generator.NewModuleSpec("example.com/app", "order_item", "name:string")
+
+		require.NoError(t, err)
+		assert.Equal(t, "orderitems", spec.Package)
+		assert.Equal(t, "OrderItem", spec.Type)
+		assert.Equal(t, "orderItem", spec.Var)
+		assert.Equal(t, "an order item", spec.ALabel)
+		assert.Equal(t, "order_items", spec.Collection)
+		assert.Equal(t, "order-items", spec.Route)
+		assert.Equal(t, "ORDER_ITEM_NOT_FOUND", spec.ErrCode())
+	})
+
+	t.Run("should pluralize regular nouns", func(t *testing.T) {
+		for name, pkg := range map[string]string{
+			"user": "users", "category": "categories", "key": "keys", "box": "boxes", "match": "matches",
+		} {
+			spec, err := generator.NewModuleSpec("example.com/app", name, "name:string")
+			require.NoError(t, err)
+			assert.Equal(t, pkg, spec.Package)
+		}
+	})
+
+	t.Run("should parse the fields", func(t *testing.T) {
+		spec, err := generator.NewModuleSpec(
+			"example.com/app", "product", "name:string, unit_price:float,image_url:string,sold_at:time",
+		)
+
+		require.NoError(t, err)
+		require.Len(t, spec.Fields, 4)
+		assert.Equal(t, "UnitPrice", spec.Fields[1].Name)
+		assert.Equal(t, "unit_price", spec.Fields[1].Key)
+		assert.Equal(t, "float64", spec.Fields[1].GoType)
+		assert.False(t, spec.Fields[1].Required())
+		assert.Equal(t, "ImageURL", spec.Fields[2].Name)
+		assert.True(t, spec.Fields[3].Required())
+		assert.True(t, spec.HasTime())
+	})
+
+	t.Run("should reject invalid input", func(t *testing.T) {
+		for _, tc := range []struct{ name, fields, err string }{
+			{"Product", "name:string", "invalid module name"},
+			{"type", "name:string", "Go keyword"},
+			{"product", "name", "use name:type"},
+			{"product", "Name:string", "invalid field name"},
+			{"product", "name:text", `invalid type "text"`},
+			{"product", "name:string,name:int", "duplicate field"},
+			{"product", "id:string", "set by common.BaseModel"},
+		} {
+			_, err := generator.NewModuleSpec("example.com/app", tc.name, tc.fields)
+			assert.ErrorContains(t, err, tc.err)
+		}
+	})
+}
+
+func TestGenerate(t *testing.T) {
+	spec, err := generator.NewModuleSpec("example.com/app", "product", "name:string,price:float")
+	require.NoError(t, err)
+
+	t.Run("should write the files and register the module", func(t *testing.T) {
+		root := setupRoot(t)
+
+		written, err := generator.Generate(root, spec, false)
+
+		require.NoError(t, err)
+		assert.Contains(t, written, filepath.Join("internal", "module", "products", "product_repo.go"))
+		assert.Contains(t, written,
+			filepath.Join("internal", "module", "products", "product_repo_memory.go"))
+		assert.Contains(t, written, filepath.Join("internal", "wires", "product_wire.go"))
+		assert.Contains(t, written,
+			filepath.Join("test", "internal", "modules", "products", "product_controller_test.go"))
+		for _, path := range written {
+			assert.FileExists(t, filepath.Join(root, path))
+		}
+
+		model, err := os.ReadFile(
+			filepath.Join(root, "internal", "module", "products", "product_model.go"),
+		)
+		require.NoError(t, err)
+		assert.Regexp(t, `Price +float64 +`+"`"+`bson:"price" json:"price"`, string(model))
+		assert.Contains(t, string(model), `return "products"`)
+
+		modules, err := os.ReadFile(filepath.Join(root, "internal", "wires", "modules.go"))
+		require.NoError(t, err)
+		assert.Contains(t, string(modules), "\t\tNewProductModule(products.NewProductRepository(db)),\n")
+		assert.Contains(t, string(modules), "\t\"example.com/app/internal/module/products\"\n")
+	})
+
+	t.Run("should generate a module that builds and passes its tests", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("builds a copy of the repository")
+		}
+		if _, err := exec.LookPath("go"); err != nil {
+			t.Skip("the go command is not available")
+		}
+		root := copyRepo(t)
+		goModule, err := generator.GoModule(root)
+		require.NoError(t, err)
+		spec, err := generator.NewModuleSpec(goModule, "order_item", "name:string,price:float,due:time")
+		require.NoError(t, err)
+
+		_, err = generator.Generate(root, spec, false)
+		require.NoError(t, err)
+
+		goRun(t, root, "generate", "./internal/wires")
+		goRun(t, root, "vet", "./internal/...", "./test/internal/modules/...")
+		goRun(t, root, "test", "./test/internal/modules/"+spec.Package+"/...")
+	})
+
+	t.Run("should not overwrite existing files unless forced", func(t *testing.T) {
+		root := setupRoot(t)
+		_, err := generator.Generate(root, spec, false)
+		require.NoError(t, err)
+
+		_, err = generator.Generate(root, spec, false)
+		assert.ErrorContains(t, err, "already exists")
+
+		written, err := generator.Generate(root, spec, true)
+		require.NoError(t, err)
+		assert.NotContains(t, written, filepath.Join("internal", "wires", "modules.go"))
+	})
+}
@@ -23,7 +23,7 @@ func TestInitDatabase(t *testing.T) {
 		initialize.InitLogger()
 
 		// Test execution
-		initialize.InitDatabase()
+		err := initialize.InitDatabase(t.Context())
 
 		defer func() {
 			if global.MongoDB != nil {
@@ -32,6 +32,8 @@ func TestInitDatabase(t *testing.T) {
 		}()
 
 		// Assertions
+		assert.NoError(t, err)
 		assert.NotNil(t, global.MongoDB)
+		assert.True(t, global.MongoDB.Ready())
 	})
 }
@@ -0,0 +1,95 @@
+package initialize
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/initialize"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module"
+	"github.com/hainguyen27798/gin-boilerplate/internal/wires"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/jobs"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/logger"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"github.com/hainguyen27798/gin-boilerplate/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAwaitShutdown(t *testing.T) {
+	t.Run("should give the running jobs the timeout from the signal", func(t *testing.T) {
+		config := testutil.DefaultConfig(t)
+		config.Jobs.PollInterval = 10 * time.Millisecond
+		jobsModule := wires.NewJobsModule(jobs.NewMemoryStore())
+		registry := module.NewRegistry()
+		require.NoError(t, registry.Register(jobsModule))
+		require.NoError(t, registry.Init(module.Deps{
+			Logger: logger.NewLogger(config.Logger, setting.TestMode, "test", ""),
+			Config: func() *setting.Config { return config },
+		}))
+
+		// The job outlives the timeout counted from the start, not from the signal.
+		const timeout, signalDelay = 200 * time.Millisecond, 300 * time.Millisecond
+		started, release := make(chan struct{}), make(chan struct{})
+		var completed atomic.Bool
+		slow := jobs.NewType[string]("slow")
+		jobs.Handle(jobsModule.Queue(), slow, func(ctx context.Context, _ string) error {
+			close(started)
+			select {
+			case <-release:
+				completed.Store(true)
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		require.NoError(t, registry.Start(context.Background()))
+		_, err := jobs.Enqueue(context.Background(), jobsModule.Queue(), slow, "payload")
+		require.NoError(t, err)
+		<-started
+
+		quit := make(chan os.Signal, 1)
+		time.AfterFunc(signalDelay, func() { quit <- syscall.SIGTERM })
+		time.AfterFunc(signalDelay+timeout/2, func() { close(release) })
+		var stopErr error
+		initialize.AwaitShutdown(quit, initialize.ShutdownStep{
+			Timeout: timeout,
+			Stop:    func(ctx context.Context) { stopErr = registry.Stop(ctx) },
+		})
+
+		assert.NoError(t, stopErr)
+		assert.True(t, completed.Load())
+	})
+
+	t.Run("should run the steps concurrently, each with its own timeout", func(t *testing.T) {
+		const short, long = 50 * time.Millisecond, 500 * time.Millisecond
+		quit := make(chan os.Signal, 1)
+		quit <- syscall.SIGTERM
+
+		shortDone := make(chan struct{})
+		var longErr error
+		var longSawShort atomic.Bool
+		start := time.Now()
+		initialize.AwaitShutdown(quit,
+			initialize.ShutdownStep{Timeout: short, Stop: func(ctx context.Context) {
+				<-ctx.Done()
+				close(shortDone)
+			}},
+			initialize.ShutdownStep{Timeout: long, Stop: func(ctx context.Context) {
+				select {
+				case <-shortDone:
+					longSawShort.Store(true)
+				case <-ctx.Done():
+				}
+				longErr = ctx.Err()
+			}},
+		)
+
+		assert.True(t, longSawShort.Load())
+		assert.NoError(t, longErr)
+		assert.Less(t, time.Since(start), long)
+	})
+}
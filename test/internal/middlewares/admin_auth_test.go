@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+func doAdminRequest(token, authorization string) int {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin", middlewares.NewAdminAuth(token), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAdminAuth(t *testing.T) {
+	const token = "0123456789abcdef"
+
+	t.Run("should let a request with the token through", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, doAdminRequest(token, "Bearer "+token))
+	})
+
+	t.Run("should reject a request without the token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, doAdminRequest(token, ""))
+	})
+
+	t.Run("should reject a request with another token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, doAdminRequest(token, "Bearer fedcba9876543210"))
+	})
+
+	t.Run("should reject every request without a configured token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, doAdminRequest("", "Bearer "))
+	})
+}
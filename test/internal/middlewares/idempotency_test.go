@@ -0,0 +1,215 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/middlewares"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/idempotency"
+	"github.com/stretchr/testify/assert"
+)
+
+// hour keeps the idempotent responses for an hour.
+func hour() time.Duration { return time.Hour }
+
+func setupIdempotencyRouter(
+	repo idempotency.IdempotencyRepository,
+	handler gin.HandlerFunc,
+) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/users", middlewares.Idempotency(repo, hour), handler)
+	return r
+}
+
+func doPost(r *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(middlewares.IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Run("should process every request without a key", func(t *testing.T) {
+		calls := 0
+		r := setupIdempotencyRouter(idempotency.NewMemoryIdempotencyRepository(), func(c *gin.Context) {
+			calls++
+			c.JSON(http.StatusCreated, gin.H{"calls": calls})
+		})
+
+		doPost(r, "", `{"email":"test@example.com"}`)
+		doPost(r, "", `{"email":"test@example.com"}`)
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should replay the saved response for a retried request", func(t *testing.T) {
+		calls := 0
+		r := setupIdempotencyRouter(idempotency.NewMemoryIdempotencyRepository(), func(c *gin.Context) {
+			calls++
+			c.JSON(http.StatusCreated, gin.H{"calls": calls})
+		})
+
+		first := doPost(r, "key-1", `{"email":"test@example.com"}`)
+		second := doPost(r, "key-1", `{"email":"test@example.com"}`)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("should replay the headers set by the handler", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		calls := 0
+		r := gin.New()
+		r.POST(
+			"/users",
+			func(c *gin.Context) { c.Header("X-Request-ID", strconv.Itoa(calls)) },
+			middlewares.Idempotency(idempotency.NewMemoryIdempotencyRepository(), hour),
+			func(c *gin.Context) {
+				calls++
+				c.Header("Location", "/users/42")
+				c.Header("ETag", `"v1"`)
+				c.JSON(http.StatusCreated, gin.H{"id": "42"})
+			},
+		)
+
+		doPost(r, "key-1", `{"email":"test@example.com"}`)
+		w := doPost(r, "key-1", `{"email":"test@example.com"}`)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "/users/42", w.Header().Get("Location"))
+		assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		// The headers of the previous middlewares are theirs, not the saved ones
+		assert.Equal(t, "1", w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("should reject the bodies too large to fingerprint", func(t *testing.T) {
+		calls := 0
+		r := setupIdempotencyRouter(idempotency.NewMemoryIdempotencyRepository(), func(c *gin.Context) {
+			calls++
+			c.JSON(http.StatusCreated, gin.H{})
+		})
+
+		w := doPost(r, "key-1", strings.Repeat("x", middlewares.MaxIdempotentBodySize+1))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Zero(t, calls)
+	})
+
+	t.Run("should reject a key reused with a different payload", func(t *testing.T) {
+		r := setupIdempotencyRouter(idempotency.NewMemoryIdempotencyRepository(), func(c *gin.Context) {
+			c.JSON(http.StatusCreated, gin.H{})
+		})
+
+		doPost(r, "key-1", `{"email":"test@example.com"}`)
+		w := doPost(r, "key-1", `{"email":"other@example.com"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	})
+
+	t.Run("should reject a concurrent duplicate", func(t *testing.T) {
+		repo := idempotency.NewMemoryIdempotencyRepository()
+		var r *gin.Engine
+		var duplicate *httptest.ResponseRecorder
+		r = setupIdempotencyRouter(repo, func(c *gin.Context) {
+			// Simulate the retry arriving while the first request is processing.
+			duplicate = doPost(r, "key-1", `{"email":"test@example.com"}`)
+			c.JSON(http.StatusCreated, gin.H{})
+		})
+
+		w := doPost(r, "key-1", `{"email":"test@example.com"}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, http.StatusConflict, duplicate.Code)
+	})
+
+	t.Run("should allow a retry after a server error", func(t *testing.T) {
+		calls := 0
+		r := setupIdempotencyRouter(idempotency.NewMemoryIdempotencyRepository(), func(c *gin.Context) {
+			calls++
+			if calls == 1 {
+				c.JSON(http.StatusInternalServerError, gin.H{})
+				return
+			}
+			c.JSON(http.StatusCreated, gin.H{})
+		})
+
+		doPost(r, "key-1", `{"email":"test@example.com"}`)
+		w := doPost(r, "key-1", `{"email":"test@example.com"}`)
+
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	// The key is scoped to the user set in the context by the authentication, which
+	// this unit test stands in for.
+	t.Run("should scope the keys to the authenticated user", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		calls := 0
+		r := gin.New()
+		r.POST(
+			"/users",
+			func(c *gin.Context) {
+				c.Set(middlewares.ContextUserIDKey, c.GetHeader("X-User-ID"))
+			},
+			middlewares.Idempotency(idempotency.NewMemoryIdempotencyRepository(), hour),
+			func(c *gin.Context) {
+				calls++
+				c.JSON(http.StatusCreated, gin.H{"calls": calls})
+			},
+		)
+		postAs := func(userID string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`))
+			req.Header.Set(middlewares.IdempotencyKeyHeader, "key-1")
+			req.Header.Set("X-User-ID", userID)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w
+		}
+
+		postAs("user-1")
+		postAs("user-2")
+		w := postAs("user-1")
+
+		assert.Equal(t, 2, calls)
+		assert.JSONEq(t, `{"calls":1}`, w.Body.String())
+	})
+
+	t.Run("should allow a retry after a panic", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		calls := 0
+		r := gin.New()
+		r.Use(gin.Recovery())
+		r.POST(
+			"/users",
+			middlewares.Idempotency(idempotency.NewMemoryIdempotencyRepository(), hour),
+			func(c *gin.Context) {
+				calls++
+				if calls == 1 {
+					panic("boom")
+				}
+				c.JSON(http.StatusCreated, gin.H{})
+			},
+		)
+
+		first := doPost(r, "key-1", `{"email":"test@example.com"}`)
+		w := doPost(r, "key-1", `{"email":"test@example.com"}`)
+
+		assert.Equal(t, http.StatusInternalServerError, first.Code)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+}
@@ -0,0 +1,147 @@
+package middlewares
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/middlewares"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/ratelimit"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStore is a rate limit store that is always unavailable.
+type failingStore struct{}
+
+func (failingStore) Allow(context.Context, string, int, time.Duration) (*ratelimit.Result, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func (failingStore) Close() error { return nil }
+
+// setupRateLimitRouter limits the requests with the rule, a request of the user
+// named by the X-User-ID header being authenticated as this user.
+func setupRateLimitRouter(store ratelimit.Store, rule setting.RateLimitRule) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET(
+		"/items",
+		// Sets the user in the context as the authentication does, which this
+		// unit test stands in for.
+		func(c *gin.Context) {
+			if userID := c.GetHeader("X-User-ID"); userID != "" {
+				c.Set(middlewares.ContextUserIDKey, userID)
+			}
+		},
+		middlewares.NewRateLimit(store, "items", rule),
+		func(c *gin.Context) { c.Status(http.StatusNoContent) },
+	)
+	return r
+}
+
+// doGet sends a request from the client IP with the given headers.
+func doGet(r *gin.Engine, ip string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.RemoteAddr = ip + ":1234"
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+	rule := setting.RateLimitRule{Limit: 2, Window: time.Minute}
+
+	t.Run("should report the remaining requests", func(t *testing.T) {
+		r := setupRateLimitRouter(ratelimit.NewMemoryStore(), rule)
+
+		w := doGet(r, "10.0.0.1", nil)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		reset, err := strconv.Atoi(w.Header().Get("RateLimit-Reset"))
+		require.NoError(t, err)
+		assert.Positive(t, reset)
+		assert.Empty(t, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("should answer 429 in the envelope once the limit is reached", func(t *testing.T) {
+		r := setupRateLimitRouter(ratelimit.NewMemoryStore(), rule)
+
+		doGet(r, "10.0.0.1", nil)
+		doGet(r, "10.0.0.1", nil)
+		w := doGet(r, "10.0.0.1", nil)
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.Positive(t, retryAfter)
+
+		var res response.TErrResponse[string]
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+		assert.Equal(t, "TOO_MANY_REQUESTS", res.ErrorCode)
+	})
+
+	t.Run("should count the requests by client IP", func(t *testing.T) {
+		r := setupRateLimitRouter(ratelimit.NewMemoryStore(), rule)
+
+		doGet(r, "10.0.0.1", nil)
+		doGet(r, "10.0.0.1", nil)
+
+		assert.Equal(t, http.StatusTooManyRequests, doGet(r, "10.0.0.1", nil).Code)
+		assert.Equal(t, http.StatusNoContent, doGet(r, "10.0.0.2", nil).Code)
+	})
+
+	t.Run("should count the requests by user", func(t *testing.T) {
+		rule := setting.RateLimitRule{Limit: 2, Window: time.Minute, KeyBy: ratelimit.KeyByUser}
+		r := setupRateLimitRouter(ratelimit.NewMemoryStore(), rule)
+		user1 := map[string]string{"X-User-ID": "user-1"}
+
+		doGet(r, "10.0.0.1", user1)
+		doGet(r, "10.0.0.2", user1)
+
+		assert.Equal(t, http.StatusTooManyRequests, doGet(r, "10.0.0.3", user1).Code)
+		assert.Equal(t, http.StatusNoContent,
+			doGet(r, "10.0.0.1", map[string]string{"X-User-ID": "user-2"}).Code)
+		// Anonymous requests fall back to the client IP
+		assert.Equal(t, http.StatusNoContent, doGet(r, "10.0.0.1", nil).Code)
+	})
+
+	t.Run("should count the requests by API key", func(t *testing.T) {
+		rule := setting.RateLimitRule{Limit: 2, Window: time.Minute, KeyBy: ratelimit.KeyByAPIKey}
+		r := setupRateLimitRouter(ratelimit.NewMemoryStore(), rule)
+		key1 := map[string]string{middlewares.APIKeyHeader: "key-1"}
+
+		doGet(r, "10.0.0.1", key1)
+		doGet(r, "10.0.0.2", key1)
+
+		assert.Equal(t, http.StatusTooManyRequests, doGet(r, "10.0.0.3", key1).Code)
+		assert.Equal(t, http.StatusNoContent,
+			doGet(r, "10.0.0.1", map[string]string{middlewares.APIKeyHeader: "key-2"}).Code)
+		// Requests without a key fall back to the client IP
+		assert.Equal(t, http.StatusNoContent, doGet(r, "10.0.0.1", nil).Code)
+	})
+
+	t.Run("should let the requests through when the store is unavailable", func(t *testing.T) {
+		r := setupRateLimitRouter(failingStore{}, rule)
+
+		w := doGet(r, "10.0.0.1", nil)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	})
+}
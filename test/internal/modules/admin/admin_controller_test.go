@@ -0,0 +1,31 @@
+package admin
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/admin"
+	"github.com/hainguyen27798/gin-boilerplate/test/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminController(t *testing.T) {
+	t.Run("should reject requests without the admin token", func(t *testing.T) {
+		app := testutil.NewApp(t)
+
+		app.Client(t).Get("/admin/log-level").ExpectStatus(http.StatusUnauthorized)
+	})
+
+	t.Run("should change the log level of an admin", func(t *testing.T) {
+		app := testutil.NewApp(t)
+
+		var logLevel admin.LogLevelDto
+		app.Client(t).Put("/admin/log-level").AsAdmin().
+			JSON(admin.UpdateLogLevelDto{Level: "debug"}).
+			ExpectStatus(http.StatusOK).
+			DecodeData(&logLevel)
+
+		assert.Equal(t, "debug", logLevel.Level)
+		assert.Equal(t, "debug", app.Logger.Level().String())
+	})
+}
@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/admin"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/logger"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// fakeTimer is a revert scheduled by the service, fired by the tests.
+type fakeTimer struct {
+	delay   time.Duration
+	fire    func()
+	stopped bool
+}
+
+// fakeTimers records the reverts scheduled by the service instead of running them.
+type fakeTimers []*fakeTimer
+
+func (timers *fakeTimers) afterFunc(d time.Duration, f func()) func() bool {
+	timer := &fakeTimer{delay: d, fire: f}
+	*timers = append(*timers, timer)
+	return func() bool {
+		stopped := !timer.stopped
+		timer.stopped = true
+		return stopped
+	}
+}
+
+func setupLogLevelService(
+	t *testing.T,
+	opts ...admin.LogLevelOption,
+) (admin.LogLevelService, *logger.Zap) {
+	config := setting.LoggerSettings{
+		FileName: filepath.Join(t.TempDir(), "app.log"),
+		Level:    "info",
+		MaxSize:  1,
+		Console:  setting.LogOutputSettings{Level: "error"},
+		Modules:  map[string]string{"mongodb": "warn"},
+	}
+	log := logger.NewLogger(config, setting.TestMode, "v-test", "unknown")
+	configured := func() setting.LoggerSettings { return config }
+	return admin.NewLogLevelService(log, configured, opts...), log
+}
+
+func TestLogLevelService(t *testing.T) {
+	t.Run("should report the current and configured levels", func(t *testing.T) {
+		service, _ := setupLogLevelService(t)
+
+		dto := service.GetLogLevel("mongodb")
+
+		assert.Equal(t, "warn", dto.Level)
+		assert.Equal(t, "warn", dto.ConfiguredLevel)
+		assert.Nil(t, dto.RevertAt)
+	})
+
+	t.Run("should change the level of the application logger", func(t *testing.T) {
+		service, log := setupLogLevelService(t)
+
+		dto := service.UpdateLogLevel(&admin.UpdateLogLevelDto{Level: "debug"})
+
+		assert.Equal(t, "debug", dto.Level)
+		assert.Equal(t, "info", dto.ConfiguredLevel)
+		assert.Equal(t, zapcore.DebugLevel, log.Level())
+	})
+
+	t.Run("should change the level of a module logger only", func(t *testing.T) {
+		service, log := setupLogLevelService(t)
+
+		service.UpdateLogLevel(&admin.UpdateLogLevelDto{Logger: "mongodb", Level: "debug"})
+
+		assert.Equal(t, zapcore.DebugLevel, log.ModuleLevel("mongodb"))
+		assert.Equal(t, zapcore.InfoLevel, log.Level())
+	})
+
+	t.Run("should revert to the configured level", func(t *testing.T) {
+		var timers fakeTimers
+		service, log := setupLogLevelService(t, admin.WithAfterFunc(timers.afterFunc))
+
+		dto := service.UpdateLogLevel(&admin.UpdateLogLevelDto{
+			Logger:      "mongodb",
+			Level:       "debug",
+			RevertAfter: 1,
+		})
+		assert.NotNil(t, dto.RevertAt)
+		require.Len(t, timers, 1)
+		assert.Equal(t, time.Second, timers[0].delay)
+
+		timers[0].fire()
+
+		assert.Equal(t, zapcore.WarnLevel, log.ModuleLevel("mongodb"))
+		assert.Nil(t, service.GetLogLevel("mongodb").RevertAt)
+	})
+
+	t.Run("should cancel the pending revert on a new change", func(t *testing.T) {
+		var timers fakeTimers
+		service, log := setupLogLevelService(t, admin.WithAfterFunc(timers.afterFunc))
+
+		service.UpdateLogLevel(&admin.UpdateLogLevelDto{Level: "debug", RevertAfter: 1})
+		dto := service.UpdateLogLevel(&admin.UpdateLogLevelDto{Level: "error"})
+		assert.Nil(t, dto.RevertAt)
+		require.Len(t, timers, 1)
+		assert.True(t, timers[0].stopped)
+
+		// A revert firing while it is being cancelled is ignored
+		timers[0].fire()
+		assert.Equal(t, zapcore.ErrorLevel, log.Level())
+	})
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/health"
+	"github.com/hainguyen27798/gin-boilerplate/internal/routes"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHealthRouter(checks map[string]health.Check) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	routes.RegisterHealthRoutes(r, health.NewHealthController(checks))
+	return r
+}
+
+// up and down are checks of an available and an unavailable dependency.
+func up(context.Context) error {
+	return nil
+}
+
+func down(context.Context) error {
+	return errors.New("connection refused")
+}
+
+func doGet(r *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestHealthController(t *testing.T) {
+	t.Run("should be alive whatever the dependencies", func(t *testing.T) {
+		r := setupHealthRouter(map[string]health.Check{"mongodb": down})
+
+		w := doGet(r, "/health/live")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should be ready when every dependency is available", func(t *testing.T) {
+		r := setupHealthRouter(map[string]health.Check{"mongodb": up})
+
+		w := doGet(r, "/health/ready")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("should not be ready when a dependency is unavailable", func(t *testing.T) {
+		r := setupHealthRouter(map[string]health.Check{
+			"mongodb": down,
+			"redis":   up,
+		})
+
+		w := doGet(r, "/health/ready")
+
+		var res response.TErrResponse[string]
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&res))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "SERVICE_UNAVAILABLE", res.ErrorCode)
+		assert.Equal(t, "unavailable: mongodb", res.Errors)
+	})
+}
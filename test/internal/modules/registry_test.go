@@ -0,0 +1,155 @@
+package modules
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeModule records the lifecycle hooks it goes through in a shared journal.
+type fakeModule struct {
+	module.Base
+	name     string
+	deps     []string
+	journal  *[]string
+	startErr error
+	stopErr  error
+}
+
+func (m *fakeModule) Name() string {
+	return m.name
+}
+
+func (m *fakeModule) Dependencies() []string {
+	return m.deps
+}
+
+func (m *fakeModule) Init(module.Deps) error {
+	*m.journal = append(*m.journal, "init "+m.name)
+	return nil
+}
+
+func (m *fakeModule) RegisterRoutes(*gin.Engine) {
+	*m.journal = append(*m.journal, "routes "+m.name)
+}
+
+func (m *fakeModule) Start(context.Context) error {
+	*m.journal = append(*m.journal, "start "+m.name)
+	return m.startErr
+}
+
+func (m *fakeModule) Stop(context.Context) error {
+	*m.journal = append(*m.journal, "stop "+m.name)
+	return m.stopErr
+}
+
+func newRegistry(t *testing.T, modules ...module.Module) *module.Registry {
+	registry := module.NewRegistry()
+	require.NoError(t, registry.Register(modules...))
+	return registry
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("should reject duplicate names", func(t *testing.T) {
+		var journal []string
+		registry := module.NewRegistry()
+
+		err := registry.Register(
+			&fakeModule{name: "users", journal: &journal},
+			&fakeModule{name: "users", journal: &journal},
+		)
+
+		assert.ErrorContains(t, err, `module "users" is already registered`)
+	})
+
+	t.Run("should run the hooks in dependency order", func(t *testing.T) {
+		var journal []string
+		registry := newRegistry(t,
+			&fakeModule{name: "users", deps: []string{"idempotency"}, journal: &journal},
+			&fakeModule{name: "admin", journal: &journal},
+			&fakeModule{name: "idempotency", journal: &journal},
+		)
+
+		require.NoError(t, registry.Init(module.Deps{}))
+		registry.RegisterRoutes(gin.New())
+		require.NoError(t, registry.Start(context.Background()))
+		require.NoError(t, registry.Stop(context.Background()))
+
+		assert.Equal(t, []string{
+			"init idempotency", "init users", "init admin",
+			"routes idempotency", "routes users", "routes admin",
+			"start idempotency", "start users", "start admin",
+			"stop admin", "stop users", "stop idempotency",
+		}, journal)
+	})
+
+	t.Run("should reject unknown dependencies", func(t *testing.T) {
+		var journal []string
+		registry := newRegistry(t, &fakeModule{name: "users", deps: []string{"mail"}, journal: &journal})
+
+		err := registry.Init(module.Deps{})
+
+		assert.ErrorContains(t, err, `module "users" depends on unknown module "mail"`)
+		assert.Empty(t, journal)
+	})
+
+	t.Run("should reject circular dependencies", func(t *testing.T) {
+		var journal []string
+		registry := newRegistry(t,
+			&fakeModule{name: "a", deps: []string{"b"}, journal: &journal},
+			&fakeModule{name: "b", deps: []string{"a"}, journal: &journal},
+		)
+
+		err := registry.Init(module.Deps{})
+
+		assert.ErrorContains(t, err, "circular module dependency: [a b a]")
+		assert.Empty(t, journal)
+	})
+
+	t.Run("should stop the started modules when one fails to start", func(t *testing.T) {
+		var journal []string
+		registry := newRegistry(t,
+			&fakeModule{name: "a", journal: &journal},
+			&fakeModule{name: "b", journal: &journal, startErr: errors.New("boom")},
+			&fakeModule{name: "c", journal: &journal},
+		)
+		require.NoError(t, registry.Init(module.Deps{}))
+		journal = nil
+
+		err := registry.Start(context.Background())
+
+		assert.ErrorContains(t, err, `start module "b": boom`)
+		assert.Equal(t, []string{"start a", "start b", "stop a"}, journal)
+	})
+
+	t.Run("should stop every module and join the errors", func(t *testing.T) {
+		var journal []string
+		registry := newRegistry(t,
+			&fakeModule{name: "a", journal: &journal, stopErr: errors.New("boom")},
+			&fakeModule{name: "b", journal: &journal, stopErr: errors.New("bang")},
+		)
+		require.NoError(t, registry.Init(module.Deps{}))
+		require.NoError(t, registry.Start(context.Background()))
+
+		err := registry.Stop(context.Background())
+
+		assert.ErrorContains(t, err, `stop module "a": boom`)
+		assert.ErrorContains(t, err, `stop module "b": bang`)
+	})
+
+	t.Run("should expose the health check of every module", func(t *testing.T) {
+		var journal []string
+		registry := newRegistry(t, &fakeModule{name: "users", journal: &journal})
+		require.NoError(t, registry.Init(module.Deps{}))
+
+		checks := registry.HealthChecks()
+
+		require.Contains(t, checks, "users")
+		assert.NoError(t, checks["users"](context.Background()))
+	})
+}
@@ -3,304 +3,665 @@ package users
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
-	"github.com/gin-gonic/gin"
-	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
+	"errors"
 	"net/http"
-	"net/http/httptest"
-	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
-	"github.com/hainguyen27798/gin-boilerplate/global"
-	"github.com/hainguyen27798/gin-boilerplate/internal/initialize"
-	"github.com/hainguyen27798/gin-boilerplate/pkg/helpers"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/jobs"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"github.com/hainguyen27798/gin-boilerplate/test/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
-func setupTestEnvironment(t *testing.T) (*users.UserController, users.UserService) {
-	initialize.RegisterValidations()
-	helpers.Must(os.Setenv("MODE", "test"))
-	defer func() {
-		helpers.Must(os.Unsetenv("MODE"))
-	}()
+func newCreateUserDto() users.CreateUserDto {
+	return users.CreateUserDto{
+		FirstName: "John",
+		LastName:  "Doe",
+		Email:     "test@example.com",
+		Password:  "StrongPass123!",
+	}
+}
+
+func TestUserController_CreateUser(t *testing.T) {
+	t.Run("successful user creation", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		userData := newCreateUserDto()
+
+		var created users.UserDto
+		res := app.Client(t).Post("/v1/users").JSON(userData).
+			ExpectStatus(http.StatusCreated).
+			DecodeData(&created)
+
+		assert.NotContains(t, res.Body.String(), "password")
+		assert.NotContains(t, res.Body.String(), "verification_code")
+		assert.Equal(t, userData.Email, created.Email)
+		assert.Equal(t, userData.FirstName, created.FirstName)
+		assert.Equal(t, userData.LastName, created.LastName)
+		assert.False(t, created.Verified)
+
+		stored, err := app.Users.FindByEmail(t.Context(), userData.Email)
+		require.Nil(t, err)
+		assert.NotEqual(t, userData.Password, stored.Password)
+	})
+
+	t.Run("invalid user data", func(t *testing.T) {
+		app := testutil.NewApp(t)
+
+		violations := app.Client(t).Post("/v1/users").
+			JSON(users.CreateUserDto{
+				LastName: "Doe",
+				Email:    "invalid-email",
+				Password: "weak",
+			}).
+			ExpectStatus(http.StatusBadRequest).
+			ExpectErrorCode("VALIDATION_ERROR").
+			DecodeViolations()
+
+		rules := make([]string, 0, len(violations))
+		for _, v := range violations {
+			rules = append(rules, v.Field+":"+v.Rule)
+		}
+		assert.Contains(t, rules, "first_name:required")
+		assert.Contains(t, rules, "email:email")
+		assert.Contains(t, rules, "password:strongPassword")
+	})
 
-	initialize.LoadConfig("../../../../configs/")
-	initialize.InitLogger()
-	initialize.InitDatabase()
+	t.Run("duplicate email", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		existing := app.CreateUser(t)
+		userData := newCreateUserDto()
+		userData.Email = existing.Email
 
-	repo := users.NewUserRepository(global.MongoDB.DB)
-	userService := users.NewUserService(repo)
-	userController := users.NewUserController(userService)
+		app.Client(t).Post("/v1/users").JSON(userData).
+			ExpectStatus(http.StatusConflict).
+			ExpectErrorCode(users.ErrCodeEmailTaken)
+	})
 
-	collection := global.MongoDB.DB.Collection(users.UserModel{}.CollectionName())
-	if err := collection.Drop(context.Background()); err != nil {
-		t.Fatal(err)
-	}
+	t.Run("not acceptable", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		userData := newCreateUserDto()
 
-	return userController, userService
+		app.Client(t).Post("/v1/users").JSON(userData).
+			Header("Accept", "text/html").
+			ExpectStatus(http.StatusNotAcceptable).
+			ExpectErrorCode("NOT_ACCEPTABLE")
+
+		_, err := app.Users.FindByEmail(t.Context(), userData.Email)
+		assert.NotNil(t, err)
+	})
 }
 
-func TestUserController_CreateUser(t *testing.T) {
-	initialize.RegisterValidations()
-	controller, _ := setupTestEnvironment(t)
+func TestUserController_GetUserByID(t *testing.T) {
+	t.Run("get existing user", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		user := app.CreateUser(t)
 
-	defer func() {
-		if global.MongoDB != nil {
-			_ = global.MongoDB.Disconnect(context.Background())
-		}
-	}()
+		var found users.UserDto
+		app.Client(t).Get("/v1/users/" + user.ID.Hex()).
+			ExpectStatus(http.StatusOK).
+			DecodeData(&found)
 
-	t.Run("successful user creation", func(t *testing.T) {
-		userData := users.CreateUserDto{
-			FirstName: "John",
-			LastName:  "Doe",
-			Email:     "test@example.com",
-			Password:  "StrongPass123!",
-		}
-		jsonData, _ := json.Marshal(userData)
+		assert.Equal(t, user.ID.Hex(), found.ID)
+		assert.Equal(t, user.Email, found.Email)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-		req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
+		app.Client(t).Get("/v1/users/" + bson.NewObjectID().Hex()).
+			ExpectStatus(http.StatusNotFound).
+			ExpectErrorCode(users.ErrCodeUserNotFound)
+	})
+
+	t.Run("invalid user id format", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-		c, _ := gin.CreateTestContext(w)
-		c.Request = req
+		app.Client(t).Get("/v1/users/invalid-id").ExpectStatus(http.StatusBadRequest)
+	})
 
-		controller.CreateUser(c)
+	t.Run("selected fields", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		user := app.CreateUser(t)
 
-		var res response.TDataResponse
-		err := json.NewDecoder(w.Body).Decode(&res)
-		assert.NoError(t, err)
-		assert.Equal(t, http.StatusCreated, w.Code)
-		assert.Equal(t, response.CreatedSuccess, res.Code)
-		assert.Equal(t, response.CodeMsg[response.CreatedSuccess], res.Message)
+		var found map[string]any
+		app.Client(t).Get("/v1/users/"+user.ID.Hex()).Query("fields", "id,first_name").
+			ExpectStatus(http.StatusOK).
+			DecodeData(&found)
 
-		responseData := res.Data.(map[string]interface{})
-		assert.Equal(t, userData.Email, responseData["email"])
-		assert.Equal(t, userData.FirstName, responseData["first_name"])
-		assert.Equal(t, userData.LastName, responseData["last_name"])
-		assert.Empty(t, responseData["password"])
-		assert.Empty(t, responseData["verification_code"])
-		assert.False(t, responseData["verified"].(bool))
+		assert.Equal(t, map[string]any{"id": user.ID.Hex(), "first_name": user.FirstName}, found)
 	})
 
-	t.Run("invalid user data", func(t *testing.T) {
-		userData := users.CreateUserDto{
-			FirstName: "",
-			LastName:  "Doe",
-			Email:     "invalid-email",
-			Password:  "weak",
+	t.Run("hidden or unknown fields", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		user := app.CreateUser(t)
+
+		for _, fields := range []string{"password", "id,verification_code", "email.domain"} {
+			app.Client(t).Get("/v1/users/"+user.ID.Hex()).Query("fields", fields).
+				ExpectStatus(http.StatusBadRequest).
+				ExpectErrorCode(response.ErrCodeInvalidFields)
 		}
-		jsonData, _ := json.Marshal(userData)
+	})
+}
 
-		req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
+func TestUserController_UpdateUser(t *testing.T) {
+	t.Run("successful update", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		user := app.CreateUser(t)
+
+		var updated users.UserDto
+		app.Client(t).Put("/v1/users/" + user.ID.Hex()).
+			JSON(users.UpdateUserDto{FirstName: "Updated John", LastName: "Updated Doe"}).
+			ExpectStatus(http.StatusOK).
+			DecodeData(&updated)
+
+		assert.Equal(t, "Updated John", updated.FirstName)
+		assert.Equal(t, "Updated Doe", updated.LastName)
+		assert.Equal(t, user.Email, updated.Email)
+	})
 
-		c, _ := gin.CreateTestContext(w)
-		c.Request = req
+	t.Run("unknown user", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-		controller.CreateUser(c)
+		app.Client(t).Put("/v1/users/" + bson.NewObjectID().Hex()).
+			JSON(users.UpdateUserDto{FirstName: "Jane"}).
+			ExpectStatus(http.StatusNotFound)
+	})
+}
 
-		var res response.TErrResponse
-		err := json.NewDecoder(w.Body).Decode(&res)
-		assert.NoError(t, err)
-		assert.Equal(t, http.StatusBadRequest, w.Code)
-		assert.Equal(t, response.ErrCodeParamInvalid, res.Code)
-		assert.Equal(t, response.CodeMsg[response.ErrCodeParamInvalid], res.Message)
+func TestUserController_DeleteUser(t *testing.T) {
+	t.Run("successful deletion", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		client := app.Client(t)
+		user := app.CreateUser(t)
 
-		errors := res.Errors.([]interface{})
-		assert.Contains(t, errors, "Field FirstName is required")
-		assert.Contains(t, errors, "Fields Email is invalid due to email")
-		assert.Contains(t, errors, "Fields Password is invalid due to strongPassword")
+		client.Delete("/v1/users/" + user.ID.Hex()).ExpectStatus(http.StatusOK)
+		client.Get("/v1/users/" + user.ID.Hex()).ExpectStatus(http.StatusNotFound)
 	})
-}
 
-func TestUserController_GetUserByID(t *testing.T) {
-	controller, service := setupTestEnvironment(t)
+	t.Run("unknown user", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-	t.Run("get existing user", func(t *testing.T) {
-		userData := users.CreateUserDto{
-			FirstName: "John",
-			LastName:  "Doe",
-			Email:     "test@example.com",
-			Password:  "StrongPass123!",
-		}
-		ctx := context.Background()
-		userCreated, err := service.CreateUser(ctx, &userData)
-		assert.NoError(t, err)
+		app.Client(t).Delete("/v1/users/" + bson.NewObjectID().Hex()).
+			ExpectStatus(http.StatusNotFound)
+	})
+}
 
-		req := httptest.NewRequest(http.MethodGet, "/users/:id", nil)
-		w := httptest.NewRecorder()
+func TestUserController_GetUserByEmail(t *testing.T) {
+	t.Run("successful email lookup", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		user := app.CreateUser(t)
 
-		c, _ := gin.CreateTestContext(w)
-		c.Params = []gin.Param{{Key: "id", Value: userCreated.ID}}
-		c.Request = req
+		var found users.UserDto
+		app.Client(t).Get("/v1/users").Query("email", user.Email).
+			ExpectStatus(http.StatusOK).
+			DecodeData(&found)
 
-		controller.GetUserByID(c)
+		assert.Equal(t, user.ID.Hex(), found.ID)
+	})
 
-		var res response.TDataResponse
-		err = json.NewDecoder(w.Body).Decode(&res)
-		assert.NoError(t, err)
-		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Equal(t, response.CodeSuccess, res.Code)
-		assert.Equal(t, response.CodeMsg[response.CodeSuccess], res.Message)
+	t.Run("missing email parameter", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-		responseData := res.Data.(map[string]interface{})
-		assert.Equal(t, userData.Email, responseData["email"])
-		assert.Equal(t, userData.FirstName, responseData["first_name"])
-		assert.Equal(t, userData.LastName, responseData["last_name"])
+		app.Client(t).Get("/v1/users").ExpectStatus(http.StatusBadRequest)
 	})
+}
 
-	t.Run("invalid user id format", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodGet, "/users/:id", nil)
-		w := httptest.NewRecorder()
+func TestUserController_SearchUsers(t *testing.T) {
+	t.Run("ranked and paginated results", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		app.CreateUser(t, func(user *users.UserModel) {
+			user.Email, user.FirstName = "jane.doe@example.com", "Jane"
+		})
+		app.CreateUser(t, func(user *users.UserModel) {
+			user.FirstName, user.LastName = "Mary", "Jane"
+		})
+
+		var page common.Page[users.UserSearchHit]
+		app.Client(t).Get("/v1/users/search").Query("q", "jane").Query("limit", "1").AsStaff().
+			ExpectStatus(http.StatusOK).
+			DecodeData(&page)
+
+		assert.EqualValues(t, 2, page.Total)
+		assert.Equal(t, 1, page.Limit)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, "jane.doe@example.com", page.Items[0].User.Email)
+		assert.Equal(t, []users.Highlight{
+			{Field: "email", Ranges: []users.TextRange{{Start: 0, End: 4}}},
+			{Field: "first_name", Ranges: []users.TextRange{{Start: 0, End: 4}}},
+		}, page.Items[0].Highlights)
+	})
+
+	t.Run("restricted to the staff", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-		c, _ := gin.CreateTestContext(w)
-		c.Params = []gin.Param{{Key: "id", Value: "invalid-id"}}
-		c.Request = req
+		app.Client(t).Get("/v1/users/search").Query("q", "jane").
+			ExpectStatus(http.StatusUnauthorized)
+		app.Client(t).Get("/v1/users/search").Query("q", "jane").Bearer("user-token").
+			ExpectStatus(http.StatusUnauthorized)
+		app.Client(t).Get("/v1/users/search").Query("q", "jane").AsAdmin().
+			ExpectStatus(http.StatusUnauthorized)
+	})
 
-		controller.GetUserByID(c)
+	t.Run("invalid query", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-		var res response.TResponse
-		err := json.NewDecoder(w.Body).Decode(&res)
-		assert.NoError(t, err)
-		assert.Equal(t, http.StatusBadRequest, w.Code)
-		assert.Equal(t, response.ErrCodeQueryParamInvalid, res.Code)
-		assert.Equal(t, response.CodeMsg[response.ErrCodeQueryParamInvalid], res.Message)
+		app.Client(t).Get("/v1/users/search").AsStaff().
+			ExpectStatus(http.StatusBadRequest).
+			ExpectErrorCode("VALIDATION_ERROR")
+		app.Client(t).Get("/v1/users/search").Query("q", "jane").Query("limit", "1000").AsStaff().
+			ExpectStatus(http.StatusBadRequest).
+			ExpectErrorCode("VALIDATION_ERROR")
 	})
 }
 
-func TestUserController_UpdateUser(t *testing.T) {
-	controller, service := setupTestEnvironment(t)
+// partialUserRepository is a user repository whose first batch insert fails
+// after inserting its first user.
+type partialUserRepository struct {
+	users.UserRepository
+	failed atomic.Bool
+}
 
-	t.Run("successful update", func(t *testing.T) {
-		// Create test user
-		userData := users.CreateUserDto{
-			FirstName: "John",
-			LastName:  "Doe",
-			Email:     "test@example.com",
-			Password:  "StrongPass123!",
+// CreateMany implements users.UserRepository.
+func (r *partialUserRepository) CreateMany(
+	ctx context.Context,
+	batch []*users.UserModel,
+) (map[int]*response.Error, *response.Error) {
+	if r.failed.Swap(true) {
+		return r.UserRepository.CreateMany(ctx, batch)
+	}
+	if _, err := r.UserRepository.CreateMany(ctx, batch[:1]); err != nil {
+		return nil, err
+	}
+	return nil, response.NewError(response.ErrInternalError, errors.New("connection lost"))
+}
+
+// recordingJobStore records the payloads of the jobs enqueued on the store.
+type recordingJobStore struct {
+	jobs.Store
+	mu       sync.Mutex
+	recorded [][]byte
+}
+
+func (s *recordingJobStore) Enqueue(ctx context.Context, job *jobs.Job) error {
+	s.mu.Lock()
+	s.recorded = append(s.recorded, bytes.Clone(job.Payload.Value))
+	s.mu.Unlock()
+	return s.Store.Enqueue(ctx, job)
+}
+
+// payloads returns the payloads of the jobs enqueued so far.
+func (s *recordingJobStore) payloads() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.recorded)
+}
+
+// awaitImport polls the import until it ends.
+func awaitImport(t *testing.T, app *testutil.App, id string) users.ImportJobDto {
+	t.Helper()
+	var job users.ImportJobDto
+	require.Eventually(t, func() bool {
+		app.Client(t).Get("/v1/users/import/" + id).AsStaff().
+			ExpectStatus(http.StatusOK).
+			DecodeData(&job)
+		return job.FinishedAt != nil
+	}, 10*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestUserController_ImportUsers(t *testing.T) {
+	t.Run("CSV upload with per-row errors", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		existing := app.CreateUser(t)
+		csv := "email,first_name,last_name,password\n" +
+			"Jane@Example.com,Jane,Doe,StrongPass123!\n" +
+			"invalid-email,John,Doe,StrongPass123!\n" +
+			existing.Email + ",John,Doe,StrongPass123!\n" +
+			"jane@example.com,Jane,Twice,StrongPass123!\n" +
+			"short@example.com,Short,Row\n"
+
+		var started users.ImportJobDto
+		app.Client(t).Post("/v1/users/import").Body(users.MIMECSV, []byte(csv)).AsStaff().
+			ExpectStatus(http.StatusAccepted).
+			DecodeData(&started)
+		assert.Equal(t, users.FormatCSV, started.Format)
+		assert.Equal(t, 5, started.Total)
+
+		job := awaitImport(t, app, started.ID)
+		assert.Equal(t, users.ImportCompleted, job.Status)
+		assert.Equal(t, 5, job.Processed)
+		assert.InDelta(t, 100, job.Progress, 0.001)
+		assert.Equal(t, 1, job.Inserted)
+		assert.Equal(t, 4, job.Failed)
+
+		failures := map[int]string{}
+		for _, rowErr := range job.Errors {
+			require.NotEmpty(t, rowErr.Violations)
+			failures[rowErr.Line] = rowErr.Violations[0].Field + ":" + rowErr.Violations[0].Rule
 		}
-		ctx := context.Background()
-		userCreated, err := service.CreateUser(ctx, &userData)
-		assert.NoError(t, err)
-
-		// Update user
-		updateData := users.UpdateUserDto{
-			FirstName: "Updated John",
-			LastName:  "Updated Doe",
+		assert.Equal(t, map[int]string{
+			3: "email:email",
+			4: "email:" + users.ErrCodeEmailTaken,
+			5: "email:" + users.ErrCodeEmailTaken,
+			6: ":format",
+		}, failures)
+
+		imported, err := app.Users.FindByEmail(t.Context(), "jane@example.com")
+		require.Nil(t, err)
+		assert.Equal(t, "Doe", imported.LastName)
+		assert.False(t, imported.Verified)
+		assert.NotEqual(t, "StrongPass123!", imported.Password)
+	})
+
+	t.Run("no plaintext password in the queued jobs", func(t *testing.T) {
+		store := &recordingJobStore{Store: jobs.NewMemoryStore()}
+		app := testutil.NewApp(t, testutil.WithJobStore(store))
+		csv := "email,first_name,last_name,password\n" +
+			"jane@example.com,Jane,Doe,StrongPass123!\n" +
+			"john@example.com,John,Doe,Tiny1!\n"
+
+		var started users.ImportJobDto
+		app.Client(t).Post("/v1/users/import").Body(users.MIMECSV, []byte(csv)).AsStaff().
+			ExpectStatus(http.StatusAccepted).
+			DecodeData(&started)
+		job := awaitImport(t, app, started.ID)
+		assert.Equal(t, 1, job.Inserted)
+
+		payloads := store.payloads()
+		require.Len(t, payloads, 1)
+		for _, password := range []string{"StrongPass123!", "Tiny1!"} {
+			assert.NotContains(t, string(payloads[0]), password)
 		}
-		jsonData, _ := json.Marshal(updateData)
 
-		req := httptest.NewRequest(http.MethodPut, "/users/:id", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
+		// The staged rows are deleted once imported.
+		batchID, ok := bson.Raw(payloads[0]).Lookup("batch_id").StringValueOK()
+		require.True(t, ok)
+		_, err := app.UserImports.FindBatch(t.Context(), batchID)
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeImportNotFound, err.ErrorCode())
+	})
 
-		c, _ := gin.CreateTestContext(w)
-		c.Params = []gin.Param{{Key: "id", Value: userCreated.ID}}
-		c.Request = req
+	t.Run("retry of a partially inserted batch", func(t *testing.T) {
+		repo := &partialUserRepository{UserRepository: users.NewMemoryUserRepository()}
+		app := testutil.NewApp(t,
+			testutil.WithUserRepository(repo),
+			testutil.WithConfig(func(config *setting.Config) {
+				config.Jobs.PollInterval = 10 * time.Millisecond
+				config.Jobs.RetryBackoff = time.Millisecond
+			}),
+		)
+		csv := "email,first_name,last_name,password\n" +
+			"jane@example.com,Jane,Doe,StrongPass123!\n" +
+			"john@example.com,John,Doe,StrongPass123!\n"
+
+		var started users.ImportJobDto
+		app.Client(t).Post("/v1/users/import").Body(users.MIMECSV, []byte(csv)).AsStaff().
+			ExpectStatus(http.StatusAccepted).
+			DecodeData(&started)
+
+		job := awaitImport(t, app, started.ID)
+		assert.Equal(t, users.ImportCompleted, job.Status)
+		assert.Equal(t, 2, job.Inserted)
+		assert.Zero(t, job.Failed)
+		assert.Empty(t, job.Errors)
+		assert.True(t, repo.failed.Load())
+		for _, email := range []string{"jane@example.com", "john@example.com"} {
+			_, err := app.Users.FindByEmail(t.Context(), email)
+			require.Nil(t, err)
+		}
+	})
 
-		controller.UpdateUser(c)
+	t.Run("NDJSON upload", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		ndjson := `{"email":"a@example.com","first_name":"A","last_name":"Doe",` +
+			`"password":"StrongPass123!"}` + "\n\n" +
+			`{"email":"b@example.com","first_name":"B","last_name":"Doe","password":"weak"}` + "\n" +
+			`{"email":"c@example.com","nickname":"C"}` + "\n"
+
+		var started users.ImportJobDto
+		app.Client(t).Post("/v1/users/import").Body("application/x-ndjson", []byte(ndjson)).
+			AsStaff().
+			ExpectStatus(http.StatusAccepted).
+			DecodeData(&started)
+
+		job := awaitImport(t, app, started.ID)
+		assert.Equal(t, 3, job.Total)
+		assert.Equal(t, 1, job.Inserted)
+		require.Len(t, job.Errors, 2)
+		assert.Equal(t, 3, job.Errors[0].Line)
+		assert.Equal(t, "password", job.Errors[0].Violations[0].Field)
+		assert.Equal(t, 4, job.Errors[1].Line)
+		assert.Equal(t, "format", job.Errors[1].Violations[0].Rule)
+	})
 
-		var res response.TDataResponse
-		err = json.NewDecoder(w.Body).Decode(&res)
-		assert.NoError(t, err)
-		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Equal(t, response.CodeSuccess, res.Code)
-		assert.Equal(t, response.CodeMsg[response.CodeSuccess], res.Message)
+	t.Run("invalid uploads", func(t *testing.T) {
+		app := testutil.NewApp(t)
+
+		app.Client(t).Post("/v1/users/import").Body("text/plain", []byte("a@example.com")).
+			AsStaff().
+			ExpectStatus(http.StatusUnsupportedMediaType)
+		app.Client(t).Post("/v1/users/import").Body(users.MIMECSV, []byte("email,nickname\n")).
+			AsStaff().
+			ExpectStatus(http.StatusBadRequest).
+			ExpectErrorCode(users.ErrCodeInvalidImport)
+		app.Client(t).Post("/v1/users/import").Body(users.MIMECSV, []byte("email,first_name\n")).
+			AsStaff().
+			ExpectStatus(http.StatusBadRequest).
+			ExpectErrorCode(users.ErrCodeInvalidImport)
+		app.Client(t).Post("/v1/users/import").Body(users.MIMENDJSON, nil).
+			AsStaff().
+			ExpectStatus(http.StatusBadRequest).
+			ExpectErrorCode(users.ErrCodeInvalidImport)
+
+		tooLarge := bytes.Repeat([]byte("{}\n"), users.MaxImportRows+1)
+		app.Client(t).Post("/v1/users/import").Body(users.MIMENDJSON, tooLarge).
+			AsStaff().
+			ExpectStatus(http.StatusRequestEntityTooLarge).
+			ExpectErrorCode(users.ErrCodeImportTooLarge)
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		app := testutil.NewApp(t)
+
+		app.Client(t).Post("/v1/users/import").Body(users.MIMECSV, []byte("email\nann@acme.example\n")).
+			Header("Accept", "text/html").
+			AsStaff().
+			ExpectStatus(http.StatusNotAcceptable).
+			ExpectErrorCode("NOT_ACCEPTABLE")
+	})
+
+	t.Run("unknown import", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-		responseData := res.Data.(map[string]interface{})
-		assert.Equal(t, updateData.FirstName, responseData["first_name"])
-		assert.Equal(t, updateData.LastName, responseData["last_name"])
+		app.Client(t).Get("/v1/users/import/" + bson.NewObjectID().Hex()).AsStaff().
+			ExpectStatus(http.StatusNotFound).
+			ExpectErrorCode(users.ErrCodeImportNotFound)
+	})
+
+	t.Run("restricted to the staff", func(t *testing.T) {
+		app := testutil.NewApp(t)
+
+		app.Client(t).Post("/v1/users/import").Body(users.MIMECSV, []byte("email\n")).
+			ExpectStatus(http.StatusUnauthorized)
+		app.Client(t).Get("/v1/users/import/" + bson.NewObjectID().Hex()).
+			Bearer("user-token").
+			ExpectStatus(http.StatusUnauthorized)
 	})
 }
 
-func TestUserController_DeleteUser(t *testing.T) {
-	controller, service := setupTestEnvironment(t)
+func TestUserController_ExportUsers(t *testing.T) {
+	t.Run("CSV export", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		user := app.CreateUser(t, func(user *users.UserModel) {
+			user.FirstName = "=HYPERLINK(\"http://evil.example\")"
+		})
+
+		res := app.Client(t).Get("/v1/users/export").AsStaff().
+			Header("Accept", "text/csv").
+			ExpectStatus(http.StatusOK).
+			ExpectHeader("Content-Type", "text/csv; charset=utf-8").
+			ExpectHeader("Content-Disposition", `attachment; filename="users.csv"`)
+
+		records, err := csv.NewReader(res.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, []string{
+			"id", "email", "first_name", "last_name", "image", "verified", "created_at", "updated_at",
+		}, records[0])
+		assert.Equal(t, user.ID.Hex(), records[1][0])
+		assert.Equal(t, user.Email, records[1][1])
+		assert.Equal(t, "'"+user.FirstName, records[1][2])
+		assert.Equal(t, "true", records[1][5])
+	})
 
-	t.Run("successful deletion", func(t *testing.T) {
-		userData := users.CreateUserDto{
-			FirstName: "John",
-			LastName:  "Doe",
-			Email:     "test@example.com",
-			Password:  "StrongPass123!",
-		}
-		ctx := context.Background()
-		userCreated, err := service.CreateUser(ctx, &userData)
-		assert.NoError(t, err)
+	t.Run("filtered NDJSON export", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		since := time.Now().UTC().Add(-time.Hour)
+		app.CreateUser(t, func(user *users.UserModel) {
+			user.Email = "old@acme.example"
+			user.CreatedAt = since.Add(-time.Hour)
+		})
+		app.CreateUser(t, func(user *users.UserModel) {
+			user.Email, user.Verified = "unverified@acme.example", false
+		})
+		app.CreateUser(t, func(user *users.UserModel) { user.Email = "other@example.com" })
+		match := app.CreateUser(t, func(user *users.UserModel) { user.Email = "new@acme.example" })
+
+		res := app.Client(t).Get("/v1/users/export").
+			Query("format", "ndjson").
+			Query("verified", "true").
+			Query("email_domain", "ACME.example").
+			Query("created_from", since.Format(time.RFC3339)).
+			AsStaff().
+			ExpectStatus(http.StatusOK).
+			ExpectHeader("Content-Type", users.MIMENDJSON)
+
+		lines := strings.Split(strings.TrimSpace(res.Body.String()), "\n")
+		require.Len(t, lines, 1)
+		var exported users.UserDto
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &exported))
+		assert.Equal(t, match.ID.Hex(), exported.ID)
+		assert.NotContains(t, lines[0], "password")
+	})
 
-		req := httptest.NewRequest(http.MethodDelete, "/users/:id", nil)
-		w := httptest.NewRecorder()
+	t.Run("empty CSV export keeps its header", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-		c, _ := gin.CreateTestContext(w)
-		c.Params = []gin.Param{{Key: "id", Value: userCreated.ID}}
-		c.Request = req
+		res := app.Client(t).Get("/v1/users/export").AsStaff().ExpectStatus(http.StatusOK)
+		assert.Equal(t, "id,email,first_name,last_name,image,verified,created_at,updated_at\n",
+			res.Body.String())
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		app := testutil.NewApp(t)
+
+		app.Client(t).Get("/v1/users/export").Query("format", "xml").AsStaff().
+			ExpectStatus(http.StatusBadRequest).
+			ExpectErrorCode("VALIDATION_ERROR")
+		app.Client(t).Get("/v1/users/export").Query("created_from", "yesterday").AsStaff().
+			ExpectStatus(http.StatusBadRequest)
+		app.Client(t).Get("/v1/users/export").
+			Query("created_from", "2026-02-01T00:00:00Z").
+			Query("created_to", "2026-01-01T00:00:00Z").
+			AsStaff().
+			ExpectStatus(http.StatusBadRequest).
+			ExpectErrorCode("VALIDATION_ERROR")
+	})
 
-		controller.DeleteUser(c)
+	t.Run("restricted to the staff", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-		var res response.TResponse
-		err = json.NewDecoder(w.Body).Decode(&res)
-		assert.NoError(t, err)
-		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Equal(t, response.CodeSuccess, res.Code)
-		assert.Equal(t, response.CodeMsg[response.CodeSuccess], res.Message)
+		app.Client(t).Get("/v1/users/export").ExpectStatus(http.StatusUnauthorized)
 	})
 }
 
-func TestUserController_GetUserByEmail(t *testing.T) {
-	controller, service := setupTestEnvironment(t)
+func TestUserController_Login(t *testing.T) {
+	t.Run("valid credentials", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		user := app.CreateUser(t)
 
-	t.Run("successful email lookup", func(t *testing.T) {
-		userData := users.CreateUserDto{
-			FirstName: "John",
-			LastName:  "Doe",
-			Email:     "test@example.com",
-			Password:  "StrongPass123!",
-		}
-		ctx := context.Background()
-		_, err := service.CreateUser(ctx, &userData)
-		assert.NoError(t, err)
+		var logged users.UserDto
+		app.Client(t).Post("/v1/users/login").
+			JSON(users.LoginDto{Email: user.Email, Password: testutil.UserPassword}).
+			ExpectStatus(http.StatusOK).
+			DecodeData(&logged)
 
-		req := httptest.NewRequest(http.MethodGet, "/users", nil)
-		w := httptest.NewRecorder()
+		assert.Equal(t, user.ID.Hex(), logged.ID)
+	})
 
-		c, _ := gin.CreateTestContext(w)
-		c.Request = req
-		q := c.Request.URL.Query()
-		q.Add("email", userData.Email)
-		c.Request.URL.RawQuery = q.Encode()
+	t.Run("wrong password", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		user := app.CreateUser(t)
 
-		controller.GetUserByEmail(c)
+		app.Client(t).Post("/v1/users/login").
+			JSON(users.LoginDto{Email: user.Email, Password: "WrongPass123!"}).
+			ExpectStatus(http.StatusUnauthorized).
+			ExpectErrorCode(users.ErrCodeInvalidCredential)
+	})
 
-		var res response.TDataResponse
-		err = json.NewDecoder(w.Body).Decode(&res)
-		assert.NoError(t, err)
-		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Equal(t, response.CodeSuccess, res.Code)
-		assert.Equal(t, response.CodeMsg[response.CodeSuccess], res.Message)
+	t.Run("unknown email", func(t *testing.T) {
+		app := testutil.NewApp(t)
 
-		responseData := res.Data.(map[string]interface{})
-		assert.Equal(t, userData.Email, responseData["email"])
-		assert.Equal(t, userData.FirstName, responseData["first_name"])
-		assert.Equal(t, userData.LastName, responseData["last_name"])
+		app.Client(t).Post("/v1/users/login").
+			JSON(users.LoginDto{Email: "unknown@example.com", Password: testutil.UserPassword}).
+			ExpectStatus(http.StatusUnauthorized).
+			ExpectErrorCode(users.ErrCodeInvalidCredential)
 	})
 
-	t.Run("missing email parameter", func(t *testing.T) {
-		req := httptest.NewRequest(http.MethodGet, "/users", nil)
-		w := httptest.NewRecorder()
+	t.Run("repository failure", func(t *testing.T) {
+		app := testutil.NewApp(t, testutil.WithUserRepository(failingUserRepository{
+			UserRepository: users.NewMemoryUserRepository(),
+		}))
 
-		c, _ := gin.CreateTestContext(w)
-		c.Request = req
+		app.Client(t).Post("/v1/users/login").
+			JSON(users.LoginDto{Email: "user@example.com", Password: testutil.UserPassword}).
+			ExpectStatus(http.StatusInternalServerError)
+	})
+}
 
-		controller.GetUserByEmail(c)
+func TestUserModule_Start(t *testing.T) {
+	t.Run("starts when the indexes cannot be created", func(t *testing.T) {
+		app := testutil.NewApp(t, testutil.WithUserRepository(unindexedUserRepository{
+			UserRepository: users.NewMemoryUserRepository(),
+		}))
 
-		var res response.TResponse
-		err := json.NewDecoder(w.Body).Decode(&res)
-		assert.NoError(t, err)
-		assert.Equal(t, http.StatusBadRequest, w.Code)
-		assert.Equal(t, response.ErrCodeParamInvalid, res.Code)
-		assert.Equal(t, response.CodeMsg[response.ErrCodeParamInvalid], res.Message)
+		app.Client(t).Post("/v1/users").
+			JSON(newCreateUserDto()).
+			ExpectStatus(http.StatusCreated)
 	})
 }
+
+// unindexedUserRepository is a user repository whose indexes cannot be created.
+type unindexedUserRepository struct {
+	users.UserRepository
+}
+
+// EnsureIndexes implements users.UserRepository.
+func (unindexedUserRepository) EnsureIndexes(context.Context) error {
+	return errors.New("duplicate emails must be merged first")
+}
+
+// failingUserRepository is a user repository whose lookups by email fail.
+type failingUserRepository struct {
+	users.UserRepository
+}
+
+// FindByEmail implements users.UserRepository.
+func (failingUserRepository) FindByEmail(
+	context.Context,
+	string,
+) (*users.UserModel, *response.Error) {
+	return nil, response.NewError(response.ErrInternalError, errors.New("connection lost"))
+}
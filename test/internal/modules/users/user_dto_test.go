@@ -3,15 +3,17 @@ package users
 import (
 	"errors"
 	"github.com/go-playground/validator/v10"
-	"github.com/hainguyen27798/gin-boilerplate/internal/initialize"
 	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
 	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/validations"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
 )
 
 func TestCreateUserDto_Validate(t *testing.T) {
-	initialize.RegisterValidations()
+	validate, err := validations.NewValidator()
+	require.NoError(t, err)
 
 	t.Run("valid user dto", func(t *testing.T) {
 		dto := users.CreateUserDto{
@@ -20,7 +22,7 @@ func TestCreateUserDto_Validate(t *testing.T) {
 			LastName:  "Doe",
 			Password:  "StrongPass123!",
 		}
-		err := dto.Validate()
+		err := dto.Validate(validate)
 		if ok := assert.NoError(t, err); !ok {
 			panic(err)
 		}
@@ -28,7 +30,7 @@ func TestCreateUserDto_Validate(t *testing.T) {
 
 	t.Run("missing required fields", func(t *testing.T) {
 		dto := users.CreateUserDto{}
-		err := dto.Validate()
+		err := dto.Validate(validate)
 		assert.Error(t, err)
 	})
 
@@ -39,7 +41,7 @@ func TestCreateUserDto_Validate(t *testing.T) {
 			LastName:  "Doe",
 			Password:  "StrongPass123!",
 		}
-		err := dto.Validate()
+		err := dto.Validate(validate)
 		var validationErrs validator.ValidationErrors
 		assert.Error(t, err)
 		errors.As(err, &validationErrs)
@@ -55,7 +57,7 @@ func TestCreateUserDto_Validate(t *testing.T) {
 			Password:  "StrongPass123!",
 			Image:     "invalid-url",
 		}
-		err := dto.Validate()
+		err := dto.Validate(validate)
 		var validationErrs validator.ValidationErrors
 		assert.Error(t, err)
 		errors.As(err, &validationErrs)
@@ -71,7 +73,7 @@ func TestCreateUserDto_Validate(t *testing.T) {
 			Password:  "weak",
 			Image:     "https://example.com/image.jpg",
 		}
-		err := dto.Validate()
+		err := dto.Validate(validate)
 		var validationErrs validator.ValidationErrors
 		assert.Error(t, err)
 		errors.As(err, &validationErrs)
@@ -81,12 +83,13 @@ func TestCreateUserDto_Validate(t *testing.T) {
 }
 
 func TestUpdateUserDto(t *testing.T) {
-	initialize.RegisterValidations()
+	validate, err := validations.NewValidator()
+	require.NoError(t, err)
 	t.Run("should allow partial updates", func(t *testing.T) {
 		dto := users.UpdateUserDto{
 			FirstName: "John",
 		}
-		err := dto.Validate()
+		err := dto.Validate(validate)
 		assert.NoError(t, err)
 		assert.Equal(t, "John", dto.FirstName)
 		assert.Empty(t, dto.LastName)
@@ -0,0 +1,140 @@
+package users
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMemoryImportJobRepository(t *testing.T) {
+	testImportJobRepository(t, users.NewMemoryImportJobRepository())
+}
+
+// testImportJobRepository runs the specs every ImportJobRepository must satisfy
+// against repo.
+func testImportJobRepository(t *testing.T, repo users.ImportJobRepository) {
+	ctx := context.Background()
+	require.NoError(t, repo.EnsureIndexes(ctx))
+
+	t.Run("Create, save and find a job", func(t *testing.T) {
+		job := &users.ImportJobModel{Format: users.FormatCSV, Status: users.ImportPending, Total: 2}
+		require.Nil(t, repo.Create(ctx, job))
+		assert.False(t, job.ID.IsZero())
+
+		job.Status, job.Processed, job.Failed = users.ImportRunning, 2, 1
+		job.Errors = []users.ImportRowError{{
+			Line:       2,
+			Violations: []response.Violation{{Field: "email", Rule: "email", Message: "invalid"}},
+		}}
+		require.Nil(t, repo.Save(ctx, job))
+
+		found, err := repo.FindByID(ctx, job.ID.Hex())
+		require.Nil(t, err)
+		assert.Equal(t, users.ImportRunning, found.Status)
+		assert.Equal(t, job.Errors, found.Errors)
+		assert.InDelta(t, 100, found.ToDto().Progress, 0.001)
+	})
+
+	t.Run("Record each batch once, finishing with the last", func(t *testing.T) {
+		job := &users.ImportJobModel{Format: users.FormatNDJSON, Status: users.ImportPending, Total: 3}
+		require.Nil(t, repo.Create(ctx, job))
+
+		first := &users.ImportBatchResult{Batch: 0, Processed: 2, Inserted: 2}
+		require.Nil(t, repo.Record(ctx, job.ID.Hex(), first))
+		require.Nil(t, repo.Record(ctx, job.ID.Hex(), first))
+		found, err := repo.FindByID(ctx, job.ID.Hex())
+		require.Nil(t, err)
+		assert.Equal(t, users.ImportRunning, found.Status)
+		assert.Equal(t, 2, found.Processed)
+		assert.Equal(t, 2, found.Inserted)
+		assert.Nil(t, found.FinishedAt)
+
+		require.Nil(t, repo.Record(ctx, job.ID.Hex(), &users.ImportBatchResult{
+			Batch:     1,
+			Processed: 1,
+			Failed:    1,
+			Errors:    []users.ImportRowError{{Line: 4}},
+			Error:     "insert users fail",
+		}))
+		found, err = repo.FindByID(ctx, job.ID.Hex())
+		require.Nil(t, err)
+		assert.Equal(t, users.ImportFailed, found.Status)
+		assert.Equal(t, "insert users fail", found.Error)
+		assert.Equal(t, 3, found.Processed)
+		assert.Equal(t, 1, found.Failed)
+		assert.Len(t, found.Errors, 1)
+		assert.NotNil(t, found.FinishedAt)
+	})
+
+	t.Run("Finish a job left running after its last batch", func(t *testing.T) {
+		job := &users.ImportJobModel{Format: users.FormatCSV, Status: users.ImportPending, Total: 2}
+		require.Nil(t, repo.Create(ctx, job))
+
+		// The last batch was recorded, but the job could not be finished.
+		job.Status, job.Processed, job.Inserted, job.Batches = users.ImportRunning, 2, 2, []int{0}
+		require.Nil(t, repo.Save(ctx, job))
+
+		require.Nil(t, repo.Record(ctx, job.ID.Hex(), &users.ImportBatchResult{
+			Batch:     0,
+			Processed: 2,
+			Inserted:  2,
+		}))
+		found, err := repo.FindByID(ctx, job.ID.Hex())
+		require.Nil(t, err)
+		assert.Equal(t, users.ImportCompleted, found.Status)
+		assert.Equal(t, 2, found.Processed)
+		assert.Equal(t, 2, found.Inserted)
+		assert.NotNil(t, found.FinishedAt)
+	})
+
+	t.Run("Stage, find and delete the batches of a job", func(t *testing.T) {
+		batch := &users.ImportBatchModel{
+			ID:        bson.NewObjectID(),
+			ImportID:  bson.NewObjectID(),
+			Index:     1,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		require.Nil(t, repo.StageBatches(ctx, []*users.ImportBatchModel{batch}))
+
+		found, err := repo.FindBatch(ctx, batch.ID.Hex())
+		require.Nil(t, err)
+		assert.Equal(t, batch.ImportID, found.ImportID)
+		assert.Equal(t, 1, found.Index)
+
+		require.Nil(t, repo.DeleteBatch(ctx, batch.ID.Hex()))
+		_, err = repo.FindBatch(ctx, batch.ID.Hex())
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeImportNotFound, err.ErrorCode())
+	})
+
+	t.Run("Report unknown jobs", func(t *testing.T) {
+		_, err := repo.FindByID(ctx, bson.NewObjectID().Hex())
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeImportNotFound, err.ErrorCode())
+
+		err = repo.Save(ctx, &users.ImportJobModel{})
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeImportNotFound, err.ErrorCode())
+
+		_, err = repo.FindByID(ctx, "invalid")
+		require.NotNil(t, err)
+	})
+}
+
+// TestImportJobRepository_Integration runs the import job repository specs
+// against MongoDB, when a test configuration is available.
+func TestImportJobRepository_Integration(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
+
+	// Ensure a clean state.
+	require.NoError(t, db.Collection(users.ImportJobModel{}.CollectionName()).Drop(ctx))
+	require.NoError(t, db.Collection(users.ImportBatchModel{}.CollectionName()).Drop(ctx))
+	testImportJobRepository(t, users.NewImportJobRepository(db))
+}
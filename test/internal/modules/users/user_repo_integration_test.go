@@ -2,121 +2,73 @@ package users
 
 import (
 	"context"
+	"os"
+	"testing"
+
 	"github.com/hainguyen27798/gin-boilerplate/global"
 	"github.com/hainguyen27798/gin-boilerplate/internal/initialize"
 	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
-	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
-	"github.com/hainguyen27798/gin-boilerplate/pkg/helpers"
-	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/fieldset"
 	"github.com/stretchr/testify/assert"
-	"go.mongodb.org/mongo-driver/v2/bson"
-	"os"
-	"testing"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func TestUserRepository_Integration(t *testing.T) {
-	// Setup test config
-	helpers.Must(os.Setenv("MODE", "test"))
-	defer func() {
-		helpers.Must(os.Unsetenv("MODE"))
-	}()
+// openTestDatabase connects to MongoDB for the duration of the test, skipping it
+// when no test configuration is available.
+func openTestDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+	if _, err := os.Stat("../../../../configs/test.yaml"); err != nil {
+		t.Skip("no test configuration, skipping MongoDB integration tests")
+	}
 
+	// Setup test config
+	t.Setenv("MODE", "test")
 	initialize.LoadConfig("../../../../configs/")
 	initialize.InitLogger()
 
-	ctx := context.Background()
-
 	// Initialize the database using the shared InitDatabase() method.
-	initialize.InitDatabase()
-	defer func() {
-		if global.MongoDB != nil {
-			err := global.MongoDB.Disconnect(ctx)
-			if err != nil {
-				panic(err)
-			}
+	require.NoError(t, initialize.InitDatabase(t.Context()))
+	t.Cleanup(func() {
+		if err := global.MongoDB.Disconnect(context.Background()); err != nil {
+			panic(err)
 		}
-	}()
-
-	// Clean up the users collection to start fresh.
-	repo := users.NewUserRepository(global.MongoDB.DB)
-	collection := global.MongoDB.DB.Collection(users.UserModel{}.CollectionName())
-	if err := collection.Drop(ctx); err != nil {
-		assert.NoError(t, err)
-	}
-
-	// Prepare a test user.
-	hexId, _ := bson.ObjectIDFromHex("67a4f57c39b9abb0dbabd5b0")
-	user := &users.UserModel{
-		BaseModel: common.BaseModel{
-			ID: hexId,
-		},
-		Email:     "test@example.com",
-		FirstName: "John",
-		LastName:  "Doe",
-		Password:  "StrongP@ss123!",
-		Image:     "test.jpg",
-	}
-
-	t.Run("Create user", func(t *testing.T) {
-		newUser, err := repo.Create(ctx, user)
-		assert.NoError(t, err)
-		assert.NotNil(t, newUser)
-		assert.Equal(t, user.Email, newUser.Email)
-		assert.Equal(t, user.FirstName, newUser.FirstName)
-		assert.Equal(t, user.LastName, newUser.LastName)
-		assert.Equal(t, user.Image, newUser.Image)
-		assert.Equal(t, user.Password, newUser.Password)
-		assert.NotEmpty(t, newUser.CreatedAt)
-		assert.NotEmpty(t, newUser.UpdatedAt)
-	})
-
-	t.Run("Find user by email", func(t *testing.T) {
-		found, err := repo.FindByEmail(ctx, user.Email)
-		assert.NoError(t, err)
-		assert.Equal(t, user.Email, found.Email)
-	})
-
-	t.Run("Find user by ID", func(t *testing.T) {
-		found, err := repo.FindByID(ctx, user.ID.Hex())
-
-		assert.NoError(t, err)
-		assert.Equal(t, user.ID, found.ID)
 	})
+	return global.MongoDB.DB
+}
 
-	t.Run("Update fistName user", func(t *testing.T) {
-		firstName := "Jane"
+// TestUserRepository_Integration runs the repository specs against MongoDB,
+// when a test configuration is available.
+func TestUserRepository_Integration(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDatabase(t)
 
-		// Update timestamp.
-		userUpdated, err := repo.Update(ctx, user.ID.Hex(), bson.D{
-			{
-				"$set",
-				bson.D{
-					{"first_name", firstName},
-				},
-			},
+	newRepo := func() users.UserRepository {
+		// Each spec starts from an empty users collection.
+		collection := db.Collection(users.UserModel{}.CollectionName())
+		require.NoError(t, collection.Drop(ctx))
+		return users.NewUserRepository(db)
+	}
+	testUserRepository(t, newRepo)
+
+	t.Run("reads only the selected fields", func(t *testing.T) {
+		repo := newRepo()
+		user, err := repo.Create(ctx, &users.UserModel{
+			Email:     "projection@example.com",
+			FirstName: "John",
+			LastName:  "Doe",
+			Password:  "hashed",
 		})
-		assert.NoError(t, err)
-		updated := userUpdated.ToDto()
-		assert.Equal(t, firstName, updated.FirstName)
-		assert.NotEmpty(t, updated.UpdatedAt)
-	})
-
-	t.Run("Delete user", func(t *testing.T) {
-		err := repo.Delete(ctx, user.ID.Hex())
-		assert.NoError(t, err)
+		require.Nil(t, err)
 
-		deleted, err := repo.FindByID(ctx, user.ID.Hex())
-		assert.Error(t, err)
-		assert.Nil(t, deleted)
-	})
-
-	t.Run("Delete non-existing user", func(t *testing.T) {
-		err := repo.Delete(ctx, "507f1f77bcf86cd799439011")
-		assert.Equal(t, err.Error(), response.CodeMsg[response.ErrNotFound])
-	})
+		fields, parseErr := fieldset.Parse("id,first_name")
+		require.NoError(t, parseErr)
+		found, err := repo.FindByID(fieldset.NewContext(ctx, fields), user.ID.Hex())
+		require.Nil(t, err)
 
-	t.Run("Delete user with invalid objectID", func(t *testing.T) {
-		err := repo.Delete(ctx, "invalid-object-id")
-		assert.Equal(t, err.Error(), "the provided hex string is not a valid ObjectID")
+		assert.Equal(t, user.ID, found.ID)
+		assert.Equal(t, "John", found.FirstName)
+		assert.Empty(t, found.Email)
+		assert.Empty(t, found.Password)
 	})
 }
@@ -0,0 +1,290 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	testUserRepository(t, users.NewMemoryUserRepository)
+}
+
+// testUserRepository runs the specs every UserRepository must satisfy against
+// the repositories returned by newRepo, which must be empty.
+func testUserRepository(t *testing.T, newRepo func() users.UserRepository) {
+	ctx := context.Background()
+	newUser := func(email string) *users.UserModel {
+		return &users.UserModel{
+			Email:     email,
+			FirstName: "John",
+			LastName:  "Doe",
+			Password:  "StrongP@ss123!",
+			Image:     "test.jpg",
+		}
+	}
+
+	t.Run("Create and find user", func(t *testing.T) {
+		repo := newRepo()
+		require.NoError(t, repo.EnsureIndexes(ctx))
+
+		created, err := repo.Create(ctx, newUser("test@example.com"))
+		require.Nil(t, err)
+		assert.False(t, created.ID.IsZero())
+		assert.NotEmpty(t, created.CreatedAt)
+		assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+		found, err := repo.FindByEmail(ctx, "test@example.com")
+		require.Nil(t, err)
+		assert.Equal(t, created.ID, found.ID)
+		assert.Equal(t, "John", found.FirstName)
+
+		found, err = repo.FindByID(ctx, created.ID.Hex())
+		require.Nil(t, err)
+		assert.Equal(t, "test@example.com", found.Email)
+	})
+
+	t.Run("Reject duplicate email", func(t *testing.T) {
+		repo := newRepo()
+		require.NoError(t, repo.EnsureIndexes(ctx))
+
+		_, err := repo.Create(ctx, newUser("test@example.com"))
+		require.Nil(t, err)
+		_, err = repo.Create(ctx, newUser("test@example.com"))
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeEmailTaken, err.ErrorCode())
+		_, err = repo.Create(ctx, newUser("Test@Example.com"))
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeEmailTaken, err.ErrorCode())
+	})
+
+	t.Run("Find users stored with a mixed-case email", func(t *testing.T) {
+		repo := newRepo()
+		require.NoError(t, repo.EnsureIndexes(ctx))
+		created, err := repo.Create(ctx, newUser("John.Doe@Example.com"))
+		require.Nil(t, err)
+
+		found, err := repo.FindByEmail(ctx, "john.doe@example.com")
+		require.Nil(t, err)
+		assert.Equal(t, created.ID, found.ID)
+	})
+
+	t.Run("Update user and bump updated_at", func(t *testing.T) {
+		repo := newRepo()
+		require.NoError(t, repo.EnsureIndexes(ctx))
+		created, err := repo.Create(ctx, newUser("test@example.com"))
+		require.Nil(t, err)
+		// Dates are stored with a millisecond precision.
+		time.Sleep(2 * time.Millisecond)
+
+		updated, err := repo.Update(ctx, created.ID.Hex(), bson.D{
+			{Key: "$set", Value: bson.D{{Key: "first_name", Value: "Jane"}}},
+		})
+		require.Nil(t, err)
+		assert.Equal(t, "Jane", updated.FirstName)
+		assert.Equal(t, "Doe", updated.LastName)
+		assert.True(t, updated.UpdatedAt.After(created.UpdatedAt))
+
+		// The prefix searches match the updated name.
+		_, total, err := repo.Search(ctx, "JAN", 0, 10)
+		require.Nil(t, err)
+		assert.EqualValues(t, 1, total)
+		_, total, err = repo.Search(ctx, "joh", 0, 10)
+		require.Nil(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("Delete user", func(t *testing.T) {
+		repo := newRepo()
+		created, err := repo.Create(ctx, newUser("test@example.com"))
+		require.Nil(t, err)
+
+		require.Nil(t, repo.Delete(ctx, created.ID.Hex()))
+
+		deleted, err := repo.FindByID(ctx, created.ID.Hex())
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeUserNotFound, err.ErrorCode())
+		assert.Nil(t, deleted)
+	})
+
+	t.Run("Report unknown users", func(t *testing.T) {
+		repo := newRepo()
+		id := bson.NewObjectID().Hex()
+
+		_, err := repo.FindByEmail(ctx, "unknown@example.com")
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeUserNotFound, err.ErrorCode())
+
+		_, err = repo.FindByID(ctx, id)
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeUserNotFound, err.ErrorCode())
+
+		_, err = repo.Update(ctx, id, bson.D{
+			{Key: "$set", Value: bson.D{{Key: "first_name", Value: "Jane"}}},
+		})
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeUserNotFound, err.ErrorCode())
+
+		err = repo.Delete(ctx, id)
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeUserNotFound, err.ErrorCode())
+	})
+
+	t.Run("Reject invalid IDs", func(t *testing.T) {
+		repo := newRepo()
+
+		_, err := repo.FindByID(ctx, "invalid-object-id")
+		require.NotNil(t, err)
+		assert.Equal(t, response.ErrInvalidObjectID.Error(), err.AppErr())
+
+		err = repo.Delete(ctx, "invalid-object-id")
+		require.NotNil(t, err)
+		assert.Equal(t, response.ErrInvalidObjectID.Error(), err.AppErr())
+	})
+
+	t.Run("Search users by partial name", func(t *testing.T) {
+		repo := newRepo()
+		require.NoError(t, repo.EnsureIndexes(ctx))
+		_, err := repo.Create(ctx, newUser("jd@example.com"))
+		require.Nil(t, err)
+
+		matches, total, err := repo.Search(ctx, "Joh", 0, 10)
+		require.Nil(t, err)
+		assert.EqualValues(t, 1, total)
+		require.Len(t, matches, 1)
+		assert.Equal(t, "John", matches[0].FirstName)
+		assert.Empty(t, matches[0].Password)
+
+		_, total, err = repo.Search(ctx, "ohn", 0, 10)
+		require.Nil(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("Search users", func(t *testing.T) {
+		repo := newRepo()
+		require.NoError(t, repo.EnsureIndexes(ctx))
+		for _, user := range []*users.UserModel{
+			{Email: "jane.smith@example.com", FirstName: "Jane", LastName: "Smith", Password: "secret"},
+			{Email: "mary@example.com", FirstName: "Mary", LastName: "Jane", Password: "secret"},
+			{Email: "bob@example.com", FirstName: "Bob", LastName: "Brown", Password: "secret"},
+		} {
+			_, err := repo.Create(ctx, user)
+			require.Nil(t, err)
+		}
+		emails := func(matches []users.UserMatch) []string {
+			found := make([]string, len(matches))
+			for i, match := range matches {
+				found[i] = match.Email
+			}
+			return found
+		}
+
+		// Matching the email and a name ranks higher than matching a name.
+		matches, total, err := repo.Search(ctx, "jane", 0, 10)
+		require.Nil(t, err)
+		assert.EqualValues(t, 2, total)
+		assert.Equal(t, []string{"jane.smith@example.com", "mary@example.com"}, emails(matches))
+		assert.Greater(t, matches[0].Score, matches[1].Score)
+		assert.Empty(t, matches[0].Password)
+
+		matches, total, err = repo.Search(ctx, "jane", 1, 1)
+		require.Nil(t, err)
+		assert.EqualValues(t, 2, total)
+		assert.Equal(t, []string{"mary@example.com"}, emails(matches))
+
+		// Without a matching word, the query is an email prefix.
+		matches, total, err = repo.Search(ctx, "MAR", 0, 10)
+		require.Nil(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.Equal(t, []string{"mary@example.com"}, emails(matches))
+		assert.Zero(t, matches[0].Score)
+
+		// Or a prefix of a name, regardless of its case.
+		matches, total, err = repo.Search(ctx, "smi", 0, 10)
+		require.Nil(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.Equal(t, []string{"jane.smith@example.com"}, emails(matches))
+
+		matches, total, err = repo.Search(ctx, " Br ", 0, 10)
+		require.Nil(t, err)
+		assert.EqualValues(t, 1, total)
+		assert.Equal(t, []string{"bob@example.com"}, emails(matches))
+
+		matches, total, err = repo.Search(ctx, "zoe", 0, 10)
+		require.Nil(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, matches)
+	})
+
+	t.Run("Create many users", func(t *testing.T) {
+		repo := newRepo()
+		require.NoError(t, repo.EnsureIndexes(ctx))
+		_, err := repo.Create(ctx, newUser("taken@example.com"))
+		require.Nil(t, err)
+
+		batch := []*users.UserModel{
+			newUser("a@example.com"),
+			newUser("taken@example.com"),
+			newUser("b@example.com"),
+			newUser("a@example.com"),
+		}
+		failed, err := repo.CreateMany(ctx, batch)
+		require.Nil(t, err)
+		require.Len(t, failed, 2)
+		assert.Equal(t, users.ErrCodeEmailTaken, failed[1].ErrorCode())
+		assert.Equal(t, users.ErrCodeEmailTaken, failed[3].ErrorCode())
+
+		found, err := repo.FindByEmail(ctx, "b@example.com")
+		require.Nil(t, err)
+		assert.Equal(t, batch[2].ID, found.ID)
+	})
+
+	t.Run("Iterate over filtered users", func(t *testing.T) {
+		repo := newRepo()
+		require.NoError(t, repo.EnsureIndexes(ctx))
+		since := time.Now().UTC().Truncate(time.Millisecond).Add(-time.Hour)
+		for _, user := range []*users.UserModel{
+			{Email: "old@acme.example", Verified: true, Password: "secret"},
+			{Email: "unverified@acme.example", Password: "secret"},
+			{Email: "other@example.com", Verified: true, Password: "secret"},
+			{Email: "new@acme.example", Verified: true, Password: "secret"},
+		} {
+			if user.Email == "old@acme.example" {
+				user.CreatedAt = since.Add(-time.Hour)
+			}
+			_, err := repo.Create(ctx, user)
+			require.Nil(t, err)
+		}
+		iterate := func(filter users.UserFilter) []string {
+			var emails []string
+			err := repo.Iterate(ctx, filter, func(user *users.UserModel) error {
+				assert.Empty(t, user.Password)
+				emails = append(emails, user.Email)
+				return nil
+			})
+			require.Nil(t, err)
+			return emails
+		}
+
+		assert.Len(t, iterate(users.UserFilter{}), 4)
+		verified := true
+		assert.Equal(t, []string{"new@acme.example"}, iterate(users.UserFilter{
+			Verified:    &verified,
+			EmailDomain: "acme.example",
+			CreatedFrom: since,
+		}))
+		assert.Equal(t, []string{"old@acme.example"}, iterate(users.UserFilter{CreatedTo: since}))
+
+		err := repo.Iterate(ctx, users.UserFilter{}, func(*users.UserModel) error {
+			return errors.New("client went away")
+		})
+		require.NotNil(t, err)
+	})
+}
@@ -0,0 +1,126 @@
+package users
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestUserService(t *testing.T) {
+	testUserService(t, users.NewMemoryUserRepository())
+}
+
+// testUserService runs the service specs against the given empty repository.
+func testUserService(t *testing.T, repo users.UserRepository) {
+	ctx := context.Background()
+	require.NoError(t, repo.EnsureIndexes(ctx))
+	service := users.NewUserService(repo, zap.NewNop())
+
+	// Prepare a new user DTO.
+	createDTO := &users.CreateUserDto{
+		Email:     "testservice@example.com",
+		FirstName: "John",
+		LastName:  "Doe",
+		Password:  "StrongP@ss123!",
+		Image:     "test.jpg",
+	}
+
+	var createdUserID string
+
+	t.Run("Create user", func(t *testing.T) {
+		newUser, err := service.CreateUser(ctx, createDTO)
+		require.Nil(t, err)
+		assert.Equal(t, createDTO.Email, newUser.Email)
+		assert.Equal(t, createDTO.FirstName, newUser.FirstName)
+		assert.Equal(t, createDTO.LastName, newUser.LastName)
+		assert.NotEmpty(t, newUser.CreatedAt)
+		assert.Equal(t, newUser.CreatedAt, newUser.UpdatedAt)
+		createdUserID = newUser.ID
+	})
+
+	t.Run("Reject duplicate email", func(t *testing.T) {
+		_, err := service.CreateUser(ctx, createDTO)
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeEmailTaken, err.ErrorCode())
+	})
+
+	t.Run("Get user by email", func(t *testing.T) {
+		userDto, err := service.GetUserByEmail(ctx, createDTO.Email)
+		require.Nil(t, err)
+		assert.Equal(t, createdUserID, userDto.ID)
+	})
+
+	t.Run("Get user by ID", func(t *testing.T) {
+		userDto, err := service.GetUserByID(ctx, createdUserID)
+		require.Nil(t, err)
+		assert.Equal(t, createDTO.Email, userDto.Email)
+	})
+
+	t.Run("Update user", func(t *testing.T) {
+		userDto, err := service.UpdateUser(ctx, createdUserID, &users.UpdateUserDto{
+			FirstName: "Jane",
+		})
+		require.Nil(t, err)
+		assert.Equal(t, "Jane", userDto.FirstName)
+		assert.NotEqual(t, userDto.CreatedAt, userDto.UpdatedAt)
+	})
+
+	t.Run("Delete user", func(t *testing.T) {
+		require.Nil(t, service.DeleteUser(ctx, createdUserID))
+
+		// Attempt to fetch the deleted user.
+		userDto, err := service.GetUserByID(ctx, createdUserID)
+		assert.NotNil(t, err)
+		assert.Nil(t, userDto)
+	})
+
+	t.Run("Normalize email", func(t *testing.T) {
+		dto := *createDTO
+		dto.Email = "  Mixed.Case@Example.COM "
+
+		userDto, err := service.CreateUser(ctx, &dto)
+		require.Nil(t, err)
+		assert.Equal(t, "mixed.case@example.com", userDto.Email)
+
+		found, err := service.GetUserByEmail(ctx, "MIXED.case@example.com")
+		require.Nil(t, err)
+		assert.Equal(t, userDto.ID, found.ID)
+
+		_, err = service.CreateUser(ctx, &users.CreateUserDto{
+			Email:     "mixed.case@EXAMPLE.com",
+			FirstName: "Jane",
+			LastName:  "Doe",
+			Password:  "StrongP@ss123!",
+		})
+		require.NotNil(t, err)
+		assert.Equal(t, users.ErrCodeEmailTaken, err.ErrorCode())
+	})
+
+	t.Run("Search users", func(t *testing.T) {
+		page, err := service.SearchUsers(ctx, &users.SearchUsersQuery{Q: "Mixed"})
+		require.Nil(t, err)
+		require.Len(t, page.Items, 1)
+		assert.EqualValues(t, 1, page.Total)
+		assert.Equal(t, 1, page.Page)
+		assert.Equal(t, 20, page.Limit)
+		assert.Equal(t, "mixed.case@example.com", page.Items[0].User.Email)
+		assert.Equal(t, []users.Highlight{{
+			Field:  "email",
+			Ranges: []users.TextRange{{Start: 0, End: 5}},
+		}}, page.Items[0].Highlights)
+
+		// The prefix of the email is highlighted as a whole.
+		page, err = service.SearchUsers(ctx, &users.SearchUsersQuery{Q: "mixed.ca"})
+		require.Nil(t, err)
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, []users.TextRange{{Start: 0, End: 8}}, page.Items[0].Highlights[0].Ranges)
+
+		page, err = service.SearchUsers(ctx, &users.SearchUsersQuery{Q: "   "})
+		require.Nil(t, err)
+		assert.Empty(t, page.Items)
+	})
+}
@@ -0,0 +1,98 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/openapi"
+	"github.com/hainguyen27798/gin-boilerplate/test/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// specFile is the committed OpenAPI document, the contract of the API.
+const specFile = "../../../api/openapi.json"
+
+var update = flag.Bool("update", false, "update the committed OpenAPI document")
+
+func TestOpenAPI(t *testing.T) {
+	t.Run("should document every route", func(t *testing.T) {
+		app := testutil.NewApp(t)
+
+		assert.NoError(t, app.OpenAPIDrift)
+	})
+
+	t.Run("should match the committed document", func(t *testing.T) {
+		app := testutil.NewApp(t)
+		res := app.Client(t).Get("/openapi.json").ExpectStatus(http.StatusOK)
+
+		var served bytes.Buffer
+		require.NoError(t, json.Indent(&served, res.Body.Bytes(), "", "  "))
+		served.WriteByte('\n')
+		if *update {
+			require.NoError(t, os.WriteFile(specFile, served.Bytes(), 0o644))
+		}
+
+		committed, err := os.ReadFile(specFile)
+		require.NoError(t, err)
+		assert.Equal(t, string(committed), served.String(),
+			"the API changed, run `make openapi` to update the committed document")
+	})
+
+	t.Run("should describe the request constraints", func(t *testing.T) {
+		app := testutil.NewApp(t)
+
+		var doc openapi.Document
+		app.Client(t).Get("/openapi.json").ExpectStatus(http.StatusOK).Decode(&doc)
+
+		create := (*doc.Paths["/v1/users"])["post"]
+		require.NotNil(t, create)
+		assert.Contains(t, create.Responses, "201")
+		assert.Contains(t, create.Responses, "409")
+
+		dto := doc.Components.Schemas["CreateUserDto"]
+		require.NotNil(t, dto)
+		assert.ElementsMatch(t, []string{"email", "first_name", "last_name", "password"}, dto.Required)
+		assert.Equal(t, "email", dto.Properties["email"].Format)
+		assert.Equal(t, "uri", dto.Properties["image"].Format)
+
+		user := doc.Components.Schemas["UserDto"]
+		require.NotNil(t, user)
+		assert.Contains(t, user.Properties, "id")
+		assert.NotContains(t, user.Properties, "password")
+	})
+
+	t.Run("should serve the Swagger UI", func(t *testing.T) {
+		if !openapi.UIAvailable {
+			t.Skip("the Swagger UI is left out by the noswaggerui build tag")
+		}
+		app := testutil.NewApp(t)
+
+		res := app.Client(t).Get("/docs").ExpectStatus(http.StatusOK)
+
+		assert.Contains(t, res.Body.String(), "swagger-ui")
+		assert.Contains(t, res.Body.String(), "/openapi.json")
+		assert.Contains(t, res.Body.String(), `src="/docs/assets/swagger-ui-bundle.js"`)
+		assert.NotContains(t, res.Body.String(), "https://")
+	})
+
+	t.Run("should serve the Swagger UI assets", func(t *testing.T) {
+		if !openapi.UIAvailable {
+			t.Skip("the Swagger UI is left out by the noswaggerui build tag")
+		}
+		app := testutil.NewApp(t)
+
+		res := app.Client(t).Get("/docs/assets/swagger-ui-bundle.js").ExpectStatus(http.StatusOK)
+		assert.Contains(t, res.Header().Get("Content-Type"), "javascript")
+		assert.Contains(t, res.Body.String(), "SwaggerUIBundle")
+
+		res = app.Client(t).Get("/docs/assets/swagger-ui.css").ExpectStatus(http.StatusOK)
+		assert.Contains(t, res.Header().Get("Content-Type"), "text/css")
+
+		app.Client(t).Get("/docs/assets/missing.js").ExpectStatus(http.StatusNotFound)
+	})
+}
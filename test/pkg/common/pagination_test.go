@@ -0,0 +1,44 @@
+package common
+
+import (
+	"github.com/hainguyen27798/gin-boilerplate/pkg/common"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PageQuery", func() {
+	It("should default to the first page of the default size", func() {
+		query := common.PageQuery{}
+		Expect(query.Number()).To(Equal(1))
+		Expect(query.Size()).To(Equal(common.DefaultPageSize))
+		Expect(query.Skip()).To(BeZero())
+	})
+
+	It("should skip the items of the previous pages", func() {
+		query := common.PageQuery{Page: 3, Limit: 10}
+		Expect(query.Skip()).To(Equal(int64(20)))
+	})
+
+	It("should cap the size of the pages", func() {
+		query := common.PageQuery{Limit: 1000}
+		Expect(query.Size()).To(Equal(common.MaxPageSize))
+	})
+})
+
+var _ = Describe("NewPage", func() {
+	It("should describe the page answering the query", func() {
+		page := common.NewPage([]string{"a", "b"}, common.PageQuery{Page: 2, Limit: 2}, 5)
+		Expect(*page).To(Equal(common.Page[string]{
+			Items: []string{"a", "b"},
+			Page:  2,
+			Limit: 2,
+			Total: 5,
+		}))
+	})
+
+	It("should never have nil items", func() {
+		page := common.NewPage[string](nil, common.PageQuery{}, 0)
+		Expect(page.Items).To(BeEmpty())
+		Expect(page.Items).NotTo(BeNil())
+	})
+})
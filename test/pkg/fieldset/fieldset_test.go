@@ -0,0 +1,177 @@
+package fieldset
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/fieldset"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestFieldset(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fieldset Suite")
+}
+
+type Base struct {
+	ID        string    `json:"id" bson:"_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Address struct {
+	City    string `json:"city"`
+	Country string `json:"country"`
+}
+
+type Profile struct {
+	Base      `json:",inline"`
+	FirstName string    `json:"first_name"`
+	Password  string    `json:"-"`
+	Nickname  string    `json:"nickname,omitempty"`
+	Address   *Address  `json:"address" bson:"addr"`
+	Addresses []Address `json:"addresses"`
+	Tags      map[string]string
+}
+
+var profileType = reflect.TypeFor[*Profile]()
+
+func newProfile() *Profile {
+	return &Profile{
+		Base:      Base{ID: "42", CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+		FirstName: "John",
+		Password:  "secret",
+		Address:   &Address{City: "Hanoi", Country: "VN"},
+		Addresses: []Address{{City: "Hue", Country: "VN"}, {City: "Paris", Country: "FR"}},
+		Tags:      map[string]string{"team": "core", "role": "admin"},
+	}
+}
+
+func mustParse(fields string) fieldset.Set {
+	set, err := fieldset.Parse(fields)
+	Expect(err).NotTo(HaveOccurred())
+	return set
+}
+
+var _ = Describe("Parse", func() {
+	It("should select every field when empty", func() {
+		Expect(mustParse(" ")).To(BeNil())
+	})
+
+	It("should parse nested paths", func() {
+		Expect(mustParse("id, first_name,address.city")).To(Equal(fieldset.Set{
+			"id":         nil,
+			"first_name": nil,
+			"address":    {"city": nil},
+		}))
+	})
+
+	It("should let a whole field win over its own fields", func() {
+		Expect(mustParse("address.city,address")).To(Equal(fieldset.Set{"address": nil}))
+		Expect(mustParse("address,address.city")).To(Equal(fieldset.Set{"address": nil}))
+	})
+
+	It("should reject empty names", func() {
+		for _, fields := range []string{"id,", "address..city", ".id"} {
+			_, err := fieldset.Parse(fields)
+			Expect(err).To(HaveOccurred(), fields)
+		}
+	})
+})
+
+var _ = Describe("Validate", func() {
+	It("should accept the JSON fields, embedded and nested ones included", func() {
+		Expect(mustParse("id,created_at,first_name,address.city,addresses.country,Tags.team").
+			Validate(profileType)).To(Succeed())
+	})
+
+	It("should reject unknown fields", func() {
+		Expect(mustParse("id,email").Validate(profileType)).
+			To(MatchError(ContainSubstring(`unknown field "email"`)))
+		Expect(mustParse("address.street").Validate(profileType)).
+			To(MatchError(ContainSubstring(`unknown field "address.street"`)))
+	})
+
+	It("should never know the hidden fields", func() {
+		Expect(mustParse("Password").Validate(profileType)).To(HaveOccurred())
+		Expect(mustParse("password").Validate(profileType)).To(HaveOccurred())
+	})
+
+	It("should reject the fields of values", func() {
+		Expect(mustParse("first_name.length").Validate(profileType)).
+			To(MatchError(ContainSubstring(`field "first_name" has no fields`)))
+		Expect(mustParse("created_at.year").Validate(profileType)).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Filter", func() {
+	It("should return the data as is without a selection", func() {
+		profile := newProfile()
+		Expect(fieldset.Set(nil).Filter(profile)).To(BeIdenticalTo(profile))
+	})
+
+	It("should keep the selected fields", func() {
+		Expect(mustParse("id,first_name,created_at").Filter(newProfile())).To(Equal(map[string]any{
+			"id":         "42",
+			"first_name": "John",
+			"created_at": time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		}))
+	})
+
+	It("should filter nested structs, slices and maps", func() {
+		Expect(mustParse("address.city,addresses.country,Tags.team").Filter(newProfile())).
+			To(Equal(map[string]any{
+				"address":   map[string]any{"city": "Hanoi"},
+				"addresses": []any{map[string]any{"country": "VN"}, map[string]any{"country": "FR"}},
+				"Tags":      map[string]any{"team": "core"},
+			}))
+	})
+
+	It("should keep whole nested fields", func() {
+		Expect(mustParse("address").Filter(newProfile())).To(Equal(map[string]any{
+			"address": &Address{City: "Hanoi", Country: "VN"},
+		}))
+	})
+
+	It("should leave out empty omitempty fields and keep nil ones", func() {
+		profile := newProfile()
+		profile.Address = nil
+
+		Expect(mustParse("nickname,address.city").Filter(profile)).To(Equal(map[string]any{
+			"address": nil,
+		}))
+	})
+
+	It("should filter every element of a list", func() {
+		Expect(mustParse("id").Filter([]*Profile{newProfile()})).To(Equal([]any{
+			map[string]any{"id": "42"},
+		}))
+	})
+})
+
+var _ = Describe("Projection", func() {
+	It("should project nothing without a selection", func() {
+		Expect(fieldset.Set(nil).Projection(profileType)).To(BeNil())
+	})
+
+	It("should name the fields by their bson tag", func() {
+		Expect(mustParse("id,first_name,address.city,addresses").Projection(profileType)).
+			To(Equal(bson.D{
+				{Key: "addr.city", Value: 1},
+				{Key: "addresses", Value: 1},
+				{Key: "first_name", Value: 1},
+				{Key: "_id", Value: 1},
+			}))
+	})
+})
+
+var _ = Describe("Context", func() {
+	It("should carry the selection", func() {
+		set := mustParse("id")
+		Expect(fieldset.FromContext(fieldset.NewContext(context.Background(), set))).To(Equal(set))
+		Expect(fieldset.FromContext(context.Background())).To(BeNil())
+	})
+})
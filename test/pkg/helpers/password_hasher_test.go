@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	helpers2 "github.com/hainguyen27798/gin-boilerplate/pkg/helpers"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastArgon2id keeps the specs fast, the parameters are not meant for production.
+var fastArgon2id = setting.Argon2idSettings{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+var _ = Describe("PasswordHasher", func() {
+	It("should encode argon2id hashes in the PHC format", func() {
+		hasher, err := helpers2.NewPasswordHasher(setting.PasswordHashSettings{Argon2id: fastArgon2id})
+		Expect(err).NotTo(HaveOccurred())
+
+		hash, err := hasher.Hash("mySecurePassword123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(hash).To(HavePrefix("$argon2id$v=19$m=1024,t=1,p=1$"))
+		Expect(strings.Split(hash, "$")).To(HaveLen(6))
+
+		ok, needsRehash := hasher.Verify("mySecurePassword123", hash)
+		Expect(ok).To(BeTrue())
+		Expect(needsRehash).To(BeFalse())
+
+		ok, _ = hasher.Verify("wrongPassword", hash)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should verify legacy bcrypt hashes and flag them for a rehash", func() {
+		legacy, err := bcrypt.GenerateFromPassword([]byte("mySecurePassword123"), bcrypt.MinCost)
+		Expect(err).NotTo(HaveOccurred())
+
+		hasher, err := helpers2.NewPasswordHasher(setting.PasswordHashSettings{Argon2id: fastArgon2id})
+		Expect(err).NotTo(HaveOccurred())
+
+		ok, needsRehash := hasher.Verify("mySecurePassword123", string(legacy))
+		Expect(ok).To(BeTrue())
+		Expect(needsRehash).To(BeTrue())
+	})
+
+	It("should flag hashes made with outdated parameters", func() {
+		old, err := helpers2.NewPasswordHasher(setting.PasswordHashSettings{Argon2id: fastArgon2id})
+		Expect(err).NotTo(HaveOccurred())
+		hash, err := old.Hash("mySecurePassword123")
+		Expect(err).NotTo(HaveOccurred())
+
+		params := fastArgon2id
+		params.Iterations = 2
+		hasher, err := helpers2.NewPasswordHasher(setting.PasswordHashSettings{Argon2id: params})
+		Expect(err).NotTo(HaveOccurred())
+
+		ok, needsRehash := hasher.Verify("mySecurePassword123", hash)
+		Expect(ok).To(BeTrue())
+		Expect(needsRehash).To(BeTrue())
+	})
+
+	It("should mix the pepper read from a secret file into hashes", func() {
+		pepperFile := filepath.Join(GinkgoT().TempDir(), "pepper")
+		Expect(os.WriteFile(pepperFile, []byte("s3cr3t\n"), 0o600)).To(Succeed())
+
+		hasher, err := helpers2.NewPasswordHasher(setting.PasswordHashSettings{
+			Argon2id:   fastArgon2id,
+			PepperFile: pepperFile,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		unpeppered, err := helpers2.NewPasswordHasher(setting.PasswordHashSettings{
+			Argon2id: fastArgon2id,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		hash, err := hasher.Hash("mySecurePassword123")
+		Expect(err).NotTo(HaveOccurred())
+
+		ok, _ := hasher.Verify("mySecurePassword123", hash)
+		Expect(ok).To(BeTrue())
+		ok, _ = unpeppered.Verify("mySecurePassword123", hash)
+		Expect(ok).To(BeFalse())
+
+		// Hashes made before the pepper was introduced still verify.
+		legacy, err := unpeppered.Hash("mySecurePassword123")
+		Expect(err).NotTo(HaveOccurred())
+		ok, needsRehash := hasher.Verify("mySecurePassword123", legacy)
+		Expect(ok).To(BeTrue())
+		Expect(needsRehash).To(BeTrue())
+	})
+
+	It("should reject passwords bcrypt would truncate", func() {
+		hasher, err := helpers2.NewPasswordHasher(setting.PasswordHashSettings{
+			Algorithm:  helpers2.BcryptAlgorithm,
+			BcryptCost: bcrypt.MinCost,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = hasher.Hash(strings.Repeat("a", 73))
+		Expect(err).To(MatchError(bcrypt.ErrPasswordTooLong))
+	})
+
+	It("should reject unknown algorithms", func() {
+		_, err := helpers2.NewPasswordHasher(setting.PasswordHashSettings{Algorithm: "md5"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,384 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/global"
+	"github.com/hainguyen27798/gin-boilerplate/internal/initialize"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/helpers"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/jobs"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestJobs(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Jobs Suite")
+}
+
+type email struct {
+	To      string `bson:"to"`
+	Subject string `bson:"subject"`
+}
+
+var sendEmail = jobs.NewType[email]("send_email")
+
+// newJob returns a queued job of the type, ready to run at runAt.
+func newJob(typ string, priority int, runAt time.Time) *jobs.Job {
+	return &jobs.Job{
+		ID:          bson.NewObjectID(),
+		Type:        typ,
+		Priority:    priority,
+		Status:      jobs.StatusQueued,
+		RunAt:       runAt,
+		MaxAttempts: 3,
+	}
+}
+
+// describeStore declares the specs every Store must satisfy, against the empty
+// stores returned by newStore.
+func describeStore(newStore func() jobs.Store) {
+	var store jobs.Store
+	var ctx context.Context
+	now := time.Now().UTC()
+
+	BeforeEach(func() {
+		store = newStore()
+		ctx = context.Background()
+	})
+
+	It("should claim the most urgent job ready to run", func() {
+		late := newJob("a", 0, now.Add(-time.Second))
+		early := newJob("a", 0, now.Add(-time.Minute))
+		urgent := newJob("a", 10, now)
+		delayed := newJob("a", 20, now.Add(time.Minute))
+		other := newJob("b", 30, now)
+		for _, job := range []*jobs.Job{late, early, urgent, delayed, other} {
+			Expect(store.Enqueue(ctx, job)).To(Succeed())
+		}
+
+		var claimed []bson.ObjectID
+		for {
+			job, err := store.Claim(ctx, []string{"a"}, now, now.Add(time.Minute))
+			if errors.Is(err, jobs.ErrNoJob) {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Status).To(Equal(jobs.StatusRunning))
+			Expect(job.Attempts).To(Equal(1))
+			Expect(job.Lease).NotTo(BeEmpty())
+			claimed = append(claimed, job.ID)
+		}
+		Expect(claimed).To(Equal([]bson.ObjectID{urgent.ID, early.ID, late.ID}))
+	})
+
+	It("should claim a job again once its visibility timeout expires", func() {
+		Expect(store.Enqueue(ctx, newJob("a", 0, now))).To(Succeed())
+		first, err := store.Claim(ctx, []string{"a"}, now, now.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = store.Claim(ctx, []string{"a"}, now.Add(time.Second), now.Add(time.Minute))
+		Expect(err).To(MatchError(jobs.ErrNoJob))
+
+		second, err := store.Claim(ctx, []string{"a"}, now.Add(time.Hour), now.Add(2*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second.ID).To(Equal(first.ID))
+		Expect(second.Attempts).To(Equal(2))
+
+		Expect(store.Complete(ctx, first)).To(MatchError(jobs.ErrLeaseLost))
+		Expect(store.Extend(ctx, first, now.Add(time.Hour))).To(MatchError(jobs.ErrLeaseLost))
+		Expect(store.Complete(ctx, second)).To(Succeed())
+	})
+
+	It("should bury the jobs whose lease expired on their last attempt", func() {
+		abandoned := newJob("a", 10, now)
+		next := newJob("a", 0, now)
+		for _, job := range []*jobs.Job{abandoned, next} {
+			Expect(store.Enqueue(ctx, job)).To(Succeed())
+		}
+		// The workers claiming the job die before recording an outcome.
+		at := now
+		for attempt := 1; attempt <= abandoned.MaxAttempts; attempt++ {
+			job, err := store.Claim(ctx, []string{"a"}, at, at.Add(time.Minute))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.ID).To(Equal(abandoned.ID))
+			Expect(job.Attempts).To(Equal(attempt))
+			at = at.Add(time.Hour)
+		}
+
+		job, err := store.Claim(ctx, []string{"a"}, at, at.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.ID).To(Equal(next.ID))
+		dead, err := store.DeadJobs(ctx, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dead).To(HaveLen(1))
+		Expect(dead[0].ID).To(Equal(abandoned.ID))
+		Expect(dead[0].Status).To(Equal(jobs.StatusDead))
+		Expect(dead[0].Attempts).To(Equal(abandoned.MaxAttempts))
+		Expect(dead[0].LastError).NotTo(BeEmpty())
+	})
+
+	It("should queue retried and released jobs again", func() {
+		Expect(store.Enqueue(ctx, newJob("a", 0, now))).To(Succeed())
+		job, err := store.Claim(ctx, []string{"a"}, now, now.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Retry(ctx, job, now.Add(time.Second), "boom")).To(Succeed())
+
+		_, err = store.Claim(ctx, []string{"a"}, now, now.Add(time.Minute))
+		Expect(err).To(MatchError(jobs.ErrNoJob))
+		job, err = store.Claim(ctx, []string{"a"}, now.Add(time.Second), now.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Attempts).To(Equal(2))
+		Expect(job.LastError).To(Equal("boom"))
+
+		Expect(store.Release(ctx, job)).To(Succeed())
+		job, err = store.Claim(ctx, []string{"a"}, time.Now(), time.Now().Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Attempts).To(Equal(2))
+	})
+
+	It("should move buried jobs to the dead letters", func() {
+		Expect(store.Enqueue(ctx, newJob("a", 0, now))).To(Succeed())
+		job, err := store.Claim(ctx, []string{"a"}, now, now.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Bury(ctx, job, "boom")).To(Succeed())
+
+		_, err = store.Claim(ctx, []string{"a"}, now.Add(time.Hour), now.Add(2*time.Hour))
+		Expect(err).To(MatchError(jobs.ErrNoJob))
+		dead, err := store.DeadJobs(ctx, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dead).To(HaveLen(1))
+		Expect(dead[0].ID).To(Equal(job.ID))
+		Expect(dead[0].Status).To(Equal(jobs.StatusDead))
+		Expect(dead[0].LastError).To(Equal("boom"))
+	})
+}
+
+var _ = Describe("MemoryStore", func() {
+	describeStore(func() jobs.Store {
+		return jobs.NewMemoryStore()
+	})
+})
+
+var _ = Describe("MongoStore", Ordered, func() {
+	ctx := context.Background()
+
+	BeforeAll(func() {
+		if _, err := os.Stat("../../../configs/test.yaml"); err != nil {
+			Skip("no test configuration, skipping MongoDB integration specs")
+		}
+		helpers.Must(os.Setenv("MODE", "test"))
+		DeferCleanup(func() {
+			helpers.Must(os.Unsetenv("MODE"))
+		})
+
+		initialize.LoadConfig("../../../configs/")
+		initialize.InitLogger()
+		Expect(initialize.InitDatabase(ctx)).To(Succeed())
+		DeferCleanup(func() {
+			Expect(global.MongoDB.Disconnect(ctx)).To(Succeed())
+		})
+	})
+
+	describeStore(func() jobs.Store {
+		// Each spec starts from empty collections.
+		for _, name := range []string{"jobs", "dead_jobs"} {
+			Expect(global.MongoDB.DB.Collection(name).Drop(ctx)).To(Succeed())
+		}
+		store := jobs.NewMongoStore(global.MongoDB.DB)
+		Expect(store.EnsureIndexes(ctx)).To(Succeed())
+		return store
+	})
+})
+
+var _ = Describe("Queue", func() {
+	var store *jobs.MemoryStore
+	var queue *jobs.Queue
+	var ctx context.Context
+
+	BeforeEach(func() {
+		store = jobs.NewMemoryStore()
+		ctx = context.Background()
+		queue = jobs.NewQueue(
+			store,
+			jobs.WithConcurrency(2),
+			jobs.WithPollInterval(10*time.Millisecond),
+			jobs.WithBackoff(time.Millisecond, 5*time.Millisecond),
+		)
+	})
+
+	AfterEach(func() {
+		Expect(queue.Stop(ctx)).To(Succeed())
+	})
+
+	// deadJobs returns the dead letters of the store.
+	deadJobs := func() []jobs.Job {
+		dead, err := store.DeadJobs(ctx, 10)
+		Expect(err).NotTo(HaveOccurred())
+		return dead
+	}
+
+	It("should run the handler of the type with the payload", func() {
+		received := make(chan email, 1)
+		jobs.Handle(queue, sendEmail, func(ctx context.Context, payload email) error {
+			Expect(jobs.FromContext(ctx).Type).To(Equal(sendEmail.Name()))
+			received <- payload
+			return nil
+		})
+		queue.Start()
+
+		_, err := jobs.Enqueue(ctx, queue, sendEmail, email{To: "a@b.co", Subject: "Hi"})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(received).Should(Receive(Equal(email{To: "a@b.co", Subject: "Hi"})))
+	})
+
+	It("should run the most urgent jobs first", func() {
+		queue = jobs.NewQueue(store, jobs.WithConcurrency(1))
+		order := make(chan string, 3)
+		jobs.Handle(queue, sendEmail, func(_ context.Context, payload email) error {
+			order <- payload.To
+			return nil
+		})
+		for to, priority := range map[string]int{"low": 0, "high": 10, "medium": 5} {
+			_, err := jobs.Enqueue(ctx, queue, sendEmail, email{To: to}, jobs.WithPriority(priority))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		queue.Start()
+
+		var ran []string
+		for range 3 {
+			var to string
+			Eventually(order).Should(Receive(&to))
+			ran = append(ran, to)
+		}
+		Expect(ran).To(Equal([]string{"high", "medium", "low"}))
+	})
+
+	It("should run delayed jobs once their delay elapsed", func() {
+		ran := make(chan time.Time, 1)
+		jobs.Handle(queue, sendEmail, func(context.Context, email) error {
+			ran <- time.Now()
+			return nil
+		})
+		queue.Start()
+
+		enqueued := time.Now()
+		_, err := jobs.Enqueue(ctx, queue, sendEmail, email{}, jobs.WithDelay(100*time.Millisecond))
+		Expect(err).NotTo(HaveOccurred())
+		var at time.Time
+		Eventually(ran).Should(Receive(&at))
+		Expect(at.Sub(enqueued)).To(BeNumerically(">=", 100*time.Millisecond))
+	})
+
+	It("should retry failed jobs, then move them to the dead letters", func() {
+		var attempts atomic.Int32
+		jobs.Handle(queue, sendEmail, func(context.Context, email) error {
+			attempts.Add(1)
+			return errors.New("smtp unavailable")
+		})
+		queue.Start()
+
+		_, err := jobs.Enqueue(ctx, queue, sendEmail, email{}, jobs.WithAttempts(3))
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(deadJobs).Should(HaveLen(1))
+		Expect(attempts.Load()).To(Equal(int32(3)))
+		Expect(deadJobs()[0].Attempts).To(Equal(3))
+		Expect(deadJobs()[0].LastError).To(Equal("smtp unavailable"))
+	})
+
+	It("should not retry permanent errors", func() {
+		var attempts atomic.Int32
+		jobs.Handle(queue, sendEmail, func(context.Context, email) error {
+			attempts.Add(1)
+			return jobs.Permanent(errors.New("invalid recipient"))
+		})
+		queue.Start()
+
+		_, err := jobs.Enqueue(ctx, queue, sendEmail, email{To: "invalid"})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(deadJobs).Should(HaveLen(1))
+		Expect(attempts.Load()).To(Equal(int32(1)))
+		Expect(deadJobs()[0].LastError).To(Equal("invalid recipient"))
+	})
+
+	It("should fail the jobs whose handler panics", func() {
+		jobs.Handle(queue, sendEmail, func(context.Context, email) error {
+			panic("no recipient")
+		})
+		queue.Start()
+
+		_, err := jobs.Enqueue(ctx, queue, sendEmail, email{}, jobs.WithAttempts(1))
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(deadJobs).Should(HaveLen(1))
+		Expect(deadJobs()[0].LastError).To(Equal("panic: no recipient"))
+	})
+
+	It("should wait for the running jobs when stopping", func() {
+		started, done := make(chan struct{}), make(chan struct{})
+		jobs.Handle(queue, sendEmail, func(context.Context, email) error {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			close(done)
+			return nil
+		})
+		queue.Start()
+
+		job, err := jobs.Enqueue(ctx, queue, sendEmail, email{})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(started).Should(BeClosed())
+		Expect(queue.Stop(ctx)).To(Succeed())
+		Expect(done).To(BeClosed())
+		Expect(store.Complete(ctx, job)).To(MatchError(jobs.ErrLeaseLost))
+	})
+
+	It("should queue the interrupted jobs again when stopping times out", func() {
+		started := make(chan struct{})
+		jobs.Handle(queue, sendEmail, func(ctx context.Context, _ email) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		queue.Start()
+
+		_, err := jobs.Enqueue(ctx, queue, sendEmail, email{})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(started).Should(BeClosed())
+		stopCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		Expect(queue.Stop(stopCtx)).To(MatchError(context.DeadlineExceeded))
+
+		job, err := store.Claim(ctx, []string{sendEmail.Name()}, time.Now(), time.Now())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Attempts).To(Equal(1))
+		Expect(deadJobs()).To(BeEmpty())
+	})
+
+	It("should cancel the jobs whose lease was lost", func() {
+		queue = jobs.NewQueue(
+			store,
+			jobs.WithVisibilityTimeout(20*time.Millisecond),
+			jobs.WithPollInterval(time.Hour),
+		)
+		canceled := make(chan struct{})
+		jobs.Handle(queue, sendEmail, func(ctx context.Context, _ email) error {
+			// Another worker claims the job, as if this one had stalled, and keeps it.
+			later := time.Now().Add(time.Hour)
+			_, err := store.Claim(ctx, []string{sendEmail.Name()}, later, later)
+			Expect(err).NotTo(HaveOccurred())
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		})
+		queue.Start()
+
+		_, err := jobs.Enqueue(ctx, queue, sendEmail, email{})
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(canceled).Should(BeClosed())
+	})
+})
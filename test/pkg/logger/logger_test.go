@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/logger"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/redact"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// credentials is logged with zap.Object.
+type credentials struct {
+	email, password string
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler.
+func (c credentials) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("email", c.email)
+	enc.AddString("password", c.password)
+	return nil
+}
+
+func TestLogger(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Logger Suite")
+}
+
+// readLines decodes the JSON lines written to the log file.
+func readLines(fileName string) []map[string]any {
+	file, err := os.Open(fileName)
+	Expect(err).NotTo(HaveOccurred())
+	defer file.Close()
+
+	var lines []map[string]any
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line map[string]any
+		Expect(json.Unmarshal(scanner.Bytes(), &line)).To(Succeed())
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+var _ = Describe("Logger", func() {
+	var config setting.LoggerSettings
+
+	BeforeEach(func() {
+		config = setting.LoggerSettings{
+			FileName:    filepath.Join(GinkgoT().TempDir(), "app.log"),
+			Level:       "info",
+			MaxSize:     1,
+			ServiceName: "users-api",
+			InstanceID:  "instance-1",
+			Console:     setting.LogOutputSettings{Level: "error"},
+		}
+	})
+
+	It("should write JSON lines with the default fields to the file", func() {
+		log := logger.NewLogger(config, setting.TestMode, "v1.0.0", "abc123")
+		log.Info("hello")
+
+		lines := readLines(config.FileName)
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0]).To(HaveKeyWithValue("msg", "hello"))
+		Expect(lines[0]).To(HaveKeyWithValue("service", "users-api"))
+		Expect(lines[0]).To(HaveKeyWithValue("instance_id", "instance-1"))
+		Expect(lines[0]).To(HaveKeyWithValue("version", "v1.0.0"))
+		Expect(lines[0]).To(HaveKeyWithValue("commit", "abc123"))
+	})
+
+	It("should apply the level of the output", func() {
+		config.File.Level = "warn"
+		log := logger.NewLogger(config, setting.TestMode, "v1.0.0", "abc123")
+		log.Info("skipped")
+		log.Warn("kept")
+
+		lines := readLines(config.FileName)
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0]).To(HaveKeyWithValue("msg", "kept"))
+	})
+
+	It("should follow the logger level when the output has none", func() {
+		log := logger.NewLogger(config, setting.TestMode, "v1.0.0", "abc123")
+		log.Debug("skipped")
+		log.Info("kept")
+
+		lines := readLines(config.FileName)
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0]).To(HaveKeyWithValue("msg", "kept"))
+	})
+
+	It("should give module loggers their own level", func() {
+		config.Modules = map[string]string{"mongodb": "debug"}
+		log := logger.NewLogger(config, setting.TestMode, "v1.0.0", "abc123")
+
+		log.Module("mongodb").Debug("module debug")
+		log.Module("users").Debug("skipped")
+		log.Debug("skipped")
+
+		lines := readLines(config.FileName)
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0]).To(HaveKeyWithValue("msg", "module debug"))
+		Expect(lines[0]).To(HaveKeyWithValue("logger", "mongodb"))
+	})
+
+	It("should change the module levels at runtime", func() {
+		log := logger.NewLogger(config, setting.TestMode, "v1.0.0", "abc123")
+		mongoLog := log.Module("mongodb")
+
+		log.SetModuleLevel("mongodb", "debug")
+		mongoLog.Debug("debug enabled")
+		log.SetModuleLevel("mongodb", "")
+		mongoLog.Debug("skipped")
+
+		Expect(readLines(config.FileName)).To(HaveLen(1))
+		Expect(log.ModuleLevel("mongodb")).To(Equal(log.Level()))
+	})
+
+	It("should sample repeated lines", func() {
+		config.Sampling = setting.LogSamplingSettings{
+			Enabled:    true,
+			Tick:       time.Minute,
+			Initial:    2,
+			Thereafter: 100,
+		}
+		log := logger.NewLogger(config, setting.TestMode, "v1.0.0", "abc123")
+		for i := 0; i < 10; i++ {
+			log.Info("hot path")
+		}
+
+		Expect(readLines(config.FileName)).To(HaveLen(2))
+	})
+
+	It("should redact sensitive fields", func() {
+		log := logger.NewLogger(config, setting.TestMode, "v1.0.0", "abc123")
+		log.Info("login",
+			zap.String("email", "test@example.com"),
+			zap.String("password", "secret"),
+			zap.Any("user", map[string]any{"verification_code": "123456"}),
+		)
+
+		lines := readLines(config.FileName)
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0]).To(HaveKeyWithValue("email", "test@example.com"))
+		Expect(lines[0]).To(HaveKeyWithValue("password", redact.Placeholder))
+		Expect(lines[0]).To(HaveKeyWithValue(
+			"user", HaveKeyWithValue("verification_code", redact.Placeholder),
+		))
+	})
+
+	It("should redact the sensitive content of objects and arrays", func() {
+		log := logger.NewLogger(config, setting.TestMode, "v1.0.0", "abc123")
+		log.Info("login",
+			zap.Object("credentials", credentials{email: "test@example.com", password: "secret"}),
+			zap.Objects("attempts", []credentials{{email: "a@example.com", password: "secret"}}),
+			zap.Strings("roles", []string{"admin", "staff"}),
+			zap.NamedError("token", errors.New("secret")),
+			zap.Error(errors.New("invalid credentials")),
+		)
+
+		lines := readLines(config.FileName)
+		Expect(lines).To(HaveLen(1))
+		Expect(lines[0]).To(HaveKeyWithValue("credentials", Equal(map[string]any{
+			"email":    "test@example.com",
+			"password": redact.Placeholder,
+		})))
+		Expect(lines[0]).To(HaveKeyWithValue("attempts", ConsistOf(Equal(map[string]any{
+			"email":    "a@example.com",
+			"password": redact.Placeholder,
+		}))))
+		Expect(lines[0]).To(HaveKeyWithValue("roles", ConsistOf("admin", "staff")))
+		Expect(lines[0]).To(HaveKeyWithValue("token", redact.Placeholder))
+		Expect(lines[0]).To(HaveKeyWithValue("error", "invalid credentials"))
+	})
+
+	It("should keep the fields holding nothing sensitive as they are", func() {
+		log := logger.NewLogger(config, setting.TestMode, "v1.0.0", "abc123")
+		log.Info("batch",
+			zap.Any("ids", map[string]int64{"first": 9007199254740993}),
+			zap.Any("user", map[string]any{"id": int64(9007199254740995), "password": "secret"}),
+		)
+
+		content, err := os.ReadFile(config.FileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring(`"first":9007199254740993`))
+		Expect(string(content)).To(ContainSubstring(`"id":9007199254740995`))
+		Expect(string(content)).NotTo(ContainSubstring("secret"))
+	})
+})
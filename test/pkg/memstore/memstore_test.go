@@ -0,0 +1,108 @@
+package memstore
+
+import (
+	"testing"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/memstore"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestMemstore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Memstore Suite")
+}
+
+type item struct {
+	ID    bson.ObjectID `bson:"_id"`
+	Email string        `bson:"email"`
+	Name  string        `bson:"name,omitempty"`
+}
+
+var _ = Describe("Collection", func() {
+	var collection *memstore.Collection[item]
+
+	BeforeEach(func() {
+		collection = memstore.NewCollection[item]("email")
+	})
+
+	It("should find inserted documents", func() {
+		doc := &item{ID: bson.NewObjectID(), Email: "a@example.com", Name: "A"}
+		Expect(collection.Insert(doc)).To(Succeed())
+
+		found, err := collection.FindOne(bson.M{"_id": doc.ID})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*found).To(Equal(*doc))
+
+		_, err = collection.FindOne(bson.M{"email": "b@example.com"})
+		Expect(err).To(MatchError(memstore.ErrNotFound))
+	})
+
+	It("should enforce unique keys", func() {
+		Expect(collection.Insert(&item{ID: bson.NewObjectID(), Email: "a@example.com"})).To(Succeed())
+
+		err := collection.Insert(&item{ID: bson.NewObjectID(), Email: "a@example.com"})
+		Expect(err).To(MatchError(memstore.ErrDuplicateKey))
+		Expect(collection.Len()).To(Equal(1))
+	})
+
+	It("should apply $set and $unset updates", func() {
+		doc := &item{ID: bson.NewObjectID(), Email: "a@example.com", Name: "A"}
+		Expect(collection.Insert(doc)).To(Succeed())
+
+		updated, err := collection.FindOneAndUpdate(bson.M{"_id": doc.ID}, bson.D{
+			{Key: "$set", Value: bson.D{{Key: "email", Value: "b@example.com"}}},
+			{Key: "$unset", Value: bson.D{{Key: "name", Value: ""}}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.Email).To(Equal("b@example.com"))
+		Expect(updated.Name).To(BeEmpty())
+	})
+
+	It("should reject updates breaking a unique key", func() {
+		doc := &item{ID: bson.NewObjectID(), Email: "a@example.com"}
+		Expect(collection.Insert(doc)).To(Succeed())
+		Expect(collection.Insert(&item{ID: bson.NewObjectID(), Email: "b@example.com"})).To(Succeed())
+
+		_, err := collection.FindOneAndUpdate(bson.M{"_id": doc.ID}, bson.D{
+			{Key: "$set", Value: bson.D{{Key: "email", Value: "b@example.com"}}},
+		})
+		Expect(err).To(MatchError(memstore.ErrDuplicateKey))
+	})
+
+	It("should replace documents", func() {
+		doc := &item{ID: bson.NewObjectID(), Email: "a@example.com", Name: "A"}
+		Expect(collection.Insert(doc)).To(Succeed())
+		Expect(collection.Insert(&item{ID: bson.NewObjectID(), Email: "b@example.com"})).To(Succeed())
+
+		doc.Name = "B"
+		Expect(collection.ReplaceOne(bson.M{"_id": doc.ID}, doc)).To(Succeed())
+		found, err := collection.FindOne(bson.M{"_id": doc.ID})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.Name).To(Equal("B"))
+
+		doc.Email = "b@example.com"
+		Expect(collection.ReplaceOne(bson.M{"_id": doc.ID}, doc)).To(MatchError(memstore.ErrDuplicateKey))
+		Expect(collection.ReplaceOne(bson.M{"_id": bson.NewObjectID()}, doc)).
+			To(MatchError(memstore.ErrNotFound))
+	})
+
+	It("should delete documents", func() {
+		doc := &item{ID: bson.NewObjectID(), Email: "a@example.com"}
+		Expect(collection.Insert(doc)).To(Succeed())
+
+		deleted, err := collection.DeleteOne(bson.M{"_id": doc.ID})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeTrue())
+
+		deleted, err = collection.DeleteOne(bson.M{"_id": doc.ID})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deleted).To(BeFalse())
+	})
+
+	It("should reject operators in filters", func() {
+		_, err := collection.Find(bson.M{"email": bson.M{"$ne": ""}, "$or": bson.A{}})
+		Expect(err).To(HaveOccurred())
+	})
+})
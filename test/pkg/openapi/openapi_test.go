@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/openapi"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestOpenAPI(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OpenAPI Suite")
+}
+
+type Base struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Tag struct {
+	Name string `json:"name" validate:"required,max=20"`
+}
+
+type ItemDto struct {
+	Base     `json:",inline"`
+	Name     string   `json:"name" validate:"required,min=2,max=50"`
+	Email    string   `json:"email,omitempty" validate:"omitempty,email"`
+	Kind     string   `json:"kind" validate:"oneof=book movie"`
+	Price    float64  `json:"price" validate:"gt=0"`
+	Quantity int      `json:"quantity" validate:"gte=1,lte=10"`
+	Tags     []Tag    `json:"tags" validate:"max=5,dive"`
+	Parent   *ItemDto `json:"parent,omitempty"`
+	Secret   string   `json:"-"`
+	internal string
+}
+
+var _ = Describe("Schemas", func() {
+	var (
+		schemas *openapi.Schemas
+		item    *openapi.Schema
+	)
+
+	BeforeEach(func() {
+		schemas = openapi.NewSchemas()
+		Expect(schemas.Of(ItemDto{})).To(Equal(openapi.Ref("ItemDto")))
+		item = schemas.Components()["ItemDto"]
+	})
+
+	It("should inline embedded structs and skip hidden fields", func() {
+		Expect(item.Properties).To(HaveKey("id"))
+		Expect(item.Properties["created_at"].Format).To(Equal("date-time"))
+		Expect(item.Properties).NotTo(HaveKey("Secret"))
+		Expect(item.Properties).NotTo(HaveKey("internal"))
+	})
+
+	It("should turn validate tags into constraints", func() {
+		Expect(item.Required).To(ConsistOf("name"))
+		Expect(*item.Properties["name"].MinLength).To(Equal(2))
+		Expect(*item.Properties["name"].MaxLength).To(Equal(50))
+		Expect(item.Properties["email"].Format).To(Equal("email"))
+		Expect(item.Properties["kind"].Enum).To(Equal([]any{"book", "movie"}))
+		Expect(*item.Properties["price"].ExclusiveMinimum).To(Equal(0.0))
+		Expect(*item.Properties["quantity"].Minimum).To(Equal(1.0))
+		Expect(*item.Properties["quantity"].Maximum).To(Equal(10.0))
+		Expect(*item.Properties["tags"].MaxItems).To(Equal(5))
+	})
+
+	It("should reference named structs, recursive ones included", func() {
+		Expect(item.Properties["tags"].Items).To(Equal(openapi.Ref("Tag")))
+		Expect(schemas.Components()["Tag"].Required).To(ConsistOf("name"))
+		Expect(item.Properties["parent"]).To(Equal(openapi.Ref("ItemDto")))
+	})
+})
+
+var _ = Describe("Spec", func() {
+	var engine *gin.Engine
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		engine = gin.New()
+		engine.GET("/v1/items/:id", func(*gin.Context) {})
+		engine.POST("/v1/items", func(*gin.Context) {})
+	})
+
+	It("should document the registered routes", func() {
+		doc, err := openapi.New(openapi.Info{Title: "API", Version: "v1"}).
+			Add(openapi.Route{
+				Method:   http.MethodGet,
+				Path:     "/v1/items/:id",
+				Response: ItemDto{},
+				Errors:   []int{http.StatusNotFound},
+			}).
+			Add(openapi.Route{
+				Method:  http.MethodPost,
+				Path:    "/v1/items",
+				Request: ItemDto{},
+				Status:  http.StatusCreated,
+			}).
+			Build(engine.Routes())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(doc.OpenAPI).To(Equal(openapi.Version))
+		get := (*doc.Paths["/v1/items/{id}"])["get"]
+		Expect(get.Parameters).To(ConsistOf(HaveField("Name", "id")))
+		Expect(get.Responses).To(HaveKey("200"))
+		Expect(get.Responses).To(HaveKey("404"))
+
+		post := (*doc.Paths["/v1/items"])["post"]
+		Expect(post.RequestBody.Content["application/json"].Schema).To(Equal(openapi.Ref("ItemDto")))
+		Expect(post.RequestBody.Content["application/msgpack"].Schema).To(Equal(openapi.Ref("ItemDto")))
+		Expect(post.Responses).To(HaveKey("201"))
+	})
+
+	It("should report the routes drifting from their documentation", func() {
+		doc, err := openapi.New(openapi.Info{Title: "API", Version: "v1"}).
+			Add(openapi.Route{Method: http.MethodGet, Path: "/v1/items/:id"}).
+			Add(openapi.Route{Method: http.MethodDelete, Path: "/v1/items/:id"}).
+			Build(engine.Routes())
+
+		Expect(err).To(MatchError(ContainSubstring("route POST /v1/items is not documented")))
+		Expect(err).To(MatchError(ContainSubstring(
+			"documented route DELETE /v1/items/:id is not registered",
+		)))
+		Expect(doc.Paths).To(HaveLen(1))
+	})
+
+	It("should document raw uploads and downloads", func() {
+		doc, err := openapi.New(openapi.Info{Title: "API", Version: "v1"}).
+			Add(openapi.Route{
+				Method: http.MethodGet, Path: "/v1/items/:id", Download: []string{"text/csv"},
+			}).
+			Add(openapi.Route{
+				Method: http.MethodPost, Path: "/v1/items", Upload: []string{"text/csv"},
+			}).
+			Build(engine.Routes())
+		Expect(err).NotTo(HaveOccurred())
+
+		get := (*doc.Paths["/v1/items/{id}"])["get"]
+		Expect(get.Responses["200"].Content).
+			To(HaveKeyWithValue("text/csv", HaveField("Schema.Type", "string")))
+		post := (*doc.Paths["/v1/items"])["post"]
+		Expect(post.RequestBody.Content).
+			To(HaveKeyWithValue("text/csv", HaveField("Schema.Type", "string")))
+		Expect(post.RequestBody.Content).NotTo(HaveKey("application/json"))
+	})
+
+	Describe("with handlers built by response.Handle", func() {
+		type updateItemRequest struct {
+			ID string `uri:"id"`
+			ItemDto
+		}
+
+		BeforeEach(func() {
+			engine = gin.New()
+			engine.GET("/v1/items/:id", response.Handle(
+				func(context.Context, struct{}) (*ItemDto, *response.Error) { return nil, nil },
+			))
+			engine.PUT("/v1/items/:id", response.Handle(
+				func(context.Context, updateItemRequest) (*ItemDto, *response.Error) { return nil, nil },
+				response.WithStatus(http.StatusAccepted),
+			))
+		})
+
+		It("should accept the documentation of their types", func() {
+			_, err := openapi.New(openapi.Info{Title: "API", Version: "v1"}).
+				Add(openapi.Route{
+					Method:   http.MethodGet,
+					Path:     "/v1/items/:id",
+					Response: response.TDataResponse[*ItemDto]{},
+				}).
+				Add(openapi.Route{
+					Method:   http.MethodPut,
+					Path:     "/v1/items/:id",
+					Request:  ItemDto{},
+					Status:   http.StatusAccepted,
+					Response: response.TDataResponse[*ItemDto]{},
+				}).
+				Build(engine.Routes())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should report the documentation drifting from their types", func() {
+			_, err := openapi.New(openapi.Info{Title: "API", Version: "v1"}).
+				Add(openapi.Route{
+					Method:   http.MethodGet,
+					Path:     "/v1/items/:id",
+					Response: response.TDataResponse[ItemDto]{},
+				}).
+				Add(openapi.Route{
+					Method:   http.MethodPut,
+					Path:     "/v1/items/:id",
+					Request:  Tag{},
+					Response: response.TDataResponse[*ItemDto]{},
+				}).
+				Build(engine.Routes())
+
+			Expect(err).To(MatchError(ContainSubstring("route GET /v1/items/:id documents response")))
+			Expect(err).To(MatchError(ContainSubstring(
+				"route PUT /v1/items/:id documents status 200, its handler has 202",
+			)))
+			Expect(err).To(MatchError(ContainSubstring("route PUT /v1/items/:id documents request")))
+		})
+	})
+
+	It("should leave hidden routes out", func() {
+		doc, err := openapi.New(openapi.Info{Title: "API", Version: "v1"}).
+			Add(openapi.Route{Method: http.MethodGet, Path: "/v1/items/:id"}).
+			Add(openapi.Route{Method: http.MethodPost, Path: "/v1/items", Hidden: true}).
+			Build(engine.Routes())
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(doc.Paths).NotTo(HaveKey("/v1/items"))
+	})
+})
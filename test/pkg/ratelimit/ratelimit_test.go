@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/ratelimit"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRateLimit(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rate Limit Suite")
+}
+
+// itBehavesLikeAStore runs the shared specs against the store returned by newStore.
+func itBehavesLikeAStore(newStore func() ratelimit.Store) {
+	var store ratelimit.Store
+
+	BeforeEach(func() {
+		store = newStore()
+	})
+
+	AfterEach(func() {
+		Expect(store.Close()).To(Succeed())
+	})
+
+	It("should allow requests up to the limit", func() {
+		for i := 0; i < 3; i++ {
+			res, err := store.Allow(context.Background(), "client", 3, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Allowed).To(BeTrue())
+			Expect(res.Limit).To(Equal(3))
+			Expect(res.Remaining).To(Equal(2 - i))
+		}
+	})
+
+	It("should reject requests over the limit with a retry delay", func() {
+		for i := 0; i < 3; i++ {
+			_, err := store.Allow(context.Background(), "client", 3, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		res, err := store.Allow(context.Background(), "client", 3, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(BeFalse())
+		Expect(res.Remaining).To(BeZero())
+		Expect(res.RetryAfter).To(BeNumerically(">", 0))
+		Expect(res.RetryAfter).To(BeNumerically("<=", time.Minute))
+	})
+
+	It("should count keys independently", func() {
+		res, err := store.Allow(context.Background(), "first", 1, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(BeTrue())
+
+		res, err = store.Allow(context.Background(), "second", 1, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(BeTrue())
+
+		res, err = store.Allow(context.Background(), "first", 1, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Allowed).To(BeFalse())
+	})
+}
+
+var _ = Describe("MemoryStore", func() {
+	itBehavesLikeAStore(func() ratelimit.Store {
+		return ratelimit.NewMemoryStore()
+	})
+})
+
+var _ = Describe("RedisStore", func() {
+	var server *miniredis.Miniredis
+
+	BeforeEach(func() {
+		server = miniredis.NewMiniRedis()
+		Expect(server.Start()).To(Succeed())
+		DeferCleanup(server.Close)
+	})
+
+	itBehavesLikeAStore(func() ratelimit.Store {
+		return ratelimit.NewRedisStore(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	})
+
+	It("should expire the counters", func() {
+		store := ratelimit.NewRedisStore(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+		defer store.Close()
+
+		_, err := store.Allow(context.Background(), "client", 1, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		keys := server.Keys()
+		Expect(keys).To(HaveLen(1))
+		Expect(server.TTL(keys[0])).To(Equal(2 * time.Minute))
+	})
+})
@@ -0,0 +1,97 @@
+package redact
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/redact"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestRedact(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Redact Suite")
+}
+
+var _ = Describe("Redactor", func() {
+	var redactor *redact.Redactor
+
+	BeforeEach(func() {
+		redactor = redact.New(nil)
+	})
+
+	It("should match field names ignoring case and separators", func() {
+		Expect(redactor.Matches("password")).To(BeTrue())
+		Expect(redactor.Matches("new_password")).To(BeTrue())
+		Expect(redactor.Matches("verificationCode")).To(BeTrue())
+		Expect(redactor.Matches("Authorization")).To(BeTrue())
+		Expect(redactor.Matches("email")).To(BeFalse())
+	})
+
+	It("should use the configured patterns", func() {
+		redactor = redact.New([]string{"ssn"})
+
+		Expect(redactor.Matches("user_ssn")).To(BeTrue())
+		Expect(redactor.Matches("password")).To(BeFalse())
+	})
+
+	It("should redact nested values", func() {
+		value := redactor.Value(map[string]any{
+			"email": "test@example.com",
+			"user": map[string]any{
+				"password": "secret",
+				"tokens":   []any{"a", "b"},
+			},
+		})
+
+		Expect(value).To(Equal(map[string]any{
+			"email": "test@example.com",
+			"user": map[string]any{
+				"password": redact.Placeholder,
+				"tokens":   redact.Placeholder,
+			},
+		}))
+	})
+
+	It("should tell whether a value holds sensitive fields", func() {
+		Expect(redactor.Sensitive(map[string]any{
+			"user": []any{map[string]any{"password": "secret"}},
+		})).To(BeTrue())
+		Expect(redactor.Sensitive(bson.D{{Key: "token", Value: "secret"}})).To(BeTrue())
+		Expect(redactor.Sensitive(map[string]any{"email": "test@example.com"})).To(BeFalse())
+		Expect(redactor.Sensitive("password")).To(BeFalse())
+	})
+
+	It("should redact a Mongo command", func() {
+		command, err := bson.Marshal(bson.D{
+			{Key: "insert", Value: "users"},
+			{Key: "documents", Value: bson.A{bson.D{
+				{Key: "email", Value: "test@example.com"},
+				{Key: "password", Value: "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA"},
+				{Key: "verification_code", Value: "123456"},
+			}}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		doc := redactor.Document(command)
+
+		Expect(doc).To(ContainSubstring("test@example.com"))
+		Expect(doc).NotTo(ContainSubstring("argon2id"))
+		Expect(doc).NotTo(ContainSubstring("123456"))
+		Expect(doc).To(ContainSubstring(redact.Placeholder))
+	})
+
+	It("should redact query parameters and headers", func() {
+		query := redactor.Query("email=a%40b.io&token=abc")
+		Expect(query).To(Equal("email=a%40b.io&token=%5BREDACTED%5D"))
+
+		header := redactor.Header(http.Header{
+			"Authorization": {"Bearer abc"},
+			"Accept":        {"application/json"},
+		})
+		Expect(header.Get("Authorization")).To(Equal(redact.Placeholder))
+		Expect(header.Get("Accept")).To(Equal("application/json"))
+	})
+})
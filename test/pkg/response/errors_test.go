@@ -57,6 +57,13 @@ var _ = Describe("Error Handling", func() {
 			{"StolenToken", response.ErrStolenToken, http.StatusUnauthorized},
 			{"InvalidObjectID", response.ErrInvalidObjectID, http.StatusBadRequest},
 			{"Validation", response.ErrValidation, http.StatusBadRequest},
+			{"TooManyRequests", response.ErrTooManyRequests, http.StatusTooManyRequests},
+			{"Conflict", response.ErrConflict, http.StatusConflict},
+			{"Unprocessable", response.ErrUnprocessable, http.StatusUnprocessableEntity},
+			{"Unavailable", response.ErrUnavailable, http.StatusServiceUnavailable},
+			{"NotAcceptable", response.ErrNotAcceptable, http.StatusNotAcceptable},
+			{"UnsupportedMedia", response.ErrUnsupportedMedia, http.StatusUnsupportedMediaType},
+			{"PayloadTooLarge", response.ErrPayloadTooLarge, http.StatusRequestEntityTooLarge},
 			{"DefaultError", errors.New("unknown error"), http.StatusInternalServerError},
 		}
 
@@ -0,0 +1,175 @@
+package response
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/ugorji/go/codec"
+)
+
+// codecHandles are the handles decoding and encoding the binary formats in the tests.
+var codecHandles = map[string]codec.Handle{
+	response.MIMEMsgPack: &codec.MsgpackHandle{},
+	response.MIMECBOR:    &codec.CborHandle{},
+}
+
+// decodeAs decodes a body in one of the binary formats.
+func decodeAs(mediaType string, body []byte, v any) error {
+	return codec.NewDecoderBytes(body, codecHandles[mediaType]).Decode(v)
+}
+
+var _ = Describe("Content negotiation", func() {
+	var engine *gin.Engine
+	calls := 0
+
+	serve := func(
+		method, target, accept, contentType string,
+		body []byte,
+	) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		calls = 0
+		engine = gin.New()
+		engine.Use(response.Acceptable())
+		engine.GET("/items", func(c *gin.Context) {
+			response.OkResponse(c, "Found the items", []string{"book", "pen"})
+		})
+		engine.PUT("/items/:id", response.Handle(
+			func(_ context.Context, req ItemRequest) (ItemRequest, *response.Error) {
+				calls++
+				return req, nil
+			},
+			response.WithValidator(validator.New()),
+		))
+	})
+
+	It("should render JSON by default", func() {
+		for _, accept := range []string{"", "*/*", "application/*", "text/html, */*;q=0.8"} {
+			w := serve(http.MethodGet, "/items", accept, "", nil)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(HavePrefix(response.MIMEJSON))
+			Expect(w.Header().Get("Vary")).To(Equal("Accept"))
+		}
+	})
+
+	for _, mediaType := range []string{response.MIMEMsgPack, response.MIMECBOR} {
+		It("should render the same envelope in "+mediaType, func() {
+			w := serve(http.MethodGet, "/items", mediaType, "", nil)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("Content-Type")).To(Equal(mediaType))
+			var res response.TDataResponse[[]string]
+			Expect(decodeAs(mediaType, w.Body.Bytes(), &res)).To(Succeed())
+			Expect(res).To(Equal(response.TDataResponse[[]string]{
+				TResponse: response.TResponse{Code: http.StatusOK, Message: "Found the items"},
+				Data:      []string{"book", "pen"},
+			}))
+
+			var fields map[string]any
+			Expect(decodeAs(mediaType, w.Body.Bytes(), &fields)).To(Succeed())
+			Expect(fields).To(HaveKey("code"))
+			Expect(fields).To(HaveKey("message"))
+			Expect(fields).To(HaveKey("data"))
+		})
+
+		It("should bind "+mediaType+" bodies", func() {
+			var body []byte
+			Expect(codec.NewEncoderBytes(&body, codecHandles[mediaType]).Encode(map[string]any{
+				"name":  "book",
+				"count": 2,
+			})).To(Succeed())
+
+			w := serve(http.MethodPut, "/items/42", mediaType, mediaType+"; charset=utf-8", body)
+
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var res response.TDataResponse[ItemRequest]
+			Expect(decodeAs(mediaType, w.Body.Bytes(), &res)).To(Succeed())
+			Expect(res.Data).To(Equal(ItemRequest{ID: "42", Name: "book", Count: 2}))
+		})
+	}
+
+	It("should follow the quality of the media ranges", func() {
+		w := serve(http.MethodGet, "/items", "application/json;q=0.5, application/cbor", "", nil)
+		Expect(w.Header().Get("Content-Type")).To(Equal(response.MIMECBOR))
+
+		w = serve(http.MethodGet, "/items", "application/json;q=0, */*", "", nil)
+		Expect(w.Header().Get("Content-Type")).To(Equal(response.MIMEMsgPack))
+
+		w = serve(http.MethodGet, "/items", "application/x-msgpack", "", nil)
+		Expect(w.Header().Get("Content-Type")).To(Equal(response.MIMEMsgPack))
+	})
+
+	It("should render the errors in the negotiated format", func() {
+		w := serve(
+			http.MethodPut, "/items/42", response.MIMECBOR, response.MIMEJSON, []byte(`{"count":-1}`),
+		)
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Header().Get("Content-Type")).To(Equal(response.MIMECBOR))
+		var res response.TErrResponse[[]response.Violation]
+		Expect(decodeAs(response.MIMECBOR, w.Body.Bytes(), &res)).To(Succeed())
+		Expect(res.ErrorCode).To(Equal("VALIDATION_ERROR"))
+		Expect(res.Errors).To(ContainElement(HaveField("Rule", "required")))
+	})
+
+	It("should answer 406 in the JSON envelope when no format is acceptable", func() {
+		w := serve(http.MethodGet, "/items", "text/html", "", nil)
+
+		Expect(w.Code).To(Equal(http.StatusNotAcceptable))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix(response.MIMEJSON))
+		var res response.TErrResponse[string]
+		Expect(json.NewDecoder(w.Body).Decode(&res)).To(Succeed())
+		Expect(res.ErrorCode).To(Equal("NOT_ACCEPTABLE"))
+		Expect(res.Errors).To(ContainSubstring(response.MIMEMsgPack))
+	})
+
+	It("should answer 406 before running the handler", func() {
+		w := serve(
+			http.MethodPut, "/items/42", "text/xml", response.MIMEJSON, []byte(`{"name":"book"}`),
+		)
+
+		Expect(w.Code).To(Equal(http.StatusNotAcceptable))
+		Expect(calls).To(BeZero())
+	})
+
+	It("should answer 415 in the envelope for unsupported bodies", func() {
+		w := serve(
+			http.MethodPut, "/items/42", response.MIMEMsgPack, "text/plain", []byte("name=book"),
+		)
+
+		Expect(w.Code).To(Equal(http.StatusUnsupportedMediaType))
+		Expect(w.Header().Get("Content-Type")).To(Equal(response.MIMEMsgPack))
+		var res response.TErrResponse[string]
+		Expect(decodeAs(response.MIMEMsgPack, w.Body.Bytes(), &res)).To(Succeed())
+		Expect(res.ErrorCode).To(Equal("UNSUPPORTED_MEDIA_TYPE"))
+		Expect(res.Errors).To(ContainSubstring(response.MIMECBOR))
+		Expect(calls).To(BeZero())
+	})
+
+	It("should list the supported media types", func() {
+		Expect(response.MediaTypes()).To(Equal([]string{
+			response.MIMEJSON, response.MIMEMsgPack, response.MIMECBOR,
+		}))
+	})
+})
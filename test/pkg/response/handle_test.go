@@ -0,0 +1,140 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/fieldset"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type ItemRequest struct {
+	ID    string `uri:"id"`
+	Sort  string `form:"sort"`
+	Name  string `json:"name" validate:"required"`
+	Count int    `json:"count" validate:"gte=0"`
+}
+
+func (r *ItemRequest) Validate(v *validator.Validate) error {
+	return v.Struct(r)
+}
+
+type ItemIDParam struct {
+	ID string `uri:"id"`
+}
+
+var _ = Describe("Handle", func() {
+	var engine *gin.Engine
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		engine = gin.New()
+		engine.PUT("/items/:id", response.Handle(
+			func(_ context.Context, req ItemRequest) (ItemRequest, *response.Error) {
+				if req.Name == "missing" {
+					return req, response.NewError(response.ErrNotFound, errors.New("item not found"))
+				}
+				return req, nil
+			},
+			response.WithStatus(http.StatusAccepted),
+			response.WithMessage("Saved the item"),
+			response.WithValidator(validator.New()),
+		))
+	})
+
+	It("should bind the body, the query and the path", func() {
+		w := serve(http.MethodPut, "/items/42?sort=name", `{"name":"book","count":2}`)
+
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+		var res response.TDataResponse[ItemRequest]
+		Expect(json.NewDecoder(w.Body).Decode(&res)).To(Succeed())
+		Expect(res.Code).To(Equal(http.StatusAccepted))
+		Expect(res.Message).To(Equal("Saved the item"))
+		Expect(res.Data).To(Equal(ItemRequest{ID: "42", Sort: "name", Name: "book", Count: 2}))
+	})
+
+	It("should not let the query override the fields of the body", func() {
+		w := serve(http.MethodPut, "/items/42?sort=name&Name=evil&Count=7", `{"name":"book","count":2}`)
+
+		Expect(w.Code).To(Equal(http.StatusAccepted))
+		var res response.TDataResponse[ItemRequest]
+		Expect(json.NewDecoder(w.Body).Decode(&res)).To(Succeed())
+		Expect(res.Data).To(Equal(ItemRequest{ID: "42", Sort: "name", Name: "book", Count: 2}))
+	})
+
+	It("should render the validation errors", func() {
+		w := serve(http.MethodPut, "/items/42", `{"count":-1}`)
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		var res response.TErrResponse[[]response.Violation]
+		Expect(json.NewDecoder(w.Body).Decode(&res)).To(Succeed())
+		Expect(res.ErrorCode).To(Equal("VALIDATION_ERROR"))
+		Expect(res.Errors).To(ContainElements(
+			HaveField("Rule", "required"),
+			HaveField("Rule", "gte"),
+		))
+	})
+
+	It("should reject malformed bodies", func() {
+		w := serve(http.MethodPut, "/items/42", `{"name":`)
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("should render the errors of the handler", func() {
+		w := serve(http.MethodPut, "/items/42", `{"name":"missing"}`)
+
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		var res response.TErrResponse[string]
+		Expect(json.NewDecoder(w.Body).Decode(&res)).To(Succeed())
+		Expect(res.Errors).To(Equal("item not found"))
+	})
+
+	It("should render the selected fields", func() {
+		var selected fieldset.Set
+		engine.GET("/items/:id", response.Handle(
+			func(ctx context.Context, req ItemIDParam) (*ItemRequest, *response.Error) {
+				selected = fieldset.FromContext(ctx)
+				return &ItemRequest{ID: req.ID, Name: "book", Count: 2}, nil
+			},
+			response.WithFields(),
+		))
+
+		w := serve(http.MethodGet, "/items/42?fields=name,count", "")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(selected).To(Equal(fieldset.Set{"name": nil, "count": nil}))
+		var res response.TDataResponse[map[string]any]
+		Expect(json.NewDecoder(w.Body).Decode(&res)).To(Succeed())
+		Expect(res.Data).To(Equal(map[string]any{"name": "book", "count": float64(2)}))
+
+		w = serve(http.MethodGet, "/items/42?fields=name,price", "")
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring(response.ErrCodeInvalidFields))
+	})
+
+	It("should require a validator for validatable requests", func() {
+		Expect(func() {
+			response.Handle(func(context.Context, ItemRequest) (any, *response.Error) {
+				return nil, nil
+			})
+		}).To(Panic())
+	})
+})
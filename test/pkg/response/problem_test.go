@@ -0,0 +1,107 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Error codes", func() {
+	It("should derive a generic code from the application error", func() {
+		err := response.NewError(response.ErrNotFound, nil)
+		Expect(err.ErrorCode()).To(Equal("NOT_FOUND"))
+	})
+
+	It("should prefer an explicit code", func() {
+		err := response.NewError(response.ErrNotFound, nil).WithCode("USER_NOT_FOUND")
+		Expect(err.ErrorCode()).To(Equal("USER_NOT_FOUND"))
+	})
+
+	It("should fall back to the service error for the detail", func() {
+		err := response.NewError(response.ErrNotFound, errors.New("user not found"))
+		Expect(err.Detail()).To(Equal("user not found"))
+
+		err = err.WithDetail("no user with this id")
+		Expect(err.Detail()).To(Equal("no user with this id"))
+	})
+
+	It("should include the code in the envelope", func() {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/users/1", nil)
+
+		response.ErrorResponse(c, response.NewError(response.ErrNotFound, nil).WithCode("USER_NOT_FOUND"))
+
+		var res response.TErrResponse[string]
+		Expect(json.NewDecoder(w.Body).Decode(&res)).To(Succeed())
+		Expect(res.Code).To(Equal(http.StatusNotFound))
+		Expect(res.ErrorCode).To(Equal("USER_NOT_FOUND"))
+	})
+})
+
+var _ = Describe("Problem details", func() {
+	var (
+		c *gin.Context
+		w *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/v1/users/1", nil)
+	})
+
+	It("should render problem details when the client accepts them", func() {
+		c.Request.Header.Set("Accept", response.ProblemContentType)
+
+		response.ErrorResponse(c, response.NewError(response.ErrNotFound, errors.New("user not found")).
+			WithCode("USER_NOT_FOUND"))
+
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix(response.ProblemContentType))
+
+		var problem response.ProblemDetails
+		Expect(json.NewDecoder(w.Body).Decode(&problem)).To(Succeed())
+		Expect(problem.Status).To(Equal(http.StatusNotFound))
+		Expect(problem.Title).To(Equal("Not Found"))
+		Expect(problem.Code).To(Equal("USER_NOT_FOUND"))
+		Expect(problem.Detail).To(Equal("user not found"))
+		Expect(problem.Instance).To(Equal("/v1/users/1"))
+	})
+
+	It("should render problem details when configured", func() {
+		response.SetErrorFormat(response.ProblemFormat)
+		DeferCleanup(response.SetErrorFormat, response.EnvelopeFormat)
+
+		response.ErrorResponse(c, response.NewError(response.ErrBadRequest, nil))
+
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix(response.ProblemContentType))
+		Expect(w.Body.String()).To(ContainSubstring(`"code":"BAD_REQUEST"`))
+	})
+
+	It("should render validation errors as violations", func() {
+		c.Request.Header.Set("Accept", response.ProblemContentType)
+		err := validator.New().Struct(MockStruct{
+			Email:   "invalid-email",
+			Age:     20,
+			Website: "https://a.io",
+		})
+
+		response.ValidateErrorResponse(c, err)
+
+		var problem response.ProblemDetails
+		Expect(json.NewDecoder(w.Body).Decode(&problem)).To(Succeed())
+		Expect(problem.Status).To(Equal(http.StatusBadRequest))
+		Expect(problem.Code).To(Equal("VALIDATION_ERROR"))
+		Expect(problem.Errors).To(ContainElement(HaveField("Rule", "required")))
+		Expect(problem.Errors).To(ContainElement(HaveField("Rule", "email")))
+	})
+})
@@ -214,6 +214,14 @@ func (m MockValidationErrors) Field() string {
 	return "username"
 }
 
+func (m MockValidationErrors) Namespace() string {
+	return "Mock.username"
+}
+
+func (m MockValidationErrors) Param() string {
+	return ""
+}
+
 func (m MockValidationErrors) Tag() string {
 	return "required"
 }
@@ -0,0 +1,109 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/validations"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type MockAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type MockUser struct {
+	FirstName string      `json:"first_name" validate:"required"`
+	Password  string      `json:"password" validate:"strongPassword"`
+	Role      string      `json:"role" validate:"oneof=admin member"`
+	Address   MockAddress `json:"address"`
+}
+
+var _ = Describe("Structured validation errors", func() {
+	var (
+		c        *gin.Context
+		w        *httptest.ResponseRecorder
+		validate *validator.Validate
+	)
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/users", nil)
+
+		validate = validator.New()
+		validate.RegisterTagNameFunc(validations.JSONTagName)
+		for _, custom := range validations.CustomValidations {
+			Expect(validate.RegisterValidation(custom.Tag, custom.Func)).To(Succeed())
+		}
+		translator, err := validations.NewTranslator(validate)
+		Expect(err).NotTo(HaveOccurred())
+		response.SetTranslator(translator)
+		DeferCleanup(func() {
+			response.SetTranslator(nil)
+		})
+	})
+
+	decodeViolations := func() []response.Violation {
+		var res struct {
+			Errors []response.Violation `json:"errors"`
+		}
+		Expect(json.NewDecoder(w.Body).Decode(&res)).To(Succeed())
+		return res.Errors
+	}
+
+	It("should report fields by their JSON path with rule and params", func() {
+		err := validate.Struct(MockUser{Password: "Str0ng!Pass", Role: "guest"})
+		response.ValidateErrorResponse(c, err)
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		violations := decodeViolations()
+		Expect(violations).To(ContainElement(response.Violation{
+			Field:   "first_name",
+			Rule:    "required",
+			Message: "first_name is a required field",
+		}))
+		Expect(violations).To(ContainElement(response.Violation{
+			Field:   "role",
+			Rule:    "oneof",
+			Params:  []string{"admin", "member"},
+			Message: "role must be one of [admin member]",
+		}))
+		Expect(violations).To(ContainElement(HaveField("Field", "address.city")))
+	})
+
+	It("should translate messages into the requested language", func() {
+		c.Request.Header.Set("Accept-Language", "fr;q=0.9, vi-VN")
+		err := validate.Struct(MockUser{Password: "Str0ng!Pass", Role: "admin", Address: MockAddress{
+			City: "Hanoi",
+		}})
+		response.ValidateErrorResponse(c, err)
+
+		violations := decodeViolations()
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Message).To(Equal("first_name không được bỏ trống"))
+	})
+
+	It("should use the messages of custom validators", func() {
+		err := validate.Struct(MockUser{
+			FirstName: "John",
+			Password:  "weak",
+			Role:      "admin",
+			Address:   MockAddress{City: "Hanoi"},
+		})
+		response.ValidateErrorResponse(c, err)
+
+		violations := decodeViolations()
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Rule).To(Equal("strongPassword"))
+		Expect(violations[0].Message).To(Equal(
+			"password does not meet the password policy: min_length, uppercase, number, special",
+		))
+	})
+})
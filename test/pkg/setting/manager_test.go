@@ -0,0 +1,84 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manager", func() {
+	var initial setting.Config
+
+	BeforeEach(func() {
+		initial = defaultConfig()
+		initial.RateLimit.Groups = map[string]setting.RateLimitRule{
+			"users": {Limit: 10, Window: time.Minute},
+		}
+	})
+
+	It("should publish a valid change to the subscribers", func() {
+		manager := setting.NewManager(initial)
+		var old, current *setting.Config
+		manager.Subscribe(func(o, c *setting.Config) {
+			old, current = o, c
+		})
+
+		next := initial
+		next.Logger.Level = "debug"
+		warnings, err := manager.Update(next)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(BeEmpty())
+		Expect(old.Logger.Level).To(Equal("info"))
+		Expect(current.Logger.Level).To(Equal("debug"))
+		Expect(manager.Current().Logger.Level).To(Equal("debug"))
+	})
+
+	It("should reject an invalid change", func() {
+		manager := setting.NewManager(initial)
+		called := false
+		manager.Subscribe(func(_, _ *setting.Config) {
+			called = true
+		})
+
+		next := initial
+		next.Logger.Level = "verbose"
+		next.RateLimit.Groups = map[string]setting.RateLimitRule{"users": {Limit: 0}}
+		_, err := manager.Update(next)
+
+		Expect(err).To(MatchError(ContainSubstring("logger_config.log_level")))
+		Expect(err).To(MatchError(ContainSubstring("rate_limit_config.groups.users.limit")))
+		Expect(called).To(BeFalse())
+		Expect(manager.Current().Logger.Level).To(Equal("info"))
+	})
+
+	It("should keep the settings that can't change at runtime", func() {
+		manager := setting.NewManager(initial)
+
+		next := initial
+		next.Server.Port = "9090"
+		next.Logger.Level = "warn"
+		warnings, err := manager.Update(next)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(ContainSubstring("server_config.port")))
+		Expect(manager.Current().Server.Port).To(Equal("8080"))
+		Expect(manager.Current().Logger.Level).To(Equal("warn"))
+	})
+
+	It("should keep the jobs settings, applied when the queue starts", func() {
+		manager := setting.NewManager(initial)
+
+		next := initial
+		next.Jobs.Concurrency = initial.Jobs.Concurrency + 1
+		next.Idempotency.TTL = time.Hour
+		warnings, err := manager.Update(next)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(ConsistOf(ContainSubstring("jobs_config")))
+		Expect(manager.Current().Jobs.Concurrency).To(Equal(initial.Jobs.Concurrency))
+		Expect(manager.Current().Idempotency.TTL).To(Equal(time.Hour))
+	})
+})
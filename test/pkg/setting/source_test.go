@@ -0,0 +1,118 @@
+package setting
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+)
+
+// defaultConfig returns the default configuration completed with the settings
+// having no default.
+func defaultConfig() setting.Config {
+	v := viper.New()
+	setting.SetDefaults(v)
+
+	var config setting.Config
+	Expect(v.Unmarshal(&config)).To(Succeed())
+	config.MongoDB.Database = "gin_test"
+	return config
+}
+
+var _ = Describe("Defaults", func() {
+	It("should apply the declared defaults", func() {
+		config := defaultConfig()
+
+		Expect(config.Server.Port).To(Equal("8080"))
+		Expect(config.Logger.Level).To(Equal("info"))
+		Expect(config.MongoDB.MaxPoolSize).To(BeEquivalentTo(100))
+		Expect(config.Idempotency.TTL).To(Equal(24 * time.Hour))
+		Expect(config.Jobs.Concurrency).To(Equal(4))
+		Expect(config.Jobs.VisibilityTimeout).To(Equal(5 * time.Minute))
+		Expect(config.Jobs.DrainTimeout).To(Equal(30 * time.Second))
+		Expect(config.Password.RequireUpper).To(BeTrue())
+		Expect(config.Validate()).To(Succeed())
+	})
+})
+
+var _ = Describe("Environment", func() {
+	It("should map nested keys onto environment variables", func() {
+		GinkgoT().Setenv("MONGO_CONFIG_PASSWORD", "secret")
+		GinkgoT().Setenv("SERVER_CONFIG_PORT", "9090")
+
+		v := viper.New()
+		setting.SetDefaults(v)
+		Expect(setting.BindEnv(v)).To(Succeed())
+
+		var config setting.Config
+		Expect(v.Unmarshal(&config)).To(Succeed())
+		Expect(config.MongoDB.Password).To(Equal("secret"))
+		Expect(config.Server.Port).To(Equal("9090"))
+	})
+
+	It("should read a value from the file named by a _FILE variable", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "mongo_password")
+		Expect(os.WriteFile(file, []byte("from-file\n"), 0o600)).To(Succeed())
+		GinkgoT().Setenv("MONGO_CONFIG_PASSWORD_FILE", file)
+
+		v := viper.New()
+		Expect(setting.BindEnv(v)).To(Succeed())
+
+		var config setting.Config
+		Expect(v.Unmarshal(&config)).To(Succeed())
+		Expect(config.MongoDB.Password).To(Equal("from-file"))
+	})
+
+	It("should reject a value set both directly and from a file", func() {
+		GinkgoT().Setenv("MONGO_CONFIG_PASSWORD", "secret")
+		GinkgoT().Setenv("MONGO_CONFIG_PASSWORD_FILE", "/run/secrets/mongo_password")
+
+		Expect(setting.BindEnv(viper.New())).To(MatchError(ContainSubstring("both set")))
+	})
+
+	It("should report a missing secret file", func() {
+		GinkgoT().Setenv("MONGO_CONFIG_PASSWORD_FILE", "/does/not/exist")
+
+		err := setting.BindEnv(viper.New())
+		Expect(err).To(MatchError(ContainSubstring("MONGO_CONFIG_PASSWORD_FILE")))
+	})
+})
+
+var _ = Describe("Validate", func() {
+	It("should report every invalid setting by its key", func() {
+		config := defaultConfig()
+		config.MongoDB.Database = ""
+		config.Server.Port = "http"
+		config.Password.MaxLength = 4
+
+		err := config.Validate()
+
+		Expect(err).To(MatchError(ContainSubstring("mongo_config.database: is required")))
+		Expect(err).To(MatchError(ContainSubstring("server_config.port: must be a valid port")))
+		Expect(err).To(MatchError(ContainSubstring(
+			"password_policy_config.max_length: must not be less than min_length",
+		)))
+	})
+
+	It("should accept an empty log file name, logging to the console only", func() {
+		config := defaultConfig()
+		config.Logger.FileName = ""
+		Expect(config.Validate()).To(Succeed())
+	})
+
+	It("should accept a Mongo URI instead of a host", func() {
+		config := defaultConfig()
+		config.MongoDB.Host = ""
+		config.MongoDB.URI = "mongodb+srv://cluster0.example.net"
+		Expect(config.Validate()).To(Succeed())
+
+		config.MongoDB.URI = ""
+		Expect(config.Validate()).To(MatchError(ContainSubstring(
+			"mongo_config.host: is required without uri",
+		)))
+	})
+})
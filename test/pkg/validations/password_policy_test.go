@@ -0,0 +1,105 @@
+package validations
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/validations"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type TestUser struct {
+	Email     string
+	FirstName string
+	Password  string `validate:"strong_password"`
+}
+
+var _ = Describe("PasswordPolicy", func() {
+	It("should report every violated rule", func() {
+		policy, err := validations.NewPasswordPolicy(validations.DefaultPasswordPolicySettings)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(policy.Check("Str0ng!Pass")).To(BeEmpty())
+		Expect(policy.Check("abc")).To(ConsistOf(
+			validations.RuleMinLength,
+			validations.RuleUppercase,
+			validations.RuleNumber,
+			validations.RuleSpecial,
+		))
+		Expect(policy.Check(strings.Repeat("Aa1!", 40))).To(ConsistOf(validations.RuleMaxLength))
+	})
+
+	It("should apply the configured length and character classes", func() {
+		policy, err := validations.NewPasswordPolicy(setting.PasswordPolicySettings{
+			MinLength:     12,
+			RequireNumber: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(policy.Check("correct horse 1")).To(BeEmpty())
+		Expect(policy.Check("short1")).To(ConsistOf(validations.RuleMinLength))
+	})
+
+	It("should reject banned substrings and personal terms", func() {
+		policy, err := validations.NewPasswordPolicy(setting.PasswordPolicySettings{
+			BannedSubstrings: []string{"password"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(policy.Check("MyPassWord1!")).To(ConsistOf(validations.RuleBannedSubstring))
+		Expect(policy.Check("johnny2024!", "johnny@example.com")).
+			To(ConsistOf(validations.RuleBannedSubstring))
+		Expect(policy.Check("Jo2024!", "Jo")).To(BeEmpty())
+	})
+
+	It("should reject breached passwords", func() {
+		sum := sha1.Sum([]byte("Str0ng!Pass"))
+		list := filepath.Join(GinkgoT().TempDir(), "breached.txt")
+		content := "# pwned passwords\n" + strings.ToUpper(hex.EncodeToString(sum[:])) + ":42\n"
+		Expect(os.WriteFile(list, []byte(content), 0o600)).To(Succeed())
+
+		policy, err := validations.NewPasswordPolicy(setting.PasswordPolicySettings{
+			BreachedListFile: list,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(policy.Check("Str0ng!Pass")).To(ConsistOf(validations.RuleBreached))
+		Expect(policy.Check("Other!Pass1")).To(BeEmpty())
+	})
+
+	It("should fail on an invalid breached password list", func() {
+		list := filepath.Join(GinkgoT().TempDir(), "breached.txt")
+		Expect(os.WriteFile(list, []byte("not-a-hash\n"), 0o600)).To(Succeed())
+
+		_, err := validations.NewPasswordPolicy(setting.PasswordPolicySettings{
+			BreachedListFile: list,
+		})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should ban personal fields through the validation tag", func() {
+		validate := validator.New()
+		Expect(validate.RegisterValidation("strong_password", validations.StrongPassword)).
+			To(Succeed())
+
+		err := validate.Struct(TestUser{
+			Email:     "johnny@example.com",
+			FirstName: "John",
+			Password:  "Johnny2024!",
+		})
+		Expect(err).To(HaveOccurred())
+
+		err = validate.Struct(TestUser{
+			Email:     "johnny@example.com",
+			FirstName: "John",
+			Password:  "Str0ng!Pass",
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
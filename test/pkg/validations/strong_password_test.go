@@ -2,7 +2,7 @@ package validations
 
 import (
 	"github.com/go-playground/validator/v10"
-	validations2 "github.com/hainguyen27798/gin-boilerplate/pkg/validations"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/validations"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"testing"
@@ -22,7 +22,7 @@ var _ = Describe("StrongPassword", func() {
 
 	BeforeEach(func() {
 		validate = validator.New()
-		err := validate.RegisterValidation("strong_password", validations2.StrongPassword)
+		err := validate.RegisterValidation("strong_password", validations.StrongPassword)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
@@ -0,0 +1,138 @@
+// Package testutil builds the application over in-memory repositories, so that
+// tests exercise the real routes, middlewares and modules with no external
+// services.
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/health"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/idempotency"
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
+	"github.com/hainguyen27798/gin-boilerplate/internal/routes"
+	"github.com/hainguyen27798/gin-boilerplate/internal/wires"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/jobs"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/logger"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/setting"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/validations"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// App is the application wired with in-memory repositories. The repositories
+// are exposed to seed data and inspect the outcome of requests.
+type App struct {
+	Engine    *gin.Engine
+	Registry  *module.Registry
+	Config    *setting.Config
+	Validator *validator.Validate
+	Logger    *logger.Zap
+
+	Users       users.UserRepository
+	UserImports users.ImportJobRepository
+	Idempotency idempotency.IdempotencyRepository
+	Jobs        jobs.Store
+
+	// OpenAPIDrift lists the routes drifting from their OpenAPI documentation.
+	OpenAPIDrift error
+}
+
+// Option customizes the application before its modules are initialized.
+type Option func(app *App)
+
+// WithConfig changes the configuration, which starts from the defaults.
+func WithConfig(fn func(config *setting.Config)) Option {
+	return func(app *App) {
+		fn(app.Config)
+	}
+}
+
+// WithUserRepository replaces the in-memory user repository.
+func WithUserRepository(repo users.UserRepository) Option {
+	return func(app *App) {
+		app.Users = repo
+	}
+}
+
+// WithJobStore replaces the in-memory store of the background jobs.
+func WithJobStore(store jobs.Store) Option {
+	return func(app *App) {
+		app.Jobs = store
+	}
+}
+
+// NewApp initializes and starts the modules of the application over in-memory
+// repositories, and registers their routes on a new engine. The modules are
+// stopped when the test ends.
+func NewApp(t testing.TB, opts ...Option) *App {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	validate, err := validations.NewValidator()
+	require.NoError(t, err)
+
+	app := &App{
+		Config:      DefaultConfig(t),
+		Validator:   validate,
+		Users:       users.NewMemoryUserRepository(),
+		UserImports: users.NewMemoryImportJobRepository(),
+		Idempotency: idempotency.NewMemoryIdempotencyRepository(),
+		Jobs:        jobs.NewMemoryStore(),
+	}
+	for _, opt := range opts {
+		opt(app)
+	}
+	app.Logger = logger.NewLogger(app.Config.Logger, setting.TestMode, "test", "")
+
+	app.Registry = module.NewRegistry()
+	require.NoError(t, app.Registry.Register(
+		wires.NewJobsModule(app.Jobs),
+		wires.NewIdempotencyModule(app.Idempotency),
+		wires.NewUserModule(app.Users, app.UserImports),
+		wires.NewAdminModule(),
+	))
+	require.NoError(t, app.Registry.Init(module.Deps{
+		Logger:    app.Logger,
+		Validator: app.Validator,
+		Config:    func() *setting.Config { return app.Config },
+	}))
+	require.NoError(t, app.Registry.Start(context.Background()))
+	t.Cleanup(func() {
+		require.NoError(t, app.Registry.Stop(context.Background()))
+	})
+
+	app.Engine = gin.New()
+	app.Engine.Use(gin.Recovery())
+	checks := map[string]health.Check{}
+	for name, check := range app.Registry.HealthChecks() {
+		checks[name] = check
+	}
+	routes.RegisterHealthRoutes(app.Engine, health.NewHealthController(checks))
+	app.Registry.RegisterRoutes(app.Engine)
+	app.OpenAPIDrift = routes.RegisterOpenAPIRoutes(
+		app.Engine, app.Registry.OpenAPIRoutes(), true,
+	)
+
+	return app
+}
+
+// DefaultConfig returns the configuration made of the declared defaults, only
+// logging errors to the console, accepting AdminToken on the admin endpoints and
+// StaffToken on the staff ones.
+func DefaultConfig(t testing.TB) *setting.Config {
+	t.Helper()
+	v := viper.New()
+	setting.SetDefaults(v)
+
+	var config setting.Config
+	require.NoError(t, v.Unmarshal(&config))
+	config.Logger.Level = "error"
+	config.Logger.FileName = ""
+	config.Admin.Token = AdminToken
+	config.Staff.Token = StaffToken
+	return &config
+}
@@ -0,0 +1,7 @@
+package testutil
+
+// AdminToken is the admin token of the default configuration.
+const AdminToken = "test-admin-token-0123456789"
+
+// StaffToken is the staff token of the default configuration.
+const StaffToken = "test-staff-token-0123456789"
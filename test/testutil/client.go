@@ -0,0 +1,185 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/hainguyen27798/gin-boilerplate/pkg/response"
+	"github.com/stretchr/testify/require"
+)
+
+// Client sends requests to the engine of an App, failing the test on any error,
+// e.g. Post("/v1/users").JSON(dto).ExpectStatus(201).DecodeData(&user).
+type Client struct {
+	t   testing.TB
+	app *App
+}
+
+// Client returns a client sending requests to the engine of the app.
+func (app *App) Client(t testing.TB) *Client {
+	return &Client{t: t, app: app}
+}
+
+// Get starts a GET request to the path.
+func (c *Client) Get(path string) *Request {
+	return c.newRequest(http.MethodGet, path)
+}
+
+// Post starts a POST request to the path.
+func (c *Client) Post(path string) *Request {
+	return c.newRequest(http.MethodPost, path)
+}
+
+// Put starts a PUT request to the path.
+func (c *Client) Put(path string) *Request {
+	return c.newRequest(http.MethodPut, path)
+}
+
+// Patch starts a PATCH request to the path.
+func (c *Client) Patch(path string) *Request {
+	return c.newRequest(http.MethodPatch, path)
+}
+
+// Delete starts a DELETE request to the path.
+func (c *Client) Delete(path string) *Request {
+	return c.newRequest(http.MethodDelete, path)
+}
+
+func (c *Client) newRequest(method, path string) *Request {
+	return &Request{
+		client: c,
+		method: method,
+		path:   path,
+		header: http.Header{},
+		query:  url.Values{},
+	}
+}
+
+// Request is a request being built, it is sent by Do or ExpectStatus.
+type Request struct {
+	client *Client
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   io.Reader
+}
+
+// JSON sets the body of the request to the JSON encoding of body.
+func (r *Request) JSON(body any) *Request {
+	r.client.t.Helper()
+	data, err := json.Marshal(body)
+	require.NoError(r.client.t, err)
+	r.body = bytes.NewReader(data)
+	return r.Header("Content-Type", "application/json")
+}
+
+// Body sets the raw body of the request along with its content type.
+func (r *Request) Body(contentType string, body []byte) *Request {
+	r.body = bytes.NewReader(body)
+	return r.Header("Content-Type", contentType)
+}
+
+// Header sets a header of the request.
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Query adds a query parameter to the request.
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Bearer authenticates the request with the token.
+func (r *Request) Bearer(token string) *Request {
+	return r.Header("Authorization", "Bearer "+token)
+}
+
+// AsAdmin authenticates the request with the admin token of the configuration.
+func (r *Request) AsAdmin() *Request {
+	return r.Bearer(r.client.app.Config.Admin.Token)
+}
+
+// AsStaff authenticates the request with the staff token of the configuration.
+func (r *Request) AsStaff() *Request {
+	return r.Bearer(r.client.app.Config.Staff.Token)
+}
+
+// Do sends the request and returns the response.
+func (r *Request) Do() *Response {
+	target := r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, target, r.body)
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+
+	w := httptest.NewRecorder()
+	r.client.app.Engine.ServeHTTP(w, req)
+	return &Response{ResponseRecorder: w, t: r.client.t}
+}
+
+// ExpectStatus sends the request and checks the status of the response.
+func (r *Request) ExpectStatus(status int) *Response {
+	r.client.t.Helper()
+	return r.Do().ExpectStatus(status)
+}
+
+// Response is the recorded response of a request.
+type Response struct {
+	*httptest.ResponseRecorder
+	t testing.TB
+}
+
+// ExpectStatus checks the status of the response, reporting the body otherwise.
+func (r *Response) ExpectStatus(status int) *Response {
+	r.t.Helper()
+	require.Equal(r.t, status, r.Code, "unexpected status, body: %s", r.Body.String())
+	return r
+}
+
+// ExpectHeader checks a header of the response.
+func (r *Response) ExpectHeader(key, value string) *Response {
+	r.t.Helper()
+	require.Equal(r.t, value, r.Header().Get(key), "unexpected %s header", key)
+	return r
+}
+
+// ExpectErrorCode checks the machine-readable code of an error response.
+func (r *Response) ExpectErrorCode(code string) *Response {
+	r.t.Helper()
+	var res response.TErrResponse[json.RawMessage]
+	r.Decode(&res)
+	require.Equal(r.t, code, res.ErrorCode)
+	return r
+}
+
+// Decode decodes the JSON body of the response into v.
+func (r *Response) Decode(v any) *Response {
+	r.t.Helper()
+	require.NoError(r.t, json.Unmarshal(r.Body.Bytes(), v), "body: %s", r.Body.String())
+	return r
+}
+
+// DecodeData decodes the data of a response envelope into v.
+func (r *Response) DecodeData(v any) *Response {
+	r.t.Helper()
+	return r.Decode(&response.TDataResponse[any]{Data: v})
+}
+
+// DecodeViolations decodes the field violations of a validation error response.
+func (r *Response) DecodeViolations() []response.Violation {
+	r.t.Helper()
+	var res response.TErrResponse[[]response.Violation]
+	r.Decode(&res)
+	return res.Errors
+}
@@ -0,0 +1,59 @@
+package testutil
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hainguyen27798/gin-boilerplate/internal/module/users"
+	"github.com/hainguyen27798/gin-boilerplate/pkg/helpers"
+	"github.com/stretchr/testify/require"
+)
+
+// UserPassword is the password of the fixture users.
+const UserPassword = "StrongPass123!"
+
+// userSeq makes the emails of the fixture users unique.
+var userSeq atomic.Int64
+
+// hashedUserPassword hashes UserPassword once, hashing is slow on purpose.
+var hashedUserPassword = sync.OnceValues(func() (string, error) {
+	hashed, err := helpers.HashPassword(UserPassword)
+	if err != nil {
+		return "", err
+	}
+	return hashed, nil
+})
+
+// NewUser returns a verified user with a unique email and UserPassword as its
+// password, changed by the overrides. The user is not saved.
+func NewUser(t testing.TB, overrides ...func(user *users.UserModel)) *users.UserModel {
+	t.Helper()
+	password, err := hashedUserPassword()
+	require.NoError(t, err)
+
+	user := &users.UserModel{
+		Email:     fmt.Sprintf("user%d@example.com", userSeq.Add(1)),
+		FirstName: "John",
+		LastName:  "Doe",
+		Password:  password,
+		Image:     "https://example.com/avatar.png",
+		Verified:  true,
+	}
+	for _, override := range overrides {
+		override(user)
+	}
+	return user
+}
+
+// CreateUser saves a user built by NewUser in the user repository of the app.
+func (app *App) CreateUser(
+	t testing.TB,
+	overrides ...func(user *users.UserModel),
+) *users.UserModel {
+	t.Helper()
+	user, err := app.Users.Create(t.Context(), NewUser(t, overrides...))
+	require.Nil(t, err)
+	return user
+}